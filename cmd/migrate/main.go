@@ -0,0 +1,176 @@
+// Command migrate applies, reverts and creates PostgreSQL migrations stored as
+// <version>_<name>.up.sql and <version>_<name>.down.sql files.
+//
+// Usage:
+//
+//	migrate [flags] up [version]
+//	migrate [flags] down [steps]
+//	migrate [flags] status
+//	migrate [flags] create <name>
+//
+// Connection flags default to the PGHOST, PGPORT, PGUSER, PGPASSWORD, PGDATABASE and
+// PGSSLMODE environment variables.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_postgres "go-libs/pkg/postgres"
+	_migrate_postgres "go-libs/pkg/postgres/migrate"
+	_pgx_postgres "go-libs/pkg/postgres/pgx"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	dbConfig := _postgres.DefaultConfig()
+	migrateConfig := _migrate_postgres.DefaultConfig()
+
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: migrate [flags] up [version] | down [steps] | status | create <name>\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	dir := flags.String("dir", "migrations", "migrations directory")
+	flags.StringVar(&dbConfig.Host, "host", env("PGHOST", dbConfig.Host), "database host, several hosts may be separated by commas")
+	flags.IntVar(&dbConfig.Port, "port", envInt("PGPORT", dbConfig.Port), "database port")
+	flags.StringVar(&dbConfig.User, "user", env("PGUSER", dbConfig.User), "database user")
+	flags.StringVar(&dbConfig.Password, "password", env("PGPASSWORD", dbConfig.Password), "database password")
+	flags.StringVar(&dbConfig.Database, "database", env("PGDATABASE", dbConfig.Database), "database name")
+	flags.StringVar(&dbConfig.SSLMode, "sslmode", env("PGSSLMODE", dbConfig.SSLMode), "SSL mode")
+	flags.DurationVar(&dbConfig.QueryTimeout, "query-timeout", 10*time.Minute, "timeout of migrations run outside a transaction")
+	flags.StringVar(&migrateConfig.Table, "table", migrateConfig.Table, "migrations table")
+	flags.DurationVar(&migrateConfig.LockTimeout, "lock-timeout", migrateConfig.LockTimeout, "how long to wait for another runner")
+	flags.BoolVar(&migrateConfig.DryRun, "dry-run", false, "print the migrations that would run without running them")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("missing command")
+	}
+	command, commandArgs := flags.Arg(0), flags.Args()[1:]
+
+	if command == "create" {
+		if len(commandArgs) != 1 {
+			return fmt.Errorf("usage: migrate create <name>")
+		}
+		upPath, downPath, err := _migrate_postgres.Create(*dir, commandArgs[0], time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\nCreated %s\n", upPath, downPath)
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	conn := _pgx_postgres.NewConnection(dbConfig)
+	if err := conn.Connect(ctx); err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	migrator, err := _migrate_postgres.NewMigrator(conn, os.DirFS(*dir), ".", migrateConfig)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		var version int64
+		if len(commandArgs) > 0 {
+			if version, err = strconv.ParseInt(commandArgs[0], 10, 64); err != nil {
+				return fmt.Errorf("invalid version: %s", commandArgs[0])
+			}
+		}
+		applied, err := migrator.UpTo(ctx, version)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(commandArgs) > 0 {
+			if steps, err = strconv.Atoi(commandArgs[0]); err != nil {
+				return fmt.Errorf("invalid steps: %s", commandArgs[0])
+			}
+		}
+		_, err := migrator.Down(ctx, steps)
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(statuses)
+
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+// printStatus prints a table of migrations
+func printStatus(statuses []_migrate_postgres.MigrationStatus) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+
+	for _, s := range statuses {
+		var state []string
+		if s.Applied {
+			state = append(state, "applied")
+		} else {
+			state = append(state, "pending")
+		}
+		if s.Drift {
+			state = append(state, "checksum mismatch")
+		}
+		if s.Missing {
+			state = append(state, "file missing")
+		}
+
+		appliedAt := "-"
+		if s.Applied {
+			appliedAt = s.AppliedAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, strings.Join(state, ", "), appliedAt)
+	}
+
+	return w.Flush()
+}
+
+// env returns the environment variable key, or fallback when it is not set
+func env(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// envInt returns the integer environment variable key, or fallback when it is not set or invalid
+func envInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
@@ -89,6 +89,12 @@ func (l *Logger) With(args ...any) *Logger {
 	}
 }
 
+// IsHidden reports whether values of the given field are masked by HideFields
+func (l *Logger) IsHidden(field string) bool {
+	_, exists := l.hideFields[field]
+	return exists
+}
+
 // Debug logs a debug message
 func (l *Logger) Debug(ctx context.Context, msg string, args ...any) {
 	l.Logger.DebugContext(ctx, msg, args...)
@@ -2,11 +2,17 @@ package _postgres
 
 import (
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds PostgreSQL database configuration
 type Config struct {
+	// Host may list several hosts libpq-style ("db1,db2:5433"), tried in order until one
+	// matches TargetSessionAttrs. Hosts without a port use Port. An IPv6 address in a list
+	// or with a port is written in brackets ("[::1]:5433").
 	Host     string `json:"host" yaml:"host"`
 	Port     int    `json:"port" yaml:"port"`
 	User     string `json:"user" yaml:"user"`
@@ -14,6 +20,10 @@ type Config struct {
 	Database string `json:"database" yaml:"database"`
 	SSLMode  string `json:"ssl_mode" yaml:"ssl_mode"`
 
+	// TargetSessionAttrs selects the host of a multi-host Host: any, read-write, read-only,
+	// primary, standby or prefer-standby (empty means any)
+	TargetSessionAttrs string `json:"target_session_attrs" yaml:"target_session_attrs"`
+
 	// Connection pool settings
 	MaxOpenConns    int           `json:"max_open_conns" yaml:"max_open_conns"`
 	MaxIdleConns    int           `json:"max_idle_conns" yaml:"max_idle_conns"`
@@ -54,10 +64,85 @@ func DefaultConfig() *Config {
 
 // DSN returns the PostgreSQL data source name
 func (c *Config) DSN() string {
-	return fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		c.Host, c.Port, c.User, c.Password, c.Database, c.SSLMode,
+	hosts, ports := c.hostsAndPorts()
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		dsnValue(hosts), dsnValue(ports), dsnValue(c.User), dsnValue(c.Password),
+		dsnValue(c.Database), dsnValue(c.SSLMode),
 	)
+	if c.TargetSessionAttrs != "" {
+		dsn += " target_session_attrs=" + dsnValue(c.TargetSessionAttrs)
+	}
+	return dsn
+}
+
+// dsnValue quotes a DSN value when it is empty or contains spaces, quotes or backslashes
+func dsnValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, " '\\\t\n") {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
+
+// Address returns host:port, or Host as is when it lists several hosts
+func (c *Config) Address() string {
+	if strings.Contains(c.Host, ",") {
+		return c.Host
+	}
+	host, port := c.hostsAndPorts()
+	return net.JoinHostPort(host, port)
+}
+
+// hostsAndPorts splits a multi-host Host into the libpq host and port lists. A single
+// host is used as is unless it is a bracketed IPv6 address with a port.
+func (c *Config) hostsAndPorts() (string, string) {
+	defaultPort := strconv.Itoa(c.Port)
+	if !strings.Contains(c.Host, ",") {
+		if strings.HasPrefix(c.Host, "[") {
+			return splitHostPort(c.Host, defaultPort)
+		}
+		return c.Host, defaultPort
+	}
+
+	entries := strings.Split(c.Host, ",")
+	hosts := make([]string, len(entries))
+	ports := make([]string, len(entries))
+	for i, entry := range entries {
+		hosts[i], ports[i] = splitHostPort(strings.TrimSpace(entry), defaultPort)
+	}
+	return strings.Join(hosts, ","), strings.Join(ports, ",")
+}
+
+// splitHostPort splits host:port or [ipv6]:port. An entry with several colons and no
+// brackets is an IPv6 address without a port.
+func splitHostPort(entry, defaultPort string) (string, string) {
+	host, port := entry, ""
+	if strings.HasPrefix(entry, "[") {
+		if end := strings.Index(entry, "]"); end > 0 {
+			host = entry[1:end]
+			port = strings.TrimPrefix(entry[end+1:], ":")
+		}
+	} else if strings.Count(entry, ":") == 1 {
+		host, port, _ = strings.Cut(entry, ":")
+	}
+
+	if _, err := strconv.Atoi(port); err != nil {
+		port = defaultPort
+	}
+	return host, port
+}
+
+// targetSessionAttrs are the values accepted for TargetSessionAttrs
+var targetSessionAttrs = map[string]bool{
+	"":               true,
+	"any":            true,
+	"read-write":     true,
+	"read-only":      true,
+	"primary":        true,
+	"standby":        true,
+	"prefer-standby": true,
 }
 
 // Validate checks if the configuration is valid
@@ -83,6 +168,9 @@ func (c *Config) Validate() error {
 	if c.MaxIdleConns > c.MaxOpenConns {
 		return fmt.Errorf("max_idle_conns cannot be greater than max_open_conns")
 	}
+	if !targetSessionAttrs[c.TargetSessionAttrs] {
+		return fmt.Errorf("invalid target_session_attrs: %s", c.TargetSessionAttrs)
+	}
 	return nil
 }
 
@@ -91,10 +179,27 @@ type MasterSlaveConfig struct {
 	Master *Config `json:"master" yaml:"master"`
 	Slave  *Config `json:"slave" yaml:"slave"`
 
+	// Replicas lists the read replicas. When it is empty Slave is the only replica.
+	Replicas []*ReplicaConfig `json:"replicas" yaml:"replicas"`
+
 	// Master-slave specific settings
 	UseSlaveConnection bool `json:"use_slave_connection" yaml:"use_slave_connection"`
 	SlaveReadOnly      bool `json:"slave_read_only" yaml:"slave_read_only"`
 
+	// Read routing: read-only Query and QueryRow calls go to the slave, everything else
+	// to the master. ReadYourWritesWindow keeps reads of a session on the master after it
+	// writes, see WithReadYourWrites.
+	RouteReadsToSlave    bool          `json:"route_reads_to_slave" yaml:"route_reads_to_slave"`
+	ReadYourWritesWindow time.Duration `json:"read_your_writes_window" yaml:"read_your_writes_window"`
+
+	// Replica selection: ReplicaBalancing picks among healthy replicas, and replicas
+	// lagging more than MaxReplicationLag (0 disables the check) are skipped until they
+	// catch up. Health and lag are checked every ReplicaCheckInterval (0 uses
+	// HealthCheckInterval) when health checks are enabled.
+	ReplicaBalancing     string        `json:"replica_balancing" yaml:"replica_balancing"`
+	MaxReplicationLag    time.Duration `json:"max_replication_lag" yaml:"max_replication_lag"`
+	ReplicaCheckInterval time.Duration `json:"replica_check_interval" yaml:"replica_check_interval"`
+
 	// Failover settings
 	AutoFailover        bool          `json:"auto_failover" yaml:"auto_failover"`
 	FailoverRetries     int           `json:"failover_retries" yaml:"failover_retries"`
@@ -106,15 +211,20 @@ type MasterSlaveConfig struct {
 // DefaultMasterSlaveConfig returns a master-slave configuration with sensible defaults
 func DefaultMasterSlaveConfig() *MasterSlaveConfig {
 	return &MasterSlaveConfig{
-		Master:              DefaultConfig(),
-		Slave:               DefaultConfig(),
-		UseSlaveConnection:  true,
-		SlaveReadOnly:       true,
-		AutoFailover:        true,
-		FailoverRetries:     3,
-		FailoverInterval:    5 * time.Second,
-		HealthCheckEnabled:  true,
-		HealthCheckInterval: 30 * time.Second,
+		Master:               DefaultConfig(),
+		Slave:                DefaultConfig(),
+		UseSlaveConnection:   true,
+		SlaveReadOnly:        true,
+		RouteReadsToSlave:    true,
+		ReadYourWritesWindow: 5 * time.Second,
+		ReplicaBalancing:     ReplicaBalancingRoundRobin,
+		MaxReplicationLag:    10 * time.Second,
+		ReplicaCheckInterval: 5 * time.Second,
+		AutoFailover:         true,
+		FailoverRetries:      3,
+		FailoverInterval:     5 * time.Second,
+		HealthCheckEnabled:   true,
+		HealthCheckInterval:  30 * time.Second,
 	}
 }
 
@@ -128,19 +238,86 @@ func (c *MasterSlaveConfig) Validate() error {
 		return fmt.Errorf("invalid master configuration: %w", err)
 	}
 
+	if c.ReadYourWritesWindow < 0 {
+		return fmt.Errorf("read your writes window cannot be negative")
+	}
+
 	if c.UseSlaveConnection {
-		if c.Slave == nil {
-			return fmt.Errorf("slave configuration is required when use_slave_connection is true")
+		if len(c.Replicas) == 0 {
+			if c.Slave == nil {
+				return fmt.Errorf("slave configuration is required when use_slave_connection is true")
+			}
+
+			if err := c.Slave.Validate(); err != nil {
+				return fmt.Errorf("invalid slave configuration: %w", err)
+			}
+		}
+
+		for i, replica := range c.Replicas {
+			if replica == nil || replica.Config == nil {
+				return fmt.Errorf("replica %d configuration is required", i)
+			}
+			if err := replica.Validate(); err != nil {
+				return fmt.Errorf("invalid replica %d configuration: %w", i, err)
+			}
+		}
+
+		switch c.ReplicaBalancing {
+		case "", ReplicaBalancingRoundRobin, ReplicaBalancingLeastConnections:
+		default:
+			return fmt.Errorf("invalid replica balancing: %s", c.ReplicaBalancing)
 		}
 
-		if err := c.Slave.Validate(); err != nil {
-			return fmt.Errorf("invalid slave configuration: %w", err)
+		if c.MaxReplicationLag < 0 {
+			return fmt.Errorf("max replication lag cannot be negative")
+		}
+
+		if c.ReplicaCheckInterval < 0 {
+			return fmt.Errorf("replica check interval cannot be negative")
 		}
 	}
 
 	return nil
 }
 
+// ReplicaConfigs returns the configured replicas, or Slave as the only replica when
+// Replicas is empty. It returns nothing when use_slave_connection is false.
+func (c *MasterSlaveConfig) ReplicaConfigs() []*ReplicaConfig {
+	if !c.UseSlaveConnection {
+		return nil
+	}
+	if len(c.Replicas) > 0 {
+		return c.Replicas
+	}
+	return []*ReplicaConfig{{Config: c.Slave, Name: "slave", Weight: 1}}
+}
+
+// ReplicaConfig holds the configuration of a read replica
+type ReplicaConfig struct {
+	*Config
+
+	// Name identifies the replica in status and logs (defaults to host:port)
+	Name string `json:"name" yaml:"name"`
+	// Weight is the share of reads for round-robin balancing (0 means 1)
+	Weight int `json:"weight" yaml:"weight"`
+}
+
+// Validate checks if the replica configuration is valid
+func (c *ReplicaConfig) Validate() error {
+	if c.Weight < 0 {
+		return fmt.Errorf("weight cannot be negative")
+	}
+	return c.Config.Validate()
+}
+
+// DisplayName returns the name of the replica, or its address when it has none
+func (c *ReplicaConfig) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Address()
+}
+
 // GormConfig holds GORM-specific configuration
 type GormConfig struct {
 	*Config
@@ -215,8 +392,13 @@ func (c *GormMasterSlaveConfig) GetMasterGormConfig() *GormConfig {
 
 // GetSlaveGormConfig returns GORM configuration for the slave
 func (c *GormMasterSlaveConfig) GetSlaveGormConfig() *GormConfig {
+	return c.GetReplicaGormConfig(c.Slave)
+}
+
+// GetReplicaGormConfig returns GORM configuration for a replica, using the slave settings
+func (c *GormMasterSlaveConfig) GetReplicaGormConfig(cfg *Config) *GormConfig {
 	return &GormConfig{
-		Config:                                   c.Slave,
+		Config:                                   cfg,
 		LogLevel:                                 c.SlaveLogLevel,
 		SlowThreshold:                            c.SlaveSlowThreshold,
 		SkipDefaultTransaction:                   c.SlaveSkipDefaultTransaction,
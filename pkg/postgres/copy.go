@@ -0,0 +1,120 @@
+package _postgres
+
+import (
+	"fmt"
+	"iter"
+	"time"
+)
+
+// ConflictAction selects how CopyModels handles rows that violate a unique constraint
+type ConflictAction string
+
+const (
+	// ConflictError copies straight into the table; a conflicting row fails its batch
+	ConflictError ConflictAction = "error"
+	// ConflictDoNothing skips conflicting rows
+	ConflictDoNothing ConflictAction = "do_nothing"
+	// ConflictUpdate overwrites conflicting rows with the copied values
+	ConflictUpdate ConflictAction = "update"
+)
+
+// CopyOptions controls a bulk copy
+type CopyOptions struct {
+	// BatchSize is the number of rows sent per COPY. Each batch commits on its own.
+	BatchSize int
+	// BatchTimeout bounds each batch (0 uses the connection's query timeout)
+	BatchTimeout time.Duration
+	// OnConflict selects the conflict handling. Anything but ConflictError copies into a
+	// temporary table first and moves the rows with INSERT ... ON CONFLICT.
+	OnConflict ConflictAction
+	// ConflictColumns are the unique columns checked for conflicts (default: primary keys)
+	ConflictColumns []string
+	// UpdateColumns are overwritten with ConflictUpdate (default: every copied column
+	// that is not a conflict column). Omitempty columns left out of a row keep their
+	// current value.
+	UpdateColumns []string
+	// ContinueOnError keeps copying after a batch fails; failures are reported in the
+	// result instead of stopping the copy
+	ContinueOnError bool
+	// OnProgress is called after every batch
+	OnProgress func(progress CopyProgress)
+}
+
+// DefaultCopyOptions returns copy options with sensible defaults
+func DefaultCopyOptions() CopyOptions {
+	return CopyOptions{
+		BatchSize:  10000,
+		OnConflict: ConflictError,
+	}
+}
+
+// Validate checks if the copy options are valid
+func (o *CopyOptions) Validate() error {
+	if o.BatchSize <= 0 {
+		return fmt.Errorf("batch size must be greater than 0")
+	}
+	switch o.OnConflict {
+	case ConflictError, ConflictDoNothing, ConflictUpdate:
+	default:
+		return fmt.Errorf("invalid conflict action: %s", o.OnConflict)
+	}
+	return nil
+}
+
+// CopyProgress reports the state of a running copy
+type CopyProgress struct {
+	// Batches is the number of batches processed so far
+	Batches int
+	// Read is the number of rows taken from the source
+	Read int64
+	// Written is the number of rows inserted or updated
+	Written int64
+	// Failed is the number of rows in failed batches
+	Failed int64
+}
+
+// CopyResult is the outcome of a copy
+type CopyResult struct {
+	CopyProgress
+	// Errors lists the failed batches in order
+	Errors []*BatchError
+}
+
+// BatchError describes a failed batch. Rows FirstRow to FirstRow+Rows-1 of the source
+// (counting from 0) were not written.
+type BatchError struct {
+	Batch    int
+	FirstRow int64
+	Rows     int
+	Err      error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch %d (rows %d-%d) failed: %v", e.Batch, e.FirstRow, e.FirstRow+int64(e.Rows)-1, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// SliceSource streams the models of a slice
+func SliceSource[T any](models []T) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		for _, model := range models {
+			if !yield(model) {
+				return
+			}
+		}
+	}
+}
+
+// ChannelSource streams models received from a channel until it is closed
+func ChannelSource[T any](ch <-chan T) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		for model := range ch {
+			if !yield(model) {
+				return
+			}
+		}
+	}
+}
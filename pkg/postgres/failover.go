@@ -0,0 +1,48 @@
+package _postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecoveryQuery reports whether a server is a standby. A primary returns false.
+const RecoveryQuery = "SELECT pg_is_in_recovery()"
+
+// MasterNodeName names the configured master in RoleChange events
+const MasterNodeName = "master"
+
+// RoleChange is emitted by master-slave connections when the node serving writes changes
+type RoleChange struct {
+	// Previous is the name of the node that served writes before
+	Previous string
+	// Current is the name of the node serving writes now, empty when no primary is
+	// reachable and the connection only serves reads
+	Current string
+	Time    time.Time
+}
+
+// IsPrimary reports whether conn is connected to a primary that accepts writes
+func IsPrimary(ctx context.Context, conn DatabaseClient) (bool, error) {
+	var inRecovery bool
+	if err := conn.QueryRow(ctx, RecoveryQuery).Scan(&inRecovery); err != nil {
+		return false, fmt.Errorf("failed to query recovery status: %w", err)
+	}
+	return !inRecovery, nil
+}
+
+// DetectPrimary returns the first candidate connected to a primary. Candidates that cannot
+// be reached are skipped.
+func DetectPrimary[C ReplicaClient](ctx context.Context, candidates []ReplicaConn[C]) (ReplicaConn[C], bool) {
+	for _, candidate := range candidates {
+		isPrimary, err := IsPrimary(ctx, candidate.Conn)
+		if err != nil {
+			fmt.Printf("Failed to check whether %s is the primary: %v\n", candidate.Name, err)
+			continue
+		}
+		if isPrimary {
+			return candidate, true
+		}
+	}
+	return ReplicaConn[C]{}, false
+}
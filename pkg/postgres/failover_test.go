@@ -0,0 +1,163 @@
+package _postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// recoveryRow returns the result of pg_is_in_recovery()
+type recoveryRow struct {
+	inRecovery bool
+	err        error
+}
+
+func (r recoveryRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	reflect.ValueOf(dest[0]).Elem().SetBool(r.inRecovery)
+	return nil
+}
+
+func TestDetectPrimary(t *testing.T) {
+	master := &fakeReplica{name: "master", pingErr: errors.New("connection refused")}
+	standby := &fakeReplica{name: "standby", standby: true}
+	promoted := &fakeReplica{name: "promoted"}
+
+	candidates := []ReplicaConn[*fakeReplica]{
+		{Name: "master", Conn: master},
+		{Name: "standby", Conn: standby},
+		{Name: "promoted", Conn: promoted},
+	}
+
+	primary, ok := DetectPrimary(context.Background(), candidates)
+	if !ok || primary.Name != "promoted" {
+		t.Errorf("DetectPrimary() = %v, %v, want promoted", primary.Name, ok)
+	}
+
+	// The master comes back as a primary and is preferred
+	master.pingErr = nil
+	if primary, _ := DetectPrimary(context.Background(), candidates); primary.Name != "master" {
+		t.Errorf("DetectPrimary() = %v, want master", primary.Name)
+	}
+
+	// Only standbys left
+	master.standby = true
+	promoted.standby = true
+	if primary, ok := DetectPrimary(context.Background(), candidates); ok {
+		t.Errorf("DetectPrimary() = %v, want none", primary.Name)
+	}
+}
+
+func TestReplicaSetConnections(t *testing.T) {
+	s := NewReplicaSet[*fakeReplica](ReplicaBalancingRoundRobin, 0, 0)
+	s.Add("a", 1, &fakeReplica{name: "a"}, true)
+	s.Add("b", 1, &fakeReplica{name: "b"}, false)
+
+	conns := s.Connections()
+	if len(conns) != 1 || conns[0].Name != "a" {
+		t.Errorf("Connections() = %v, want only a", conns)
+	}
+}
+
+func TestConfigDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		password string
+		attrs    string
+		want     string
+	}{
+		{
+			name:     "single host",
+			host:     "db1",
+			password: "secret",
+			want:     "host=db1 port=5432 user=postgres password=secret dbname=app sslmode=disable",
+		},
+		{
+			name: "empty password",
+			host: "db1",
+			want: "host=db1 port=5432 user=postgres password='' dbname=app sslmode=disable",
+		},
+		{
+			name:     "quoted password",
+			host:     "db1",
+			password: `it's a \secret`,
+			want:     `host=db1 port=5432 user=postgres password='it\'s a \\secret' dbname=app sslmode=disable`,
+		},
+		{
+			name:     "multiple hosts",
+			host:     "db1, db2:5433,db3",
+			password: "secret",
+			attrs:    "read-write",
+			want:     "host=db1,db2,db3 port=5432,5433,5432 user=postgres password=secret dbname=app sslmode=disable target_session_attrs=read-write",
+		},
+		{
+			name:     "ipv6 host",
+			host:     "::1",
+			password: "secret",
+			want:     "host=::1 port=5432 user=postgres password=secret dbname=app sslmode=disable",
+		},
+		{
+			name:     "ipv6 host with port",
+			host:     "[fe80::1]:5433",
+			password: "secret",
+			want:     "host=fe80::1 port=5433 user=postgres password=secret dbname=app sslmode=disable",
+		},
+		{
+			name:     "ipv6 hosts in a list",
+			host:     "[::1]:5433,fe80::1,db3:5434",
+			password: "secret",
+			want:     "host=::1,fe80::1,db3 port=5433,5432,5434 user=postgres password=secret dbname=app sslmode=disable",
+		},
+	}
+
+	for _, tt := range tests {
+		config := &Config{
+			Host:               tt.host,
+			Port:               5432,
+			User:               "postgres",
+			Password:           tt.password,
+			Database:           "app",
+			SSLMode:            "disable",
+			TargetSessionAttrs: tt.attrs,
+		}
+		if got := config.DSN(); got != tt.want {
+			t.Errorf("%s: DSN() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigAddress(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "db1", want: "db1:5432"},
+		{host: "::1", want: "[::1]:5432"},
+		{host: "[::1]:5433", want: "[::1]:5433"},
+		{host: "db1,db2:5433", want: "db1,db2:5433"},
+	}
+
+	for _, tt := range tests {
+		config := &Config{Host: tt.host, Port: 5432}
+		if got := config.Address(); got != tt.want {
+			t.Errorf("Address() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestConfigValidateTargetSessionAttrs(t *testing.T) {
+	config := DefaultConfig()
+	config.TargetSessionAttrs = "prefer-standby"
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	config.TargetSessionAttrs = "primary-only"
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() with an invalid target_session_attrs error = nil, want error")
+	}
+}
@@ -6,20 +6,71 @@ import (
 	"fmt"
 
 	_postgres "go-libs/pkg/postgres"
+
+	"gorm.io/gorm"
 )
 
+// isolationLevels maps isolation levels to database/sql
+var isolationLevels = map[_postgres.IsolationLevel]sql.IsolationLevel{
+	_postgres.IsolationDefault:        sql.LevelDefault,
+	_postgres.IsolationReadCommitted:  sql.LevelReadCommitted,
+	_postgres.IsolationRepeatableRead: sql.LevelRepeatableRead,
+	_postgres.IsolationSerializable:   sql.LevelSerializable,
+}
+
 // BeginTx starts a new transaction
 func (c *Connection) BeginTx(ctx context.Context) (_postgres.Transaction, error) {
+	return c.BeginTxWithOptions(ctx, _postgres.TxOptions{})
+}
+
+// BeginTxWithOptions starts a new transaction with the given isolation level and access mode
+func (c *Connection) BeginTxWithOptions(ctx context.Context, opts _postgres.TxOptions) (_postgres.Transaction, error) {
 	if c.db == nil {
 		return nil, fmt.Errorf("database not connected")
 	}
 
-	tx := c.db.WithContext(ctx).Begin()
+	level, ok := isolationLevels[opts.Isolation]
+	if !ok {
+		return nil, fmt.Errorf("invalid isolation level: %s", opts.Isolation)
+	}
+
+	tx := c.db.WithContext(ctx).Begin(&sql.TxOptions{
+		Isolation: level,
+		ReadOnly:  opts.ReadOnly,
+	})
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
 
-	return &Transaction{tx: tx}, nil
+	return &Transaction{tx: tx, conn: c}, nil
+}
+
+// WithTx runs fn in a transaction, see _postgres.RunTx. Exec, Query, QueryRow and
+// WithContext on this connection called with the context passed to fn join the transaction.
+func (c *Connection) WithTx(ctx context.Context, opts _postgres.TxOptions, fn _postgres.TxFunc) error {
+	return _postgres.RunTx(ctx, c, opts, fn)
+}
+
+// WithContext returns a GORM session bound to ctx, inside the transaction ctx carries if
+// it was started on this connection
+func (c *Connection) WithContext(ctx context.Context) *gorm.DB {
+	if tx, ok := c.txFromContext(ctx); ok {
+		return tx.tx.WithContext(ctx)
+	}
+	return c.db.WithContext(ctx)
+}
+
+// txFromContext returns the transaction of this connection carried by ctx
+func (c *Connection) txFromContext(ctx context.Context) (*Transaction, bool) {
+	tx, ok := _postgres.TxFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	t, ok := tx.(*Transaction)
+	if !ok || t.conn != c {
+		return nil, false
+	}
+	return t, true
 }
 
 // Exec executes a query
@@ -27,6 +78,9 @@ func (c *Connection) Exec(ctx context.Context, query string, args ...any) error
 	if c.db == nil {
 		return fmt.Errorf("database not connected")
 	}
+	if tx, ok := c.txFromContext(ctx); ok {
+		return tx.Exec(ctx, query, args...)
+	}
 
 	queryCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
 	defer cancel()
@@ -34,32 +88,38 @@ func (c *Connection) Exec(ctx context.Context, query string, args ...any) error
 	return c.db.WithContext(queryCtx).Exec(query, args...).Error
 }
 
-// Query executes a query and returns rows
+// Query executes a query and returns rows. The query timeout covers reading the rows,
+// which must be closed.
 func (c *Connection) Query(ctx context.Context, query string, args ...any) (_postgres.Rows, error) {
 	if c.db == nil {
 		return nil, fmt.Errorf("database not connected")
 	}
+	if tx, ok := c.txFromContext(ctx); ok {
+		return tx.Query(ctx, query, args...)
+	}
 
 	queryCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
-	defer cancel()
 
 	rows, err := c.db.WithContext(queryCtx).Raw(query, args...).Rows()
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	return &RowsWrapper{rows: rows}, nil
+	return &RowsWrapper{rows: rows, cancel: cancel}, nil
 }
 
 // QueryRow executes a query and returns a single row
 func (c *Connection) QueryRow(ctx context.Context, query string, args ...any) _postgres.Row {
 	if c.db == nil {
-		return &RowWrapper{row: &sql.Row{}}
+		return &RowWrapper{err: fmt.Errorf("database not connected")}
+	}
+	if tx, ok := c.txFromContext(ctx); ok {
+		return tx.QueryRow(ctx, query, args...)
 	}
 
 	queryCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
-	defer cancel()
 
 	row := c.db.WithContext(queryCtx).Raw(query, args...).Row()
-	return &RowWrapper{row: row}
+	return &RowWrapper{row: row, cancel: cancel}
 }
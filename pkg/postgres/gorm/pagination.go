@@ -0,0 +1,19 @@
+package _gorm_postgres
+
+import (
+	"context"
+
+	_postgres "go-libs/pkg/postgres"
+)
+
+// Paginate reads a keyset page of T, see _postgres.Paginate. The query uses ? placeholders,
+// which GORM binds itself.
+func Paginate[T any](ctx context.Context, db _postgres.DatabaseClient, q *_postgres.PageQuery, req _postgres.PageRequest) (*_postgres.Page[T], error) {
+	return _postgres.Paginate[T](ctx, db, nil, q, req)
+}
+
+// PaginateOffset reads a page of T by page number with the total count, see
+// _postgres.PaginateOffset. The query uses ? placeholders, which GORM binds itself.
+func PaginateOffset[T any](ctx context.Context, db _postgres.DatabaseClient, q *_postgres.PageQuery, req _postgres.PageRequest) (*_postgres.Page[T], error) {
+	return _postgres.PaginateOffset[T](ctx, db, nil, q, req)
+}
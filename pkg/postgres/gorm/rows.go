@@ -1,10 +1,15 @@
 package _gorm_postgres
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
 
 // RowsWrapper wraps sql.Rows to implement interfaces.Rows
 type RowsWrapper struct {
 	rows *sql.Rows
+	// cancel releases the query timeout once the rows are closed
+	cancel context.CancelFunc
 }
 
 func (r *RowsWrapper) Next() bool {
@@ -16,18 +21,35 @@ func (r *RowsWrapper) Scan(dest ...any) error {
 }
 
 func (r *RowsWrapper) Close() error {
-	return r.rows.Close()
+	err := r.rows.Close()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return err
 }
 
 func (r *RowsWrapper) Err() error {
 	return r.rows.Err()
 }
 
+func (r *RowsWrapper) Columns() ([]string, error) {
+	return r.rows.Columns()
+}
+
 // RowWrapper wraps sql.Row to implement interfaces.Row
 type RowWrapper struct {
 	row *sql.Row
+	err error
+	// cancel releases the query timeout once the row is scanned
+	cancel context.CancelFunc
 }
 
 func (r *RowWrapper) Scan(dest ...any) error {
+	if r.cancel != nil {
+		defer r.cancel()
+	}
+	if r.err != nil {
+		return r.err
+	}
 	return r.row.Scan(dest...)
 }
@@ -10,7 +10,8 @@ import (
 
 // Transaction wraps GORM transaction
 type Transaction struct {
-	tx *gorm.DB
+	tx   *gorm.DB
+	conn *Connection
 }
 
 // Commit commits the transaction
@@ -42,3 +43,8 @@ func (t *Transaction) QueryRow(ctx context.Context, query string, args ...any) _
 	row := t.tx.WithContext(ctx).Raw(query, args...).Row()
 	return &RowWrapper{row: row}
 }
+
+// DB returns the underlying GORM transaction
+func (t *Transaction) DB() *gorm.DB {
+	return t.tx
+}
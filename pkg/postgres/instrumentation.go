@@ -0,0 +1,328 @@
+package _postgres
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	_logger "go-libs/pkg/logger"
+)
+
+// MetricsRecorder receives a measurement for every query. Implementations typically observe
+// duration in a latency histogram labelled by statement and count non-nil errors.
+type MetricsRecorder interface {
+	RecordQuery(ctx context.Context, statement string, duration time.Duration, err error)
+}
+
+// PoolMetricsRecorder receives the pool statistics of a connection, see StatsReporter
+type PoolMetricsRecorder interface {
+	RecordPoolStats(ctx context.Context, name string, stats ConnectionStats)
+}
+
+// Tracer starts a span around every query
+type Tracer interface {
+	StartSpan(ctx context.Context, name string, attributes map[string]string) (context.Context, Span)
+}
+
+// Span is a trace span started by a Tracer
+type Span interface {
+	// End finishes the span, recording err if it is not nil
+	End(err error)
+}
+
+// InstrumentationConfig holds the collaborators of query instrumentation. Any of them may be
+// nil to disable that part.
+type InstrumentationConfig struct {
+	// Metrics records query latency and errors, labelled by the normalised statement
+	Metrics MetricsRecorder
+	// Tracer emits a span per query
+	Tracer Tracer
+	// Logger logs queries slower than SlowThreshold with the types of their arguments
+	Logger *_logger.Logger
+	// SlowThreshold is the duration from which a query is logged (0 disables slow logging)
+	SlowThreshold time.Duration
+	// LogArgValues logs argument values instead of their types. Arguments bound to a column
+	// listed in the logger's HideFields are masked, any other value is logged in clear.
+	LogArgValues bool
+	// MaxArgLength truncates long arguments in logs
+	MaxArgLength int
+}
+
+// DefaultInstrumentationConfig returns an instrumentation configuration with sensible defaults
+func DefaultInstrumentationConfig() InstrumentationConfig {
+	return InstrumentationConfig{
+		SlowThreshold: 200 * time.Millisecond,
+		MaxArgLength:  64,
+	}
+}
+
+var (
+	// valueListPattern matches a list of placeholders, as in IN (?, ?, ?)
+	valueListPattern = regexp.MustCompile(`\?(?:, \?)+`)
+	// rowListPattern matches the rows of a multi-row VALUES
+	rowListPattern = regexp.MustCompile(`\(\?\)(?:, \(\?\))+`)
+)
+
+// NormalizeQuery reduces a statement to a low-cardinality label: comments are removed,
+// whitespace is collapsed, literals and parameters become ? and lists of them a single ?,
+// so "SELECT * FROM users WHERE id IN ($1, $2)" and "... IN (1, 2, 3)" share a label.
+func NormalizeQuery(query string) string {
+	tokens := queryTokens(query)
+
+	var sb strings.Builder
+	for i, token := range tokens {
+		if i > 0 && !noSpaceBefore(token) && !noSpaceAfter(tokens[i-1]) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(token)
+	}
+
+	normalized := valueListPattern.ReplaceAllString(sb.String(), "?")
+	return rowListPattern.ReplaceAllString(normalized, "(?)")
+}
+
+// queryTokens splits a statement into words, quoted identifiers, operators and punctuation,
+// dropping comments and replacing literals and parameters with ?
+func queryTokens(query string) []string {
+	var tokens []string
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i++
+		case unicode.IsSpace(r):
+		case r == '\'':
+			// A doubled quote is an escaped quote inside the literal
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			tokens = append(tokens, "?")
+		case r == '"':
+			start := i
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+			}
+			tokens = append(tokens, string(runes[start:min(i+1, len(runes))]))
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+			i--
+		case unicode.IsDigit(r) || r == '$':
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			tokens = append(tokens, "?")
+		case strings.ContainsRune(sqlOperatorChars, r):
+			start := i
+			for i+1 < len(runes) && strings.ContainsRune(sqlOperatorChars, runes[i+1]) &&
+				!(runes[i+1] == '-' && i+2 < len(runes) && runes[i+2] == '-') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i+1]))
+		default:
+			tokens = append(tokens, string(r))
+		}
+	}
+
+	return tokens
+}
+
+// sqlOperatorChars are the characters making up operators such as <= or ::
+const sqlOperatorChars = "+-*/<>=~!@#%^&|`:"
+
+// noSpaceBefore reports whether token is written right after the previous token
+func noSpaceBefore(token string) bool {
+	switch token {
+	case ")", ",", ".", ";", "::", "]", "[":
+		return true
+	}
+	return false
+}
+
+// noSpaceAfter reports whether the next token is written right after token
+func noSpaceAfter(token string) bool {
+	switch token {
+	case "(", ".", "::", "[":
+		return true
+	}
+	return false
+}
+
+var (
+	// comparisonPattern matches a column compared to a parameter, as in "password = $2"
+	comparisonPattern = regexp.MustCompile(`(?i)([a-z_][a-z0-9_]*)"?\s*(?:=|<>|!=|<=|>=|<|>|\s(?:not\s+)?i?like)\s*\$(\d+)`)
+	// insertPattern matches the column list and VALUES of an INSERT
+	insertPattern = regexp.MustCompile(`(?is)insert\s+into\s+[^(]+\(([^)]*)\)\s*values\s*(.*)`)
+	// parameterPattern matches a parameter
+	parameterPattern = regexp.MustCompile(`\$(\d+)`)
+)
+
+// QueryArgTypes formats the types of the arguments of a query for logs, so no value is
+// ever logged
+func QueryArgTypes(args []any) []string {
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			formatted[i] = "nil"
+			continue
+		}
+		formatted[i] = fmt.Sprintf("%T", arg)
+	}
+	return formatted
+}
+
+// RedactQueryArgs formats the values of the arguments of a query for logs. An argument is
+// masked when hidden reports true for the column it is bound to, found from comparisons
+// such as "password = $2" and from the column list of an INSERT. Values passed any other
+// way, such as function arguments, are not masked. Long arguments are truncated to maxLen.
+func RedactQueryArgs(query string, args []any, hidden func(string) bool, maxLen int) []string {
+	masked := make(map[int]bool)
+	if hidden != nil {
+		for _, m := range comparisonPattern.FindAllStringSubmatch(query, -1) {
+			if n, err := strconv.Atoi(m[2]); err == nil && hidden(strings.ToLower(m[1])) {
+				masked[n] = true
+			}
+		}
+
+		if m := insertPattern.FindStringSubmatch(query); m != nil {
+			columns := strings.Split(m[1], ",")
+			for k, p := range parameterPattern.FindAllStringSubmatch(m[2], -1) {
+				column := strings.ToLower(strings.Trim(strings.TrimSpace(columns[k%len(columns)]), `"`))
+				if n, err := strconv.Atoi(p[1]); err == nil && hidden(column) {
+					masked[n] = true
+				}
+			}
+		}
+	}
+
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		if masked[i+1] {
+			formatted[i] = "***"
+			continue
+		}
+		s := fmt.Sprint(arg)
+		if maxLen > 0 && len(s) > maxLen {
+			s = s[:maxLen] + "..."
+		}
+		formatted[i] = s
+	}
+	return formatted
+}
+
+// QueryOperation returns the lower-cased first keyword of a statement, such as "select"
+func QueryOperation(query string) string {
+	if words := sqlKeywords(query); len(words) > 0 {
+		return strings.ToLower(words[0])
+	}
+	return ""
+}
+
+// StatsReporter passes the pool statistics of a connection to a recorder periodically
+type StatsReporter struct {
+	name     string
+	provider StatsProvider
+	recorder PoolMetricsRecorder
+	interval time.Duration
+
+	// Control
+	running bool
+	cancel  context.CancelFunc
+	doneCh  chan struct{}
+	mu      sync.Mutex
+}
+
+// NewStatsReporter creates a reporter of the statistics of provider, labelled with name
+func NewStatsReporter(name string, provider StatsProvider, recorder PoolMetricsRecorder, interval time.Duration) *StatsReporter {
+	return &StatsReporter{
+		name:     name,
+		provider: provider,
+		recorder: recorder,
+		interval: interval,
+	}
+}
+
+// Start reports the statistics every interval in the background
+func (r *StatsReporter) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return fmt.Errorf("stats reporter already started")
+	}
+	if r.interval <= 0 {
+		return fmt.Errorf("report interval must be greater than 0")
+	}
+	if r.recorder == nil {
+		return fmt.Errorf("recorder is required")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.doneCh = make(chan struct{})
+	r.running = true
+
+	go r.run(ctx, r.doneCh)
+
+	return nil
+}
+
+// run reports the statistics until ctx is done
+func (r *StatsReporter) run(ctx context.Context, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.Report(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report passes the current statistics to the recorder
+func (r *StatsReporter) Report(ctx context.Context) {
+	r.recorder.RecordPoolStats(ctx, r.name, r.provider.Stats())
+}
+
+// Stop stops the background reports
+func (r *StatsReporter) Stop() {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.cancel()
+	doneCh := r.doneCh
+	r.running = false
+	r.mu.Unlock()
+
+	<-doneCh
+}
@@ -0,0 +1,175 @@
+package _postgres
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	_logger "go-libs/pkg/logger"
+)
+
+type fakePoolMetrics struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (m *fakePoolMetrics) RecordPoolStats(ctx context.Context, name string, stats ConnectionStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.names = append(m.names, name)
+}
+
+type fakeStats struct{}
+
+func (fakeStats) Stats() ConnectionStats {
+	return ConnectionStats{OpenConnections: 2}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "parameters",
+			query: "SELECT id, name FROM users WHERE id = $1 AND status = $2",
+			want:  "SELECT id, name FROM users WHERE id = ? AND status = ?",
+		},
+		{
+			name:  "literals",
+			query: "SELECT * FROM users WHERE name = 'O''Brien' AND age > 42.5",
+			want:  "SELECT * FROM users WHERE name = ? AND age > ?",
+		},
+		{
+			name:  "comments and whitespace",
+			query: "-- find user\nSELECT *\n\tFROM users /* hint */ WHERE id=$1",
+			want:  "SELECT * FROM users WHERE id = ?",
+		},
+		{
+			name:  "in list",
+			query: "SELECT * FROM users WHERE id IN ($1, $2, $3)",
+			want:  "SELECT * FROM users WHERE id IN (?)",
+		},
+		{
+			name:  "multi-row values",
+			query: "INSERT INTO tags (name) VALUES ($1), ($2), ($3)",
+			want:  "INSERT INTO tags (name) VALUES (?)",
+		},
+		{
+			name:  "casts and qualified names",
+			query: `SELECT u."id", u.created_at::date FROM users u WHERE u.id = $1::bigint`,
+			want:  `SELECT u."id", u.created_at::date FROM users u WHERE u.id = ?::bigint`,
+		},
+		{
+			name:  "gorm placeholders",
+			query: "UPDATE users SET name=? WHERE id=?",
+			want:  "UPDATE users SET name = ? WHERE id = ?",
+		},
+		{
+			name:  "comment only",
+			query: "-- ping",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeQuery(tt.query); got != tt.want {
+				t.Errorf("NormalizeQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactQueryArgs(t *testing.T) {
+	log := _logger.New(_logger.Config{HideFields: []string{"password", "token"}})
+
+	tests := []struct {
+		name  string
+		query string
+		args  []any
+		want  []string
+	}{
+		{
+			name:  "plain",
+			query: "SELECT * FROM users WHERE id = $1",
+			args:  []any{42},
+			want:  []string{"42"},
+		},
+		{
+			name:  "comparison",
+			query: "SELECT * FROM users WHERE email = $1 AND password = $2",
+			args:  []any{"a@b.c", "secret"},
+			want:  []string{"a@b.c", "***"},
+		},
+		{
+			name:  "update",
+			query: `UPDATE users SET "token"=$2 WHERE id = $1`,
+			args:  []any{1, "abc"},
+			want:  []string{"1", "***"},
+		},
+		{
+			name:  "insert",
+			query: "INSERT INTO users (email, password) VALUES ($1, $2), ($3, $4)",
+			args:  []any{"a@b.c", "secret", "d@e.f", "hunter2"},
+			want:  []string{"a@b.c", "***", "d@e.f", "***"},
+		},
+		{
+			name:  "truncated",
+			query: "SELECT * FROM logs WHERE message = $1",
+			args:  []any{"0123456789abcdef"},
+			want:  []string{"01234567..."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactQueryArgs(tt.query, tt.args, log.IsHidden, 8); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RedactQueryArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryArgTypes(t *testing.T) {
+	got := QueryArgTypes([]any{42, "secret", []byte("x"), nil, time.Second})
+	want := []string{"int", "string", "[]uint8", "nil", "time.Duration"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryArgTypes() = %q, want %q", got, want)
+	}
+}
+
+func TestStatsReporter(t *testing.T) {
+	metrics := &fakePoolMetrics{}
+	reporter := NewStatsReporter("main", fakeStats{}, metrics, 10*time.Millisecond)
+
+	if err := reporter.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := reporter.Start(); err == nil {
+		t.Errorf("Start() twice error = nil, want an error")
+	}
+	time.Sleep(35 * time.Millisecond)
+	reporter.Stop()
+
+	metrics.mu.Lock()
+	reports := len(metrics.names)
+	metrics.mu.Unlock()
+	if reports < 2 {
+		t.Errorf("RecordPoolStats() calls = %v, want at least 2", reports)
+	}
+	if metrics.names[0] != "main" {
+		t.Errorf("RecordPoolStats() name = %v, want %v", metrics.names[0], "main")
+	}
+
+	// No report after Stop
+	time.Sleep(20 * time.Millisecond)
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if len(metrics.names) != reports {
+		t.Errorf("RecordPoolStats() calls after Stop() = %v, want %v", len(metrics.names), reports)
+	}
+}
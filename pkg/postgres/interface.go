@@ -2,6 +2,7 @@ package _postgres
 
 import (
 	"context"
+	"iter"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -19,8 +20,15 @@ type DatabaseClient interface {
 	// Health check
 	IsHealthy(ctx context.Context) bool
 
+	// Queries
+	Exec(ctx context.Context, query string, args ...any) error
+	Query(ctx context.Context, query string, args ...any) (Rows, error)
+	QueryRow(ctx context.Context, query string, args ...any) Row
+
 	// Transaction support
 	BeginTx(ctx context.Context) (Transaction, error)
+	BeginTxWithOptions(ctx context.Context, opts TxOptions) (Transaction, error)
+	WithTx(ctx context.Context, opts TxOptions, fn TxFunc) error
 }
 
 // MasterSlaveClient defines the interface for master-slave database operations
@@ -35,6 +43,11 @@ type MasterSlaveClient interface {
 	// Role information
 	IsMaster() bool
 	IsSlave() bool
+
+	// Primary detection
+	PrimaryName() string
+	RefreshPrimary(ctx context.Context) error
+	OnRoleChange(fn func(RoleChange))
 }
 
 // Transaction interface for transaction operations
@@ -52,6 +65,7 @@ type Rows interface {
 	Scan(dest ...any) error
 	Close() error
 	Err() error
+	Columns() ([]string, error)
 }
 
 // Row interface for single row query results
@@ -106,6 +120,7 @@ type PgxClient interface {
 	InsertModel(ctx context.Context, model any) error
 	UpsertModel(ctx context.Context, model any, primaryKeys ...string) error
 	BatchInsertModel(ctx context.Context, models []any, batchSize int) error
+	CopyModels(ctx context.Context, source iter.Seq[any], opts CopyOptions) (*CopyResult, error)
 }
 
 // PgxMasterSlaveClient extends PgxClient with master-slave support
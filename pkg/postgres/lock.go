@@ -0,0 +1,11 @@
+package _postgres
+
+import "hash/fnv"
+
+// AdvisoryLockKey hashes a lock name to the int64 key taken by the pg_advisory_* functions.
+// Every instance computes the same key for the same name.
+func AdvisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
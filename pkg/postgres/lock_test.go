@@ -0,0 +1,12 @@
+package _postgres
+
+import "testing"
+
+func TestAdvisoryLockKey(t *testing.T) {
+	if AdvisoryLockKey("jobs") != AdvisoryLockKey("jobs") {
+		t.Errorf("AdvisoryLockKey() differs for the same name")
+	}
+	if AdvisoryLockKey("jobs") == AdvisoryLockKey("reports") {
+		t.Errorf("AdvisoryLockKey() is the same for different names")
+	}
+}
@@ -0,0 +1,60 @@
+package _migrate_postgres
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	_postgres "go-libs/pkg/postgres"
+)
+
+// identifierPattern matches a table name, optionally schema-qualified
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Config holds the configuration of a migrator
+type Config struct {
+	// Table records the applied migrations, it may be schema-qualified
+	Table string `json:"table" yaml:"table"`
+
+	// LockKey is the advisory lock held while migrating. 0 derives it from Table, so runners
+	// of different tables do not block each other.
+	LockKey int64 `json:"lock_key" yaml:"lock_key"`
+	// LockTimeout is how long to wait for another runner to release the lock
+	LockTimeout time.Duration `json:"lock_timeout" yaml:"lock_timeout"`
+	// LockRetryInterval is the period of lock attempts while waiting
+	LockRetryInterval time.Duration `json:"lock_retry_interval" yaml:"lock_retry_interval"`
+
+	// DryRun reports the migrations that would run without running them
+	DryRun bool `json:"dry_run" yaml:"dry_run"`
+}
+
+// DefaultConfig returns a migrator configuration with sensible defaults
+func DefaultConfig() *Config {
+	return &Config{
+		Table:             "schema_migrations",
+		LockTimeout:       time.Minute,
+		LockRetryInterval: time.Second,
+	}
+}
+
+// Validate checks if the configuration is valid
+func (c *Config) Validate() error {
+	if !identifierPattern.MatchString(c.Table) {
+		return fmt.Errorf("invalid table name: %q", c.Table)
+	}
+	if c.LockTimeout <= 0 {
+		return fmt.Errorf("lock_timeout must be greater than 0")
+	}
+	if c.LockRetryInterval <= 0 {
+		return fmt.Errorf("lock_retry_interval must be greater than 0")
+	}
+	return nil
+}
+
+// lockKey returns the advisory lock key
+func (c *Config) lockKey() int64 {
+	if c.LockKey != 0 {
+		return c.LockKey
+	}
+	return _postgres.AdvisoryLockKey("migrate:" + c.Table)
+}
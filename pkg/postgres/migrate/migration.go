@@ -0,0 +1,174 @@
+package _migrate_postgres
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NoTransactionDirective in a migration file runs it outside a transaction, which statements
+// such as CREATE INDEX CONCURRENTLY require
+const NoTransactionDirective = "-- migrate:no-transaction"
+
+// filePattern matches migration file names: <version>_<name>.up.sql or .down.sql
+var filePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_-]+)\.(up|down)\.sql$`)
+
+// namePattern matches the name part of a migration file
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Migration is a versioned schema change
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+
+	// UpNoTransaction and DownNoTransaction are set by NoTransactionDirective
+	UpNoTransaction   bool
+	DownNoTransaction bool
+}
+
+// String returns the file name prefix of the migration
+func (m *Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// HasDown reports whether the migration can be reverted
+func (m *Migration) HasDown() bool {
+	return strings.TrimSpace(m.Down) != ""
+}
+
+// Checksum returns the SHA-256 of the up SQL, recorded to detect edits of applied migrations
+func (m *Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads the migrations in dir of fsys, typically an embed.FS, sorted by version.
+// Files that do not end in .sql are ignored.
+func Load(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, m.Name, match[2])
+		}
+
+		sql := string(data)
+		if match[3] == "up" {
+			m.Up = sql
+			m.UpNoTransaction = hasNoTransactionDirective(sql)
+		} else {
+			m.Down = sql
+			m.DownNoTransaction = hasNoTransactionDirective(sql)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration %s has no up SQL", m)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// hasNoTransactionDirective reports whether the leading comments of sql contain
+// NoTransactionDirective
+func hasNoTransactionDirective(sql string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(sql))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			return false
+		}
+		if line == NoTransactionDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// Create writes empty up and down files for a new migration in dir, versioned by the UTC
+// time now, and returns their paths
+func Create(dir, name string, now time.Time) (string, string, error) {
+	name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+	if !namePattern.MatchString(name) {
+		return "", "", fmt.Errorf("invalid migration name: %q", name)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	prefix := now.UTC().Format("20060102150405") + "_" + name
+	upPath := filepath.Join(dir, prefix+".up.sql")
+	downPath := filepath.Join(dir, prefix+".down.sql")
+
+	if err := createFile(upPath, fmt.Sprintf("-- %s: up\n", prefix)); err != nil {
+		return "", "", err
+	}
+	if err := createFile(downPath, fmt.Sprintf("-- %s: down\n", prefix)); err != nil {
+		os.Remove(upPath)
+		return "", "", err
+	}
+
+	return upPath, downPath, nil
+}
+
+// createFile writes a file that must not exist yet
+func createFile(name, content string) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,130 @@
+package _migrate_postgres
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_add_index.up.sql":      {Data: []byte("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY users_email ON users (email);")},
+		"migrations/0002_add_index.down.sql":    {Data: []byte("DROP INDEX users_email;")},
+		"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id BIGSERIAL PRIMARY KEY, email TEXT);")},
+		"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"migrations/0003_seed.up.sql":           {Data: []byte("INSERT INTO users (email) VALUES ('admin@example.com');")},
+		"migrations/README.md":                  {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) != 3 {
+		t.Fatalf("Load() returned %d migrations, want 3", len(migrations))
+	}
+
+	tests := []struct {
+		version int64
+		name    string
+		noTx    bool
+		hasDown bool
+	}{
+		{1, "create_users", false, true},
+		{2, "add_index", true, true},
+		{3, "seed", false, false},
+	}
+	for i, tt := range tests {
+		m := migrations[i]
+		if m.Version != tt.version || m.Name != tt.name {
+			t.Errorf("migrations[%d] = %s, want %d_%s", i, m, tt.version, tt.name)
+		}
+		if m.UpNoTransaction != tt.noTx {
+			t.Errorf("migrations[%d].UpNoTransaction = %v, want %v", i, m.UpNoTransaction, tt.noTx)
+		}
+		if m.HasDown() != tt.hasDown {
+			t.Errorf("migrations[%d].HasDown() = %v, want %v", i, m.HasDown(), tt.hasDown)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"invalid name", fstest.MapFS{"m/create_users.up.sql": {Data: []byte("SELECT 1")}}},
+		{"duplicate version", fstest.MapFS{
+			"m/0001_a.up.sql": {Data: []byte("SELECT 1")},
+			"m/0001_b.up.sql": {Data: []byte("SELECT 1")},
+		}},
+		{"down only", fstest.MapFS{"m/0001_a.down.sql": {Data: []byte("SELECT 1")}}},
+		{"missing directory", fstest.MapFS{}},
+	}
+
+	for _, tt := range tests {
+		if _, err := Load(tt.fsys, "m"); err == nil {
+			t.Errorf("%s: Load() error = nil, want error", tt.name)
+		}
+	}
+}
+
+func TestNoTransactionDirective(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY i ON t (c);", true},
+		{"-- add an index\n\n-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY i ON t (c);", true},
+		{"CREATE TABLE t (c INT);\n-- migrate:no-transaction", false},
+		{"CREATE TABLE t (c INT);", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasNoTransactionDirective(tt.sql); got != tt.want {
+			t.Errorf("hasNoTransactionDirective(%q) = %v, want %v", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	a := &Migration{Up: "CREATE TABLE a (id INT);"}
+	b := &Migration{Up: "CREATE TABLE a (id BIGINT);"}
+	if a.Checksum() == b.Checksum() {
+		t.Errorf("Checksum() is the same for different SQL")
+	}
+	if a.Checksum() != (&Migration{Up: a.Up}).Checksum() {
+		t.Errorf("Checksum() differs for the same SQL")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	upPath, downPath, err := Create(dir, "Add Users", now)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if filepath.Base(upPath) != "20240501123000_add_users.up.sql" {
+		t.Errorf("Create() up = %s, want 20240501123000_add_users.up.sql", upPath)
+	}
+	if filepath.Base(downPath) != "20240501123000_add_users.down.sql" {
+		t.Errorf("Create() down = %s, want 20240501123000_add_users.down.sql", downPath)
+	}
+
+	migrations, err := Load(os.DirFS(dir), ".")
+	if err != nil || len(migrations) != 1 || migrations[0].Version != 20240501123000 {
+		t.Errorf("Load() = %v, %v, want the created migration", migrations, err)
+	}
+
+	if _, _, err := Create(dir, "add users", now); err == nil || !strings.Contains(err.Error(), "exists") {
+		t.Errorf("Create() twice error = %v, want an exists error", err)
+	}
+	if _, _, err := Create(dir, "drop;users", now); err == nil {
+		t.Errorf("Create() with an invalid name error = nil, want error")
+	}
+}
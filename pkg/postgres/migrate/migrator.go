@@ -0,0 +1,365 @@
+package _migrate_postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	_postgres "go-libs/pkg/postgres"
+)
+
+var (
+	// ErrChecksumMismatch is returned when applied migrations were edited since
+	ErrChecksumMismatch = errors.New("migration checksum mismatch")
+	// ErrLocked is returned when another runner holds the migration lock past LockTimeout
+	ErrLocked = errors.New("migrations are locked by another runner")
+)
+
+// MigrationStatus describes a migration and whether it was applied
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Drift is set when the up SQL changed since the migration was applied
+	Drift bool
+	// Missing is set when an applied migration has no file anymore
+	Missing bool
+}
+
+// appliedMigration is a row of the migrations table
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator applies and reverts migrations. It works with any DatabaseClient, such as the
+// pgx and GORM connections.
+type Migrator struct {
+	db         _postgres.DatabaseClient
+	migrations []*Migration
+	config     *Config
+}
+
+// NewMigrator creates a migrator for the migrations in dir of fsys, typically an embed.FS
+func NewMigrator(db _postgres.DatabaseClient, fsys fs.FS, dir string, cfg *Config) (*Migrator, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid migrator config: %w", err)
+	}
+
+	migrations, err := Load(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		config:     cfg,
+	}, nil
+}
+
+// Migrations returns the loaded migrations sorted by version
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+// Up applies every pending migration and returns them
+func (m *Migrator) Up(ctx context.Context) ([]*Migration, error) {
+	return m.UpTo(ctx, 0)
+}
+
+// UpTo applies the pending migrations up to version included, all of them if version is 0
+func (m *Migrator) UpTo(ctx context.Context, version int64) ([]*Migration, error) {
+	var done []*Migration
+	err := m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkDrift(m.migrations, applied); err != nil {
+			return err
+		}
+
+		for _, mig := range planUp(m.migrations, applied, version) {
+			if m.config.DryRun {
+				fmt.Printf("Would apply migration %s\n", mig)
+				done = append(done, mig)
+				continue
+			}
+
+			record := fmt.Sprintf(
+				"INSERT INTO %s (version, name, checksum) VALUES (%d, %s, %s)",
+				m.config.Table, mig.Version, quoteLiteral(mig.Name), quoteLiteral(mig.Checksum()),
+			)
+			if err := m.run(ctx, mig.Up, mig.UpNoTransaction, record); err != nil {
+				return fmt.Errorf("failed to apply migration %s: %w", mig, err)
+			}
+			fmt.Printf("Applied migration %s\n", mig)
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the last steps applied migrations and returns them
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be greater than 0")
+	}
+
+	var done []*Migration
+	err := m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkDrift(m.migrations, applied); err != nil {
+			return err
+		}
+
+		plan, err := planDown(m.migrations, applied, steps)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range plan {
+			if m.config.DryRun {
+				fmt.Printf("Would revert migration %s\n", mig)
+				done = append(done, mig)
+				continue
+			}
+
+			record := fmt.Sprintf("DELETE FROM %s WHERE version = %d", m.config.Table, mig.Version)
+			if err := m.run(ctx, mig.Down, mig.DownNoTransaction, record); err != nil {
+				return fmt.Errorf("failed to revert migration %s: %w", mig, err)
+			}
+			fmt.Printf("Reverted migration %s\n", mig)
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status returns every known migration, loaded or applied, sorted by version
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return statuses(m.migrations, applied), nil
+}
+
+// run executes the SQL of a migration and records it, in a transaction unless noTx is set
+func (m *Migrator) run(ctx context.Context, sql string, noTx bool, record string) error {
+	if noTx {
+		if err := m.db.Exec(ctx, sql); err != nil {
+			return err
+		}
+		return m.db.Exec(ctx, record)
+	}
+
+	return m.db.WithTx(ctx, _postgres.DefaultTxOptions(), func(ctx context.Context, tx _postgres.Transaction) error {
+		if err := tx.Exec(ctx, sql); err != nil {
+			return err
+		}
+		return tx.Exec(ctx, record)
+	})
+}
+
+// withLock runs fn holding the migration advisory lock, once the migrations table exists.
+// The lock is taken in a transaction of its own, released when it is rolled back. A dry run
+// neither locks nor creates the table.
+func (m *Migrator) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.config.DryRun {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin lock transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := m.acquireLock(ctx, tx); err != nil {
+		return err
+	}
+
+	err = m.db.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	checksum TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`, m.config.Table))
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	return fn(ctx)
+}
+
+// acquireLock waits up to LockTimeout for the advisory lock
+func (m *Migrator) acquireLock(ctx context.Context, tx _postgres.Transaction) error {
+	query := fmt.Sprintf("SELECT pg_try_advisory_xact_lock(%d)", m.config.lockKey())
+	deadline := time.Now().Add(m.config.LockTimeout)
+
+	for {
+		var locked bool
+		if err := tx.QueryRow(ctx, query).Scan(&locked); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if locked {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.config.LockRetryInterval):
+		}
+	}
+}
+
+// applied reads the migrations table, which may not exist yet
+func (m *Migrator) applied(ctx context.Context) (map[int64]appliedMigration, error) {
+	var exists bool
+	query := fmt.Sprintf("SELECT to_regclass(%s) IS NOT NULL", quoteLiteral(m.config.Table))
+	if err := m.db.QueryRow(ctx, query).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check migrations table: %w", err)
+	}
+
+	applied := make(map[int64]appliedMigration)
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := m.db.Query(ctx, fmt.Sprintf(
+		"SELECT version, name, checksum, applied_at FROM %s ORDER BY version", m.config.Table,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read migrations table: %w", err)
+		}
+		applied[a.Version] = a
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read migrations table: %w", err)
+	}
+
+	return applied, nil
+}
+
+// checkDrift returns ErrChecksumMismatch when applied migrations were edited since
+func checkDrift(migrations []*Migration, applied map[int64]appliedMigration) error {
+	var drifted []string
+	for _, mig := range migrations {
+		if a, ok := applied[mig.Version]; ok && a.Checksum != mig.Checksum() {
+			drifted = append(drifted, mig.String())
+		}
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(drifted, ", "))
+	}
+	return nil
+}
+
+// planUp returns the pending migrations up to target included, all of them if target is 0
+func planUp(migrations []*Migration, applied map[int64]appliedMigration, target int64) []*Migration {
+	var plan []*Migration
+	for _, mig := range migrations {
+		if target > 0 && mig.Version > target {
+			break
+		}
+		if _, ok := applied[mig.Version]; !ok {
+			plan = append(plan, mig)
+		}
+	}
+	return plan
+}
+
+// planDown returns the last steps applied migrations, latest first
+func planDown(migrations []*Migration, applied map[int64]appliedMigration, steps int) ([]*Migration, error) {
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	if len(versions) > steps {
+		versions = versions[:steps]
+	}
+
+	byVersion := make(map[int64]*Migration, len(migrations))
+	for _, mig := range migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	plan := make([]*Migration, 0, len(versions))
+	for _, version := range versions {
+		mig, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("applied migration %d_%s has no file", version, applied[version].Name)
+		}
+		if !mig.HasDown() {
+			return nil, fmt.Errorf("migration %s has no down SQL", mig)
+		}
+		plan = append(plan, mig)
+	}
+	return plan, nil
+}
+
+// statuses merges the loaded and the applied migrations
+func statuses(migrations []*Migration, applied map[int64]appliedMigration) []MigrationStatus {
+	result := make([]MigrationStatus, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+
+	for _, mig := range migrations {
+		known[mig.Version] = true
+		status := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if a, ok := applied[mig.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = a.AppliedAt
+			status.Drift = a.Checksum != mig.Checksum()
+		}
+		result = append(result, status)
+	}
+
+	for version, a := range applied {
+		if !known[version] {
+			result = append(result, MigrationStatus{
+				Version:   version,
+				Name:      a.Name,
+				Applied:   true,
+				AppliedAt: a.AppliedAt,
+				Missing:   true,
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result
+}
+
+// quoteLiteral quotes s as an SQL string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,145 @@
+package _migrate_postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testMigrations() []*Migration {
+	return []*Migration{
+		{Version: 1, Name: "create_users", Up: "CREATE TABLE users ();", Down: "DROP TABLE users;"},
+		{Version: 2, Name: "create_orders", Up: "CREATE TABLE orders ();", Down: "DROP TABLE orders;"},
+		{Version: 3, Name: "seed", Up: "INSERT INTO users DEFAULT VALUES;"},
+	}
+}
+
+func appliedFrom(migrations ...*Migration) map[int64]appliedMigration {
+	applied := make(map[int64]appliedMigration)
+	for _, m := range migrations {
+		applied[m.Version] = appliedMigration{Version: m.Version, Name: m.Name, Checksum: m.Checksum()}
+	}
+	return applied
+}
+
+func versions(migrations []*Migration) []int64 {
+	result := make([]int64, 0, len(migrations))
+	for _, m := range migrations {
+		result = append(result, m.Version)
+	}
+	return result
+}
+
+func TestPlanUp(t *testing.T) {
+	migrations := testMigrations()
+
+	tests := []struct {
+		name    string
+		applied map[int64]appliedMigration
+		target  int64
+		want    []int64
+	}{
+		{"fresh", appliedFrom(), 0, []int64{1, 2, 3}},
+		{"partially applied", appliedFrom(migrations[0]), 0, []int64{2, 3}},
+		{"out of order", appliedFrom(migrations[0], migrations[2]), 0, []int64{2}},
+		{"up to a version", appliedFrom(), 2, []int64{1, 2}},
+		{"up to date", appliedFrom(migrations...), 0, []int64{}},
+	}
+
+	for _, tt := range tests {
+		got := versions(planUp(migrations, tt.applied, tt.target))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: planUp() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlanDown(t *testing.T) {
+	migrations := testMigrations()
+
+	plan, err := planDown(migrations, appliedFrom(migrations[0], migrations[1]), 1)
+	if err != nil || !reflect.DeepEqual(versions(plan), []int64{2}) {
+		t.Errorf("planDown() = %v, %v, want [2]", versions(plan), err)
+	}
+
+	plan, err = planDown(migrations, appliedFrom(migrations[0], migrations[1]), 5)
+	if err != nil || !reflect.DeepEqual(versions(plan), []int64{2, 1}) {
+		t.Errorf("planDown() = %v, %v, want [2 1]", versions(plan), err)
+	}
+
+	if _, err := planDown(migrations, appliedFrom(migrations...), 1); err == nil {
+		t.Errorf("planDown() without down SQL error = nil, want error")
+	}
+
+	applied := appliedFrom(migrations[0])
+	applied[9] = appliedMigration{Version: 9, Name: "removed"}
+	if _, err := planDown(migrations, applied, 1); err == nil {
+		t.Errorf("planDown() of a missing file error = nil, want error")
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	migrations := testMigrations()
+	applied := appliedFrom(migrations[0], migrations[1])
+
+	if err := checkDrift(migrations, applied); err != nil {
+		t.Errorf("checkDrift() error = %v, want nil", err)
+	}
+
+	migrations[1].Up = "CREATE TABLE orders (id BIGINT);"
+	if err := checkDrift(migrations, applied); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("checkDrift() error = %v, want ErrChecksumMismatch", err)
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	migrations := testMigrations()
+	applied := appliedFrom(migrations[0], migrations[1])
+	applied[0] = appliedMigration{Version: 0, Name: "legacy", AppliedAt: time.Now()}
+	migrations[1].Up = "CREATE TABLE orders (id BIGINT);"
+
+	got := statuses(migrations, applied)
+	want := []MigrationStatus{
+		{Version: 0, Name: "legacy", Applied: true, Missing: true},
+		{Version: 1, Name: "create_users", Applied: true},
+		{Version: 2, Name: "create_orders", Applied: true, Drift: true},
+		{Version: 3, Name: "seed"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("statuses() returned %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		got[i].AppliedAt = time.Time{}
+		if got[i] != want[i] {
+			t.Errorf("statuses()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		table string
+		valid bool
+	}{
+		{"schema_migrations", true},
+		{"app.schema_migrations", true},
+		{"schema_migrations; DROP TABLE users", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		config := DefaultConfig()
+		config.Table = tt.table
+		if err := config.Validate(); (err == nil) != tt.valid {
+			t.Errorf("Validate() with table %q error = %v, want valid %v", tt.table, err, tt.valid)
+		}
+	}
+
+	a, b := DefaultConfig(), DefaultConfig()
+	b.Table = "other_migrations"
+	if a.lockKey() == b.lockKey() {
+		t.Errorf("lockKey() is the same for different tables")
+	}
+}
@@ -0,0 +1,279 @@
+package _postgres
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRecordNotFound is returned when a query for a single record matches no rows
+var ErrRecordNotFound = errors.New("record not found")
+
+// Tabler can be implemented by a model to override its table name
+type Tabler interface {
+	TableName() string
+}
+
+// Field describes a struct field mapped to a column. Its behaviour is set with the db tag,
+// for example `db:"id,pk"`, `db:"email,omitempty"` or `db:"created_at,readonly"`:
+//   - pk: part of the primary key
+//   - omitempty: left out of inserts when it holds the zero value, so the column default applies
+//   - readonly: never written, only read back (for example generated columns)
+//   - softdelete: timestamp set instead of deleting the row (a deleted_at column is detected automatically)
+type Field struct {
+	Name       string
+	Column     string
+	Index      []int
+	Type       reflect.Type
+	PrimaryKey bool
+	OmitEmpty  bool
+	ReadOnly   bool
+	SoftDelete bool
+}
+
+// Model describes how a struct type maps to a table
+type Model struct {
+	Type        reflect.Type
+	Table       string
+	Fields      []*Field
+	PrimaryKeys []*Field
+	SoftDelete  *Field
+
+	byColumn map[string]*Field
+}
+
+// modelCache holds the metadata of every type seen so far
+var modelCache sync.Map
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// GetModel returns the cached metadata for a struct type, a pointer to one or a value of one
+func GetModel(model any) (*Model, error) {
+	t, ok := model.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(model)
+	}
+	if t == nil {
+		return nil, errors.New("model must be a struct")
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, errors.New("model must be a struct")
+	}
+
+	if cached, ok := modelCache.Load(t); ok {
+		return cached.(*Model), nil
+	}
+
+	m, err := parseModel(t)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := modelCache.LoadOrStore(t, m)
+	return actual.(*Model), nil
+}
+
+// FieldByColumn returns the field mapped to a column
+func (m *Model) FieldByColumn(column string) (*Field, bool) {
+	f, ok := m.byColumn[column]
+	return f, ok
+}
+
+// Columns returns every column of the model in field order
+func (m *Model) Columns() []string {
+	columns := make([]string, len(m.Fields))
+	for i, f := range m.Fields {
+		columns[i] = f.Column
+	}
+	return columns
+}
+
+// Value returns the value of the field in v, which must be a struct of the model type.
+// A field inside a nil embedded pointer reads as its zero value.
+func (f *Field) Value(v reflect.Value) reflect.Value {
+	fv, err := v.FieldByIndexErr(f.Index)
+	if err != nil {
+		return reflect.Zero(f.Type)
+	}
+	return fv
+}
+
+// Dest returns a pointer to the field in v for scanning, allocating nil embedded pointers
+// on the way. v must be addressable.
+func (f *Field) Dest(v reflect.Value) any {
+	for i, x := range f.Index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v.Addr().Interface()
+}
+
+// Insertable returns the fields written by an insert of v: read-only fields and
+// omitempty fields holding the zero value are left out
+func (m *Model) Insertable(v reflect.Value) []*Field {
+	fields := make([]*Field, 0, len(m.Fields))
+	for _, f := range m.Fields {
+		if f.ReadOnly {
+			continue
+		}
+		if f.OmitEmpty && f.Value(v).IsZero() {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// Updatable returns the fields an update may write: every field except primary keys,
+// read-only fields and the soft delete marker
+func (m *Model) Updatable() []*Field {
+	fields := make([]*Field, 0, len(m.Fields))
+	for _, f := range m.Fields {
+		if f.PrimaryKey || f.ReadOnly || f.SoftDelete {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// parseModel builds the metadata of a struct type
+func parseModel(t reflect.Type) (*Model, error) {
+	m := &Model{
+		Type:     t,
+		Table:    ToSnakeCase(t.Name()),
+		byColumn: make(map[string]*Field),
+	}
+
+	if tabler, ok := reflect.New(t).Interface().(Tabler); ok {
+		m.Table = tabler.TableName()
+	}
+
+	if err := m.addFields(t, nil); err != nil {
+		return nil, err
+	}
+	if len(m.Fields) == 0 {
+		return nil, fmt.Errorf("model %s has no columns", t.Name())
+	}
+
+	return m, nil
+}
+
+// addFields adds the fields of t, flattening embedded structs
+func (m *Model) addFields(t reflect.Type, parent []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		index := append(append([]int(nil), parent...), i)
+
+		tag, hasTag := sf.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		// Flatten embedded structs and struct pointers without a column name of their own
+		if sf.Anonymous && !hasTag {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				// Unexported struct pointers cannot be allocated when scanning
+				if !sf.IsExported() {
+					continue
+				}
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				if err := m.addFields(ft, index); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		// Skip unexported fields
+		if !sf.IsExported() {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		f := &Field{
+			Name:   sf.Name,
+			Column: strings.TrimSpace(parts[0]),
+			Index:  index,
+			Type:   sf.Type,
+		}
+		if f.Column == "" {
+			f.Column = ToSnakeCase(sf.Name)
+		}
+
+		for _, option := range parts[1:] {
+			switch strings.TrimSpace(option) {
+			case "pk":
+				f.PrimaryKey = true
+			case "omitempty":
+				f.OmitEmpty = true
+			case "readonly":
+				f.ReadOnly = true
+			case "softdelete":
+				f.SoftDelete = true
+			case "":
+			default:
+				return fmt.Errorf("unknown db tag option %q on field %s", option, sf.Name)
+			}
+		}
+
+		if _, exists := m.byColumn[f.Column]; exists {
+			return fmt.Errorf("duplicate column %s on field %s", f.Column, sf.Name)
+		}
+
+		m.Fields = append(m.Fields, f)
+		m.byColumn[f.Column] = f
+		if f.PrimaryKey {
+			m.PrimaryKeys = append(m.PrimaryKeys, f)
+		}
+		if f.SoftDelete {
+			m.SoftDelete = f
+		}
+	}
+
+	// Fall back to conventions when the tags do not say
+	if parent == nil {
+		if len(m.PrimaryKeys) == 0 {
+			if f, ok := m.byColumn["id"]; ok {
+				f.PrimaryKey = true
+				m.PrimaryKeys = append(m.PrimaryKeys, f)
+			}
+		}
+		if m.SoftDelete == nil {
+			if f, ok := m.byColumn["deleted_at"]; ok {
+				f.SoftDelete = true
+				m.SoftDelete = f
+			}
+		}
+	}
+
+	return nil
+}
+
+// ToSnakeCase converts CamelCase to snake_case by putting an underscore before every upper
+// case letter but the first. Acronyms are split letter by letter (UserID -> user_i_d), as
+// InsertModel always did, so tag such fields with db to name their column.
+func ToSnakeCase(str string) string {
+	var result strings.Builder
+	for i, r := range str {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			result.WriteRune('_')
+		}
+		result.WriteRune(r)
+	}
+	return strings.ToLower(result.String())
+}
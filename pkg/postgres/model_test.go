@@ -0,0 +1,114 @@
+package _postgres
+
+import (
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID        int64      `db:"id,pk"`
+	CreatedAt time.Time  `db:"created_at,readonly"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+type testUser struct {
+	testBase
+	Email    string `db:"email"`
+	Nickname string `db:",omitempty"`
+	Secret   string `db:"-"`
+	internal string
+}
+
+type testAccount struct {
+	UserID string
+	Name   string
+}
+
+func (testAccount) TableName() string {
+	return "billing.accounts"
+}
+
+func TestGetModel(t *testing.T) {
+	m, err := GetModel(&testUser{})
+	if err != nil {
+		t.Fatalf("GetModel() error = %v", err)
+	}
+
+	if m.Table != "test_user" {
+		t.Errorf("GetModel() table = %v, want %v", m.Table, "test_user")
+	}
+
+	wantColumns := []string{"id", "created_at", "deleted_at", "email", "nickname"}
+	columns := m.Columns()
+	if len(columns) != len(wantColumns) {
+		t.Fatalf("GetModel() columns = %v, want %v", columns, wantColumns)
+	}
+	for i := range wantColumns {
+		if columns[i] != wantColumns[i] {
+			t.Errorf("GetModel() columns = %v, want %v", columns, wantColumns)
+			break
+		}
+	}
+
+	if len(m.PrimaryKeys) != 1 || m.PrimaryKeys[0].Column != "id" {
+		t.Errorf("GetModel() primary keys = %v, want [id]", m.PrimaryKeys)
+	}
+	if m.SoftDelete == nil || m.SoftDelete.Column != "deleted_at" {
+		t.Errorf("GetModel() soft delete = %v, want deleted_at", m.SoftDelete)
+	}
+	if f, _ := m.FieldByColumn("created_at"); !f.ReadOnly {
+		t.Errorf("GetModel() created_at readonly = false, want true")
+	}
+	if f, _ := m.FieldByColumn("nickname"); !f.OmitEmpty {
+		t.Errorf("GetModel() nickname omitempty = false, want true")
+	}
+
+	again, _ := GetModel(testUser{})
+	if again != m {
+		t.Errorf("GetModel() did not return the cached model")
+	}
+}
+
+func TestGetModelTableName(t *testing.T) {
+	m, err := GetModel(testAccount{})
+	if err != nil {
+		t.Fatalf("GetModel() error = %v", err)
+	}
+	if m.Table != "billing.accounts" {
+		t.Errorf("GetModel() table = %v, want %v", m.Table, "billing.accounts")
+	}
+	if _, ok := m.FieldByColumn("user_i_d"); !ok {
+		t.Errorf("GetModel() missing column user_i_d")
+	}
+}
+
+func TestGetModelUnknownOption(t *testing.T) {
+	type bad struct {
+		ID int `db:"id,primary"`
+	}
+	if _, err := GetModel(bad{}); err == nil {
+		t.Errorf("GetModel() error = nil, want error")
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "User", want: "user"},
+		{input: "CreatedAt", want: "created_at"},
+		// Names derived before struct tag metadata was cached must not change
+		{input: "UserID", want: "user_i_d"},
+		{input: "HTTPServer", want: "h_t_t_p_server"},
+		{input: "Address2Line", want: "address2_line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ToSnakeCase(tt.input); got != tt.want {
+				t.Errorf("ToSnakeCase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
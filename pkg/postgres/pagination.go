@@ -0,0 +1,468 @@
+package _postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page size limits of PageRequest
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 1000
+)
+
+// ErrInvalidCursor is returned for a cursor that is malformed or was issued for another sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// columnPattern matches a sort column
+var columnPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SortField is a column of a page sort
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// ParseSort parses a sort spec such as "created_at desc, id". When allowed is given, only
+// those columns may be used, which makes the spec safe to take from a request.
+func ParseSort(spec string, allowed ...string) ([]SortField, error) {
+	var sort []SortField
+	for _, part := range strings.Split(spec, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("invalid sort %q", part)
+		}
+
+		field := SortField{Column: fields[0]}
+		if len(fields) == 2 {
+			switch strings.ToUpper(fields[1]) {
+			case "ASC":
+			case "DESC":
+				field.Desc = true
+			default:
+				return nil, fmt.Errorf("invalid sort direction %q", fields[1])
+			}
+		}
+		if len(allowed) > 0 && !slices.Contains(allowed, field.Column) {
+			return nil, fmt.Errorf("sorting by %q is not allowed", field.Column)
+		}
+		sort = append(sort, field)
+	}
+	return sort, nil
+}
+
+// PageQuery is the query a page is read from
+type PageQuery struct {
+	// SQL is the base query, without ORDER BY or LIMIT. It uses ? as the placeholder and its
+	// result must include the sort columns.
+	SQL  string
+	Args []any
+	// Sort orders the rows. Keyset pagination requires the columns to be NOT NULL and the
+	// last one to be unique, typically the primary key.
+	Sort []SortField
+}
+
+// PageRequest selects a page: by Cursor for keyset pagination or by Page for offset pagination
+type PageRequest struct {
+	Limit int `json:"limit"`
+	// Cursor is NextCursor or PrevCursor of a previous page, empty for the first page
+	Cursor string `json:"cursor"`
+	// Page is the 1-based page number of offset pagination
+	Page int `json:"page"`
+}
+
+// limit returns the page size, defaulted and capped
+func (r PageRequest) limit() int {
+	switch {
+	case r.Limit <= 0:
+		return DefaultPageLimit
+	case r.Limit > MaxPageLimit:
+		return MaxPageLimit
+	}
+	return r.Limit
+}
+
+// Page is a page of results
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+
+	// Set by offset pagination only
+	Page       int   `json:"page,omitempty"`
+	Total      int64 `json:"total,omitempty"`
+	TotalPages int   `json:"total_pages,omitempty"`
+}
+
+// Paginate reads a page of T with keyset pagination: the page starts right after the row
+// the cursor was taken from, so its cost does not grow with the depth of the page. bind
+// converts ? placeholders to those of the driver, nil keeps them.
+func Paginate[T any](ctx context.Context, db DatabaseClient, bind func(string) string, q *PageQuery, req PageRequest) (*Page[T], error) {
+	model, err := GetModel(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	if err := q.validate(model); err != nil {
+		return nil, err
+	}
+
+	var c *cursor
+	if req.Cursor != "" {
+		if c, err = decodeCursor(req.Cursor, q.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := req.limit()
+	query, args := q.keysetSQL(c, limit+1)
+	items, err := queryAll[T](ctx, db, bind, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	backward := c != nil && c.Backward
+	if backward {
+		slices.Reverse(items)
+	}
+
+	page := &Page[T]{Items: items}
+	switch {
+	case c == nil:
+		page.HasNext = more
+	case backward:
+		page.HasNext = true
+		page.HasPrev = more
+	default:
+		page.HasNext = more
+		page.HasPrev = true
+	}
+
+	if len(items) == 0 {
+		return page, nil
+	}
+	if page.HasNext {
+		if page.NextCursor, err = encodeCursor(model, q.Sort, items[len(items)-1], false); err != nil {
+			return nil, err
+		}
+	}
+	if page.HasPrev {
+		if page.PrevCursor, err = encodeCursor(model, q.Sort, items[0], true); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// PaginateOffset reads a page of T with LIMIT and OFFSET and counts the total, for admin
+// screens that jump to page numbers. bind converts ? placeholders to those of the driver,
+// nil keeps them.
+func PaginateOffset[T any](ctx context.Context, db DatabaseClient, bind func(string) string, q *PageQuery, req PageRequest) (*Page[T], error) {
+	model, err := GetModel(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	if err := q.validate(model); err != nil {
+		return nil, err
+	}
+
+	pageNumber := max(req.Page, 1)
+	limit := req.limit()
+
+	var total int64
+	countSQL := "SELECT count(*) FROM (" + q.SQL + ") AS page"
+	if err := db.QueryRow(ctx, rebindWith(bind, countSQL), q.Args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count rows: %w", err)
+	}
+
+	query := fmt.Sprintf("SELECT * FROM (%s) AS page ORDER BY %s LIMIT %d OFFSET %d",
+		q.SQL, q.orderBy(false), limit, (pageNumber-1)*limit)
+	items, err := queryAll[T](ctx, db, bind, query, q.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	return &Page[T]{
+		Items:      items,
+		HasNext:    pageNumber < totalPages,
+		HasPrev:    pageNumber > 1,
+		Page:       pageNumber,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// queryAll runs a query and scans every row into T
+func queryAll[T any](ctx context.Context, db DatabaseClient, bind func(string) string, query string, args []any) ([]T, error) {
+	rows, err := db.Query(ctx, rebindWith(bind, query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := ScanAll[T](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan page: %w", err)
+	}
+	return items, nil
+}
+
+// rebindWith applies bind to query, if any
+func rebindWith(bind func(string) string, query string) string {
+	if bind == nil {
+		return query
+	}
+	return bind(query)
+}
+
+// validate checks the sort against the model
+func (q *PageQuery) validate(model *Model) error {
+	if strings.TrimSpace(q.SQL) == "" {
+		return fmt.Errorf("page query SQL is required")
+	}
+	if len(q.Sort) == 0 {
+		return fmt.Errorf("page query sort is required")
+	}
+	for _, s := range q.Sort {
+		if !columnPattern.MatchString(s.Column) {
+			return fmt.Errorf("invalid sort column %q", s.Column)
+		}
+		if _, ok := model.FieldByColumn(s.Column); !ok {
+			return fmt.Errorf("sort column %q is not a field of %s", s.Column, model.Type)
+		}
+	}
+	return nil
+}
+
+// orderBy renders the ORDER BY list, reversed when reading backward
+func (q *PageQuery) orderBy(backward bool) string {
+	orders := make([]string, len(q.Sort))
+	for i, s := range q.Sort {
+		if s.Desc != backward {
+			orders[i] = s.Column + " DESC"
+		} else {
+			orders[i] = s.Column + " ASC"
+		}
+	}
+	return strings.Join(orders, ", ")
+}
+
+// keysetSQL renders the query of a keyset page of limit rows, starting after the cursor
+func (q *PageQuery) keysetSQL(c *cursor, limit int) (string, []any) {
+	var sb strings.Builder
+	args := slices.Clone(q.Args)
+	backward := c != nil && c.Backward
+
+	sb.WriteString("SELECT * FROM (")
+	sb.WriteString(q.SQL)
+	sb.WriteString(") AS page")
+
+	if c != nil {
+		where, whereArgs := q.keysetCondition(c.values, backward)
+		sb.WriteString(" WHERE ")
+		sb.WriteString(where)
+		args = append(args, whereArgs...)
+	}
+
+	sb.WriteString(" ORDER BY ")
+	sb.WriteString(q.orderBy(backward))
+	sb.WriteString(" LIMIT ")
+	sb.WriteString(strconv.Itoa(limit))
+
+	return sb.String(), args
+}
+
+// keysetCondition matches the rows after values in the sort order, or before them when
+// reading backward. A sort in a single direction uses a row comparison, which an index on
+// the sort columns serves directly.
+func (q *PageQuery) keysetCondition(values []any, backward bool) (string, []any) {
+	op := func(desc bool) string {
+		if desc != backward {
+			return "<"
+		}
+		return ">"
+	}
+
+	sameDirection := true
+	for _, s := range q.Sort {
+		sameDirection = sameDirection && s.Desc == q.Sort[0].Desc
+	}
+
+	if sameDirection {
+		columns := make([]string, len(q.Sort))
+		placeholders := make([]string, len(q.Sort))
+		for i, s := range q.Sort {
+			columns[i] = s.Column
+			placeholders[i] = "?"
+		}
+		return fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(columns, ", "), op(q.Sort[0].Desc), strings.Join(placeholders, ", "),
+		), values
+	}
+
+	// (a > ?) OR (a = ? AND b < ?) OR ...
+	var ors []string
+	var args []any
+	for i, s := range q.Sort {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, q.Sort[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		ands = append(ands, s.Column+" "+op(s.Desc)+" ?")
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// cursor is the decoded position of a keyset page
+type cursor struct {
+	Backward bool
+	values   []any
+}
+
+// cursorData is the JSON form of a cursor
+type cursorData struct {
+	Backward bool          `json:"b,omitempty"`
+	Sort     string        `json:"s"`
+	Values   []cursorValue `json:"v"`
+}
+
+// cursorValue keeps the type of a value, which JSON alone would lose
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+// sortSignature identifies a sort so cursors are not reused across sorts
+func sortSignature(sort []SortField) string {
+	parts := make([]string, len(sort))
+	for i, s := range sort {
+		parts[i] = s.Column
+		if s.Desc {
+			parts[i] += " desc"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// encodeCursor returns the opaque cursor of item for the sort
+func encodeCursor[T any](model *Model, sort []SortField, item T, backward bool) (string, error) {
+	v := reflect.ValueOf(item)
+	data := cursorData{Backward: backward, Sort: sortSignature(sort)}
+
+	for _, s := range sort {
+		f, _ := model.FieldByColumn(s.Column)
+		cv, err := newCursorValue(f.Value(v).Interface())
+		if err != nil {
+			return "", fmt.Errorf("failed to encode cursor column %s: %w", s.Column, err)
+		}
+		data.Values = append(data.Values, cv)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor parses a cursor issued for the sort
+func decodeCursor(s string, sort []SortField) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var data cursorData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if data.Sort != sortSignature(sort) || len(data.Values) != len(sort) {
+		return nil, fmt.Errorf("%w: it was issued for another sort", ErrInvalidCursor)
+	}
+
+	c := &cursor{Backward: data.Backward, values: make([]any, len(data.Values))}
+	for i, cv := range data.Values {
+		if c.values[i], err = cv.value(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+	}
+	return c, nil
+}
+
+// newCursorValue encodes a column value
+func newCursorValue(v any) (cursorValue, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr {
+		return cursorValue{}, errors.New("sort columns cannot be NULL")
+	}
+
+	if valuer, ok := rv.Interface().(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil {
+			return cursorValue{}, err
+		}
+		if dv == nil {
+			return cursorValue{}, errors.New("sort columns cannot be NULL")
+		}
+		rv = reflect.ValueOf(dv)
+	}
+
+	if t, ok := rv.Interface().(time.Time); ok {
+		return cursorValue{Type: "t", Value: t.Format(time.RFC3339Nano)}, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return cursorValue{Type: "s", Value: rv.String()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: "i", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: "u", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return cursorValue{Type: "f", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case reflect.Bool:
+		return cursorValue{Type: "b", Value: strconv.FormatBool(rv.Bool())}, nil
+	}
+	return cursorValue{}, fmt.Errorf("unsupported type %s", rv.Type())
+}
+
+// value decodes a column value
+func (cv cursorValue) value() (any, error) {
+	switch cv.Type {
+	case "s":
+		return cv.Value, nil
+	case "i":
+		return strconv.ParseInt(cv.Value, 10, 64)
+	case "u":
+		return strconv.ParseUint(cv.Value, 10, 64)
+	case "f":
+		return strconv.ParseFloat(cv.Value, 64)
+	case "b":
+		return strconv.ParseBool(cv.Value)
+	case "t":
+		return time.Parse(time.RFC3339Nano, cv.Value)
+	}
+	return nil, fmt.Errorf("unknown value type %q", cv.Type)
+}
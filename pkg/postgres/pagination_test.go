@@ -0,0 +1,219 @@
+package _postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type pageUser struct {
+	ID        int64     `db:"id,pk"`
+	CreatedAt time.Time `db:"created_at"`
+	Name      string    `db:"name"`
+}
+
+// pageDB records the last query and serves fixed rows and a count
+type pageDB struct {
+	*fakeReplica
+	query string
+	args  []any
+	rows  [][]any
+	total int64
+}
+
+func (db *pageDB) Query(ctx context.Context, query string, args ...any) (Rows, error) {
+	db.query, db.args = query, args
+	return &fakeRows{columns: []string{"id", "created_at", "name"}, rows: db.rows}, nil
+}
+
+func (db *pageDB) QueryRow(ctx context.Context, query string, args ...any) Row {
+	return countRow{count: db.total}
+}
+
+// countRow returns a count
+type countRow struct {
+	count int64
+}
+
+func (r countRow) Scan(dest ...any) error {
+	reflect.ValueOf(dest[0]).Elem().SetInt(r.count)
+	return nil
+}
+
+func userRows(base time.Time, ids ...int64) [][]any {
+	rows := make([][]any, len(ids))
+	for i, id := range ids {
+		rows[i] = []any{id, base.Add(-time.Duration(id) * time.Minute), "user"}
+	}
+	return rows
+}
+
+func TestPaginate(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	q := &PageQuery{
+		SQL:  "SELECT id, created_at, name FROM users WHERE status = ?",
+		Args: []any{"active"},
+		Sort: []SortField{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+	}
+	db := &pageDB{}
+
+	// First page
+	db.rows = userRows(base, 1, 2, 3)
+	first, err := Paginate[pageUser](ctx, db, nil, q, PageRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+	wantSQL := "SELECT * FROM (SELECT id, created_at, name FROM users WHERE status = ?) AS page ORDER BY created_at DESC, id DESC LIMIT 3"
+	if db.query != wantSQL {
+		t.Errorf("Paginate() query = %q, want %q", db.query, wantSQL)
+	}
+	if len(first.Items) != 2 || !first.HasNext || first.HasPrev || first.NextCursor == "" || first.PrevCursor != "" {
+		t.Errorf("Paginate() first page = %+v, want 2 items with a next cursor only", first)
+	}
+
+	// Next page starts after the last item
+	db.rows = userRows(base, 3, 4)
+	second, err := Paginate[pageUser](ctx, db, nil, q, PageRequest{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+	wantSQL = "SELECT * FROM (SELECT id, created_at, name FROM users WHERE status = ?) AS page WHERE (created_at, id) < (?, ?) ORDER BY created_at DESC, id DESC LIMIT 3"
+	if db.query != wantSQL {
+		t.Errorf("Paginate() query = %q, want %q", db.query, wantSQL)
+	}
+	wantArgs := []any{"active", base.Add(-2 * time.Minute), int64(2)}
+	if !reflect.DeepEqual(db.args, wantArgs) {
+		t.Errorf("Paginate() args = %v, want %v", db.args, wantArgs)
+	}
+	if len(second.Items) != 2 || second.HasNext || !second.HasPrev || second.PrevCursor == "" {
+		t.Errorf("Paginate() second page = %+v, want 2 items with a previous cursor only", second)
+	}
+
+	// Previous page reads backward and restores the order
+	db.rows = userRows(base, 2, 1)
+	prev, err := Paginate[pageUser](ctx, db, nil, q, PageRequest{Limit: 2, Cursor: second.PrevCursor})
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+	wantSQL = "SELECT * FROM (SELECT id, created_at, name FROM users WHERE status = ?) AS page WHERE (created_at, id) > (?, ?) ORDER BY created_at ASC, id ASC LIMIT 3"
+	if db.query != wantSQL {
+		t.Errorf("Paginate() query = %q, want %q", db.query, wantSQL)
+	}
+	if len(prev.Items) != 2 || prev.Items[0].ID != 1 || !prev.HasNext || prev.HasPrev {
+		t.Errorf("Paginate() previous page = %+v, want items 1 and 2 with a next cursor only", prev)
+	}
+}
+
+func TestPaginateMixedDirections(t *testing.T) {
+	q := &PageQuery{
+		SQL:  "SELECT * FROM users",
+		Sort: []SortField{{Column: "name"}, {Column: "created_at", Desc: true}, {Column: "id"}},
+	}
+
+	where, args := q.keysetCondition([]any{"bob", "t", int64(7)}, false)
+	want := "((name > ?) OR (name = ? AND created_at < ?) OR (name = ? AND created_at = ? AND id > ?))"
+	if where != want {
+		t.Errorf("keysetCondition() = %q, want %q", where, want)
+	}
+	if wantArgs := []any{"bob", "bob", "t", "bob", "t", int64(7)}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("keysetCondition() args = %v, want %v", args, wantArgs)
+	}
+
+	where, _ = q.keysetCondition([]any{"bob", "t", int64(7)}, true)
+	want = "((name < ?) OR (name = ? AND created_at > ?) OR (name = ? AND created_at = ? AND id < ?))"
+	if where != want {
+		t.Errorf("keysetCondition() backward = %q, want %q", where, want)
+	}
+}
+
+func TestPaginateInvalidCursor(t *testing.T) {
+	ctx := context.Background()
+	db := &pageDB{rows: userRows(time.Now(), 1, 2, 3)}
+	q := &PageQuery{SQL: "SELECT * FROM users", Sort: []SortField{{Column: "id"}}}
+
+	page, err := Paginate[pageUser](ctx, db, nil, q, PageRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+
+	other := &PageQuery{SQL: q.SQL, Sort: []SortField{{Column: "id", Desc: true}}}
+	if _, err := Paginate[pageUser](ctx, db, nil, other, PageRequest{Cursor: page.NextCursor}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Paginate() with a cursor of another sort error = %v, want ErrInvalidCursor", err)
+	}
+	if _, err := Paginate[pageUser](ctx, db, nil, q, PageRequest{Cursor: "not a cursor"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Paginate() with a malformed cursor error = %v, want ErrInvalidCursor", err)
+	}
+
+	unknown := &PageQuery{SQL: q.SQL, Sort: []SortField{{Column: "email"}}}
+	if _, err := Paginate[pageUser](ctx, db, nil, unknown, PageRequest{}); err == nil {
+		t.Errorf("Paginate() by an unknown column error = nil, want error")
+	}
+}
+
+func TestPaginateOffset(t *testing.T) {
+	db := &pageDB{rows: userRows(time.Now(), 3, 4), total: 5}
+	q := &PageQuery{SQL: "SELECT * FROM users", Sort: []SortField{{Column: "id"}}}
+
+	page, err := PaginateOffset[pageUser](context.Background(), db, nil, q, PageRequest{Limit: 2, Page: 2})
+	if err != nil {
+		t.Fatalf("PaginateOffset() error = %v", err)
+	}
+	if want := "SELECT * FROM (SELECT * FROM users) AS page ORDER BY id ASC LIMIT 2 OFFSET 2"; db.query != want {
+		t.Errorf("PaginateOffset() query = %q, want %q", db.query, want)
+	}
+	if page.Total != 5 || page.TotalPages != 3 || page.Page != 2 || !page.HasNext || !page.HasPrev {
+		t.Errorf("PaginateOffset() = %+v, want page 2 of 3 with 5 rows", page)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	sort, err := ParseSort("created_at desc, id", "created_at", "id")
+	want := []SortField{{Column: "created_at", Desc: true}, {Column: "id"}}
+	if err != nil || !reflect.DeepEqual(sort, want) {
+		t.Errorf("ParseSort() = %v, %v, want %v", sort, err, want)
+	}
+
+	for _, spec := range []string{"password", "id sideways", "id desc extra", ""} {
+		if _, err := ParseSort(spec, "created_at", "id"); err == nil {
+			t.Errorf("ParseSort(%q) error = nil, want error", spec)
+		}
+	}
+}
+
+func TestCursorValues(t *testing.T) {
+	name := "bob"
+	now := time.Date(2024, 5, 1, 12, 0, 0, 123, time.UTC)
+
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{int32(7), int64(7)},
+		{uint64(9), uint64(9)},
+		{1.5, 1.5},
+		{"bob", "bob"},
+		{&name, "bob"},
+		{true, true},
+		{now, now},
+	}
+
+	for _, tt := range tests {
+		cv, err := newCursorValue(tt.in)
+		if err != nil {
+			t.Errorf("newCursorValue(%v) error = %v", tt.in, err)
+			continue
+		}
+		got, err := cv.value()
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("cursor value of %v = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	var nilName *string
+	if _, err := newCursorValue(nilName); err == nil {
+		t.Errorf("newCursorValue(nil) error = nil, want error")
+	}
+}
@@ -13,19 +13,57 @@ import (
 
 // BeginTx starts a new transaction
 func (c *Connection) BeginTx(ctx context.Context) (_postgres.Transaction, error) {
+	return c.BeginTxWithOptions(ctx, _postgres.TxOptions{})
+}
+
+// BeginTxWithOptions starts a new transaction with the given isolation level and access mode
+func (c *Connection) BeginTxWithOptions(ctx context.Context, opts _postgres.TxOptions) (_postgres.Transaction, error) {
 	if c.pool == nil {
 		return nil, fmt.Errorf("database not connected")
 	}
 
-	queryCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
+	txOptions := pgx.TxOptions{IsoLevel: pgx.TxIsoLevel(opts.Isolation)}
+	if opts.ReadOnly {
+		txOptions.AccessMode = pgx.ReadOnly
+	}
+
+	// The timeout only bounds BEGIN, the transaction itself lives as long as ctx
+	beginCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
 	defer cancel()
 
-	tx, err := c.pool.Begin(queryCtx)
+	tx, err := c.pool.BeginTx(beginCtx, txOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	return &Transaction{tx: tx, ctx: queryCtx}, nil
+	return &Transaction{tx: tx, ctx: ctx, conn: c}, nil
+}
+
+// WithTx runs fn in a transaction, see _postgres.RunTx. Exec, Query, QueryRow and
+// repositories on this connection called with the context passed to fn join the transaction.
+func (c *Connection) WithTx(ctx context.Context, opts _postgres.TxOptions, fn _postgres.TxFunc) error {
+	return _postgres.RunTx(ctx, c, opts, fn)
+}
+
+// txFromContext returns the transaction of this connection carried by ctx
+func (c *Connection) txFromContext(ctx context.Context) (*Transaction, bool) {
+	tx, ok := _postgres.TxFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	t, ok := tx.(*Transaction)
+	if !ok || t.conn != c {
+		return nil, false
+	}
+	return t, true
+}
+
+// querier returns the transaction carried by ctx, or the pool
+func (c *Connection) querier(ctx context.Context) Querier {
+	if tx, ok := c.txFromContext(ctx); ok {
+		return tx.tx
+	}
+	return c.pool
 }
 
 // Exec executes a query
@@ -33,6 +71,9 @@ func (c *Connection) Exec(ctx context.Context, query string, args ...any) error
 	if c.pool == nil {
 		return fmt.Errorf("database not connected")
 	}
+	if tx, ok := c.txFromContext(ctx); ok {
+		return tx.Exec(ctx, query, args...)
+	}
 
 	queryCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
 	defer cancel()
@@ -41,34 +82,40 @@ func (c *Connection) Exec(ctx context.Context, query string, args ...any) error
 	return err
 }
 
-// Query executes a query and returns rows
+// Query executes a query and returns rows. The query timeout covers reading the rows,
+// which must be closed.
 func (c *Connection) Query(ctx context.Context, query string, args ...any) (_postgres.Rows, error) {
 	if c.pool == nil {
 		return nil, fmt.Errorf("database not connected")
 	}
+	if tx, ok := c.txFromContext(ctx); ok {
+		return tx.Query(ctx, query, args...)
+	}
 
 	queryCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
-	defer cancel()
 
 	rows, err := c.pool.Query(queryCtx, query, args...)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	return &RowsWrapper{rows: rows}, nil
+	return &RowsWrapper{rows: rows, cancel: cancel}, nil
 }
 
 // QueryRow executes a query and returns a single row
 func (c *Connection) QueryRow(ctx context.Context, query string, args ...any) _postgres.Row {
 	if c.pool == nil {
-		return &RowWrapper{row: nil}
+		return &RowWrapper{err: fmt.Errorf("database not connected")}
+	}
+	if tx, ok := c.txFromContext(ctx); ok {
+		return tx.QueryRow(ctx, query, args...)
 	}
 
 	queryCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
-	defer cancel()
 
 	row := c.pool.QueryRow(queryCtx, query, args...)
-	return &RowWrapper{row: row}
+	return &RowWrapper{row: row, cancel: cancel}
 }
 
 // InsertModel inserts a model into the database
@@ -85,7 +132,7 @@ func (c *Connection) InsertModel(ctx context.Context, model any) error {
 	queryCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
 	defer cancel()
 
-	_, err = c.pool.Exec(queryCtx, query, args...)
+	_, err = c.querier(ctx).Exec(queryCtx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to insert model: %w", err)
 	}
@@ -107,7 +154,7 @@ func (c *Connection) UpsertModel(ctx context.Context, model any, primaryKeys ...
 	queryCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
 	defer cancel()
 
-	_, err = c.pool.Exec(queryCtx, query, args...)
+	_, err = c.querier(ctx).Exec(queryCtx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to upsert model: %w", err)
 	}
@@ -165,7 +212,12 @@ func (c *Connection) executeBatch(ctx context.Context, models []any) error {
 		batch.Queue(query, args...)
 	}
 
-	results := c.pool.SendBatch(queryCtx, batch)
+	var results pgx.BatchResults
+	if tx, ok := c.txFromContext(ctx); ok {
+		results = tx.tx.SendBatch(queryCtx, batch)
+	} else {
+		results = c.pool.SendBatch(queryCtx, batch)
+	}
 	defer results.Close()
 
 	// Process all results
@@ -247,7 +299,7 @@ func (c *Connection) buildBatchInsertQuery(models []any) (string, error) {
 		return "", fmt.Errorf("no models provided")
 	}
 
-	tableName, columns, _, err := c.analyzeModel(models[0])
+	tableName, columns, _, err := analyzeModel(models[0], false)
 	if err != nil {
 		return "", err
 	}
@@ -266,60 +318,38 @@ func (c *Connection) buildBatchInsertQuery(models []any) (string, error) {
 }
 
 func (c *Connection) analyzeModel(model any) (tableName string, columns []string, values []any, err error) {
+	return analyzeModel(model, true)
+}
+
+func (c *Connection) extractModelValues(model any) ([]any, error) {
+	// Batches share one statement, so every row must provide the same columns
+	_, _, values, err := analyzeModel(model, false)
+	return values, err
+}
+
+// analyzeModel returns the table, the writable columns and their values using the cached
+// model metadata. Zero valued omitempty columns are left out when skipEmpty is set.
+func analyzeModel(model any, skipEmpty bool) (tableName string, columns []string, values []any, err error) {
 	v := reflect.ValueOf(model)
 	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil, nil, fmt.Errorf("model must not be a nil pointer")
+		}
 		v = v.Elem()
 	}
 
-	if v.Kind() != reflect.Struct {
-		return "", nil, nil, fmt.Errorf("model must be a struct")
+	m, err := _postgres.GetModel(model)
+	if err != nil {
+		return "", nil, nil, err
 	}
 
-	t := v.Type()
-
-	// Get table name from struct name (convert to snake_case)
-	tableName = toSnakeCase(t.Name())
-
-	for i := 0; i < v.NumField(); i++ {
-		field := t.Field(i)
-		fieldValue := v.Field(i)
-
-		// Skip unexported fields
-		if !fieldValue.CanInterface() {
-			continue
-		}
-
-		// Get column name from tag or field name
-		columnName := field.Tag.Get("db")
-		if columnName == "" {
-			columnName = toSnakeCase(field.Name)
-		}
-
-		// Skip fields marked with "-"
-		if columnName == "-" {
+	for _, f := range m.Fields {
+		if f.ReadOnly || (skipEmpty && f.OmitEmpty && f.Value(v).IsZero()) {
 			continue
 		}
-
-		columns = append(columns, columnName)
-		values = append(values, fieldValue.Interface())
+		columns = append(columns, f.Column)
+		values = append(values, f.Value(v).Interface())
 	}
 
-	return tableName, columns, values, nil
-}
-
-func (c *Connection) extractModelValues(model any) ([]any, error) {
-	_, _, values, err := c.analyzeModel(model)
-	return values, err
-}
-
-// toSnakeCase converts CamelCase to snake_case
-func toSnakeCase(str string) string {
-	var result strings.Builder
-	for i, r := range str {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			result.WriteRune('_')
-		}
-		result.WriteRune(r)
-	}
-	return strings.ToLower(result.String())
+	return m.Table, columns, values, nil
 }
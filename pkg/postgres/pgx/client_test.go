@@ -0,0 +1,51 @@
+package _pgx_postgres
+
+import (
+	"reflect"
+	"testing"
+)
+
+type legacyOrder struct {
+	OrderID    int
+	CustomerID string
+	TotalPrice float64
+	Note       string `db:"comment"`
+}
+
+func TestBuildInsertQuery(t *testing.T) {
+	c := &Connection{}
+
+	query, args, err := c.buildInsertQuery(&legacyOrder{OrderID: 1, CustomerID: "c1", TotalPrice: 9.5, Note: "gift"})
+	if err != nil {
+		t.Fatalf("buildInsertQuery() error = %v", err)
+	}
+
+	// Derived names must match what InsertModel has always generated
+	want := "INSERT INTO legacy_order (order_i_d, customer_i_d, total_price, comment) VALUES ($1, $2, $3, $4)"
+	if query != want {
+		t.Errorf("buildInsertQuery() query = %v, want %v", query, want)
+	}
+	if wantArgs := []any{1, "c1", 9.5, "gift"}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("buildInsertQuery() args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestBuildInsertQueryNilModel(t *testing.T) {
+	c := &Connection{}
+
+	tests := []struct {
+		name  string
+		model any
+	}{
+		{name: "nil", model: nil},
+		{name: "nil pointer", model: (*legacyOrder)(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := c.buildInsertQuery(tt.model); err == nil {
+				t.Errorf("buildInsertQuery() error = nil, want error")
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ type Connection struct {
 	pool   *pgxpool.Pool
 	conn   *pgx.Conn
 	config *_postgres.Config
+	tracer pgx.QueryTracer
 }
 
 // NewConnection creates a new pgx connection
@@ -79,6 +80,7 @@ func (c *Connection) connectWithTimeout(ctx context.Context) (*pgxpool.Pool, *pg
 	poolConfig.MinConns = int32(c.config.MaxIdleConns)
 	poolConfig.MaxConnLifetime = c.config.ConnMaxLifetime
 	poolConfig.MaxConnIdleTime = c.config.ConnMaxIdleTime
+	poolConfig.ConnConfig.Tracer = c.tracer
 
 	pool, err := pgxpool.NewWithConfig(connectCtx, poolConfig)
 	if err != nil {
@@ -92,7 +94,14 @@ func (c *Connection) connectWithTimeout(ctx context.Context) (*pgxpool.Pool, *pg
 	}
 
 	// Create direct connection
-	conn, err := pgx.Connect(connectCtx, c.config.DSN())
+	connConfig, err := pgx.ParseConfig(c.config.DSN())
+	if err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("failed to parse connection config: %w", err)
+	}
+	connConfig.Tracer = c.tracer
+
+	conn, err := pgx.ConnectConfig(connectCtx, connConfig)
 	if err != nil {
 		pool.Close()
 		return nil, nil, fmt.Errorf("failed to create direct connection: %w", err)
@@ -153,10 +162,10 @@ func (c *Connection) Stats() _postgres.ConnectionStats {
 		OpenConnections:   int(stats.TotalConns()),
 		InUseConnections:  int(stats.AcquiredConns()),
 		IdleConnections:   int(stats.IdleConns()),
-		WaitCount:         0, // pgx doesn't provide this directly
-		WaitDuration:      0, // pgx doesn't provide this directly
-		MaxIdleClosed:     0, // pgx doesn't provide this directly
-		MaxIdleTimeClosed: 0, // pgx doesn't provide this directly
-		MaxLifetimeClosed: 0, // pgx doesn't provide this directly
+		WaitCount:         stats.EmptyAcquireCount(), // acquires that waited for a connection
+		WaitDuration:      stats.AcquireDuration(),   // includes acquires that did not wait
+		MaxIdleClosed:     0,                         // pgx doesn't provide this directly
+		MaxIdleTimeClosed: stats.MaxIdleDestroyCount(),
+		MaxLifetimeClosed: stats.MaxLifetimeDestroyCount(),
 	}
 }
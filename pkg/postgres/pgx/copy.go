@@ -0,0 +1,381 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	_postgres "go-libs/pkg/postgres"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CopyModels bulk loads the models produced by source with COPY, which is much faster
+// than INSERT for large imports. Rows are sent in batches of opts.BatchSize, each
+// committed on its own, so a failure only loses its batch. All models must be of the
+// same type. Empty omitempty fields are left out of each row like InsertModel does, so
+// rows whose omitempty fields are set differently are copied separately.
+//
+// With a conflict action other than ConflictError each batch is copied into a temporary
+// table and moved with INSERT ... ON CONFLICT. A batch must not contain the same key
+// twice when updating.
+//
+// Inside WithTx every batch runs in a savepoint of the transaction instead, and nothing
+// is committed before the transaction is.
+//
+// The copy stops at the first failed batch unless opts.ContinueOnError is set. The
+// result is returned in both cases and lists every failed batch.
+func (c *Connection) CopyModels(ctx context.Context, source iter.Seq[any], opts _postgres.CopyOptions) (*_postgres.CopyResult, error) {
+	if c.pool == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid copy options: %w", err)
+	}
+
+	timeout := opts.BatchTimeout
+	if timeout <= 0 {
+		timeout = c.config.QueryTimeout
+	}
+
+	next, stop := iter.Pull(source)
+	defer stop()
+
+	result := &_postgres.CopyResult{}
+	var plan *copyPlan
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		rows := make([]copyRow, 0, opts.BatchSize)
+		for len(rows) < opts.BatchSize {
+			model, ok := next()
+			if !ok {
+				break
+			}
+
+			if plan == nil {
+				var err error
+				if plan, err = newCopyPlan(model, opts); err != nil {
+					return result, err
+				}
+			}
+
+			row, err := plan.row(model)
+			if err != nil {
+				return result, fmt.Errorf("invalid row %d: %w", result.Read, err)
+			}
+			rows = append(rows, row)
+			result.Read++
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		written, err := c.copyBatch(ctx, plan, rows, timeout)
+		if err != nil {
+			batchErr := &_postgres.BatchError{
+				Batch:    result.Batches,
+				FirstRow: result.Read - int64(len(rows)),
+				Rows:     len(rows),
+				Err:      err,
+			}
+			result.Errors = append(result.Errors, batchErr)
+			result.Failed += int64(len(rows))
+		} else {
+			result.Written += written
+		}
+		result.Batches++
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(result.CopyProgress)
+		}
+		if err != nil && !opts.ContinueOnError {
+			return result, result.Errors[len(result.Errors)-1]
+		}
+		if len(rows) < opts.BatchSize {
+			break
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		errs := make([]error, len(result.Errors))
+		for i, e := range result.Errors {
+			errs[i] = e
+		}
+		return result, fmt.Errorf("%d of %d batches failed: %w", len(result.Errors), result.Batches, errors.Join(errs...))
+	}
+
+	return result, nil
+}
+
+// copier is implemented by *pgxpool.Pool and pgx.Tx, where Begin creates a savepoint
+type copier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// copyGroup is the rows of a batch that share the same columns
+type copyGroup struct {
+	columns *copyColumns
+	rows    [][]any
+}
+
+// groupRows splits rows by column set, in the order the sets first appear
+func groupRows(rows []copyRow) []*copyGroup {
+	var groups []*copyGroup
+	index := make(map[*copyColumns]*copyGroup)
+	for _, row := range rows {
+		g, ok := index[row.columns]
+		if !ok {
+			g = &copyGroup{columns: row.columns}
+			index[row.columns] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, row.values)
+	}
+	return groups
+}
+
+// copyBatch writes one batch and returns the number of rows inserted or updated. Inside
+// WithTx the batch runs in a savepoint of the transaction.
+func (c *Connection) copyBatch(ctx context.Context, plan *copyPlan, rows []copyRow, timeout time.Duration) (int64, error) {
+	batchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var db copier = c.pool
+	tx, inTx := c.txFromContext(ctx)
+	if inTx {
+		db = tx.tx
+	}
+
+	groups := groupRows(rows)
+
+	// A single COPY is atomic on its own
+	if plan.tempTable == "" && !inTx && len(groups) == 1 {
+		return db.CopyFrom(batchCtx, plan.table, groups[0].columns.columns, pgx.CopyFromRows(groups[0].rows))
+	}
+
+	batchTx, err := db.Begin(batchCtx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer batchTx.Rollback(context.WithoutCancel(batchCtx))
+
+	var written int64
+	if plan.tempTable == "" {
+		for _, g := range groups {
+			n, err := batchTx.CopyFrom(batchCtx, plan.table, g.columns.columns, pgx.CopyFromRows(g.rows))
+			if err != nil {
+				return 0, err
+			}
+			written += n
+		}
+		if err := batchTx.Commit(batchCtx); err != nil {
+			return 0, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return written, nil
+	}
+
+	if _, err := batchTx.Exec(batchCtx, plan.createTemp); err != nil {
+		return 0, fmt.Errorf("failed to create temporary table: %w", err)
+	}
+	for i, g := range groups {
+		if _, err := batchTx.CopyFrom(batchCtx, pgx.Identifier{plan.tempTable}, g.columns.columns, pgx.CopyFromRows(g.rows)); err != nil {
+			return 0, fmt.Errorf("failed to copy rows: %w", err)
+		}
+		tag, err := batchTx.Exec(batchCtx, g.columns.insert)
+		if err != nil {
+			return 0, fmt.Errorf("failed to move rows: %w", err)
+		}
+		written += tag.RowsAffected()
+		// Each group moves only its own columns, so the next one starts from an empty table
+		if i < len(groups)-1 {
+			if _, err := batchTx.Exec(batchCtx, "TRUNCATE "+plan.tempTable); err != nil {
+				return 0, fmt.Errorf("failed to truncate temporary table: %w", err)
+			}
+		}
+	}
+	// ON COMMIT DROP does not apply when the batch is a savepoint
+	if _, err := batchTx.Exec(batchCtx, "DROP TABLE "+plan.tempTable); err != nil {
+		return 0, fmt.Errorf("failed to drop temporary table: %w", err)
+	}
+	if err := batchTx.Commit(batchCtx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return written, nil
+}
+
+// copyPlan holds the statements and column mapping of a copy, built from the model type
+type copyPlan struct {
+	model  *_postgres.Model
+	table  pgx.Identifier
+	fields []*_postgres.Field
+	// sets caches the column sets seen so far by their column list
+	sets map[string]*copyColumns
+
+	// Conflict handling, empty for ConflictError
+	tempTable       string
+	createTemp      string
+	conflictColumns []string
+	updateColumns   []string
+	update          bool
+}
+
+// copyColumns is a set of copied columns, shared by the rows whose omitempty fields are
+// set alike
+type copyColumns struct {
+	columns []string
+	// insert moves the rows from the temporary table, empty for ConflictError
+	insert string
+}
+
+// copyRow is the values of one model and the columns they are copied to
+type copyRow struct {
+	columns *copyColumns
+	values  []any
+}
+
+// newCopyPlan builds the plan for models shaped like the given one
+func newCopyPlan(model any, opts _postgres.CopyOptions) (*copyPlan, error) {
+	m, err := _postgres.GetModel(model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+
+	p := &copyPlan{
+		model: m,
+		table: pgx.Identifier(strings.Split(m.Table, ".")),
+		sets:  make(map[string]*copyColumns),
+	}
+	var columns []string
+	for _, f := range m.Fields {
+		if f.ReadOnly {
+			continue
+		}
+		p.fields = append(p.fields, f)
+		columns = append(columns, f.Column)
+	}
+	if len(p.fields) == 0 {
+		return nil, fmt.Errorf("model %s has no columns to copy", m.Table)
+	}
+
+	if opts.OnConflict == _postgres.ConflictError {
+		return p, nil
+	}
+
+	p.conflictColumns = opts.ConflictColumns
+	if len(p.conflictColumns) == 0 {
+		for _, f := range m.PrimaryKeys {
+			p.conflictColumns = append(p.conflictColumns, f.Column)
+		}
+	}
+	if len(p.conflictColumns) == 0 {
+		return nil, fmt.Errorf("model %s has no primary key, conflict columns are required", m.Table)
+	}
+	for _, column := range p.conflictColumns {
+		if _, ok := m.FieldByColumn(column); !ok {
+			return nil, fmt.Errorf("unknown conflict column %q", column)
+		}
+	}
+
+	p.update = opts.OnConflict == _postgres.ConflictUpdate
+	if p.update {
+		p.updateColumns = opts.UpdateColumns
+		for _, column := range p.updateColumns {
+			if !slices.Contains(columns, column) {
+				return nil, fmt.Errorf("update column %q is not copied", column)
+			}
+		}
+	}
+
+	p.tempTable = "copy_" + strings.NewReplacer(".", "_", `"`, "").Replace(m.Table)
+	p.createTemp = fmt.Sprintf("CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA",
+		p.tempTable,
+		strings.Join(columns, ", "),
+		m.Table)
+
+	return p, nil
+}
+
+// columnSet returns the plan's column set for the given columns
+func (p *copyPlan) columnSet(columns []string) *copyColumns {
+	key := strings.Join(columns, ", ")
+	if s, ok := p.sets[key]; ok {
+		return s
+	}
+
+	s := &copyColumns{columns: columns}
+	p.sets[key] = s
+	if p.tempTable == "" {
+		return s
+	}
+
+	// Empty omitempty columns keep their current value on update
+	var updates []string
+	if p.update {
+		for _, column := range columns {
+			if len(p.updateColumns) > 0 && !slices.Contains(p.updateColumns, column) {
+				continue
+			}
+			if len(p.updateColumns) == 0 && slices.Contains(p.conflictColumns, column) {
+				continue
+			}
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		}
+	}
+
+	action := "DO NOTHING"
+	if len(updates) > 0 {
+		action = "DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+
+	s.insert = fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT (%s) %s",
+		p.model.Table,
+		key,
+		key,
+		p.tempTable,
+		strings.Join(p.conflictColumns, ", "),
+		action)
+
+	return s
+}
+
+// row returns the copied columns and values of a model, which must have the plan's type
+func (p *copyPlan) row(model any) (copyRow, error) {
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return copyRow{}, fmt.Errorf("model must not be a nil pointer")
+	}
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return copyRow{}, fmt.Errorf("model must not be nil")
+	}
+	if v.Type() != p.model.Type {
+		return copyRow{}, fmt.Errorf("expected %s, got %s", p.model.Type, v.Type())
+	}
+
+	var columns []string
+	var values []any
+	for _, f := range p.fields {
+		fv := f.Value(v)
+		if f.OmitEmpty && fv.IsZero() {
+			continue
+		}
+		columns = append(columns, f.Column)
+		values = append(values, fv.Interface())
+	}
+	if len(columns) == 0 {
+		return copyRow{}, fmt.Errorf("model %s has no columns to copy", p.model.Table)
+	}
+
+	return copyRow{columns: p.columnSet(columns), values: values}, nil
+}
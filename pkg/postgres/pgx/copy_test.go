@@ -0,0 +1,181 @@
+package _pgx_postgres
+
+import (
+	"reflect"
+	"testing"
+
+	_postgres "go-libs/pkg/postgres"
+)
+
+type copyItem struct {
+	ID    int64  `db:"id,pk,omitempty"`
+	SKU   string `db:"sku"`
+	Name  string `db:"name"`
+	Stock int    `db:"stock"`
+}
+
+func (copyItem) TableName() string {
+	return "inventory.items"
+}
+
+func TestNewCopyPlan(t *testing.T) {
+	opts := _postgres.DefaultCopyOptions()
+
+	plan, err := newCopyPlan(copyItem{SKU: "A-1"}, opts)
+	if err != nil {
+		t.Fatalf("newCopyPlan() error = %v", err)
+	}
+	if got, want := plan.table.Sanitize(), `"inventory"."items"`; got != want {
+		t.Errorf("newCopyPlan() table = %v, want %v", got, want)
+	}
+	if plan.tempTable != "" {
+		t.Errorf("newCopyPlan() temp table = %q, want none", plan.tempTable)
+	}
+
+	opts.OnConflict = _postgres.ConflictUpdate
+	opts.ConflictColumns = []string{"sku"}
+	plan, err = newCopyPlan(&copyItem{SKU: "A-1"}, opts)
+	if err != nil {
+		t.Fatalf("newCopyPlan() error = %v", err)
+	}
+	wantCreate := "CREATE TEMP TABLE copy_inventory_items ON COMMIT DROP AS SELECT id, sku, name, stock FROM inventory.items WITH NO DATA"
+	if plan.createTemp != wantCreate {
+		t.Errorf("newCopyPlan() create = %q, want %q", plan.createTemp, wantCreate)
+	}
+
+	opts.UpdateColumns = []string{"created_at"}
+	if _, err := newCopyPlan(copyItem{}, opts); err == nil {
+		t.Errorf("newCopyPlan() with an uncopied update column error = nil, want error")
+	}
+
+	opts.ConflictColumns = []string{"code"}
+	if _, err := newCopyPlan(copyItem{}, opts); err == nil {
+		t.Errorf("newCopyPlan() with an unknown conflict column error = nil, want error")
+	}
+}
+
+func TestCopyPlanInsert(t *testing.T) {
+	tests := []struct {
+		name       string
+		onConflict _postgres.ConflictAction
+		update     []string
+		row        copyItem
+		want       string
+	}{
+		{
+			name:       "update",
+			onConflict: _postgres.ConflictUpdate,
+			row:        copyItem{SKU: "A-1"},
+			want:       "INSERT INTO inventory.items (sku, name, stock) SELECT sku, name, stock FROM copy_inventory_items ON CONFLICT (sku) DO UPDATE SET name = EXCLUDED.name, stock = EXCLUDED.stock",
+		},
+		{
+			name:       "update with omitempty set",
+			onConflict: _postgres.ConflictUpdate,
+			row:        copyItem{ID: 7, SKU: "A-1"},
+			want:       "INSERT INTO inventory.items (id, sku, name, stock) SELECT id, sku, name, stock FROM copy_inventory_items ON CONFLICT (sku) DO UPDATE SET id = EXCLUDED.id, name = EXCLUDED.name, stock = EXCLUDED.stock",
+		},
+		{
+			name:       "update columns not in the row",
+			onConflict: _postgres.ConflictUpdate,
+			update:     []string{"id"},
+			row:        copyItem{SKU: "A-1"},
+			want:       "INSERT INTO inventory.items (sku, name, stock) SELECT sku, name, stock FROM copy_inventory_items ON CONFLICT (sku) DO NOTHING",
+		},
+		{
+			name:       "do nothing",
+			onConflict: _postgres.ConflictDoNothing,
+			row:        copyItem{},
+			want:       "INSERT INTO inventory.items (sku, name, stock) SELECT sku, name, stock FROM copy_inventory_items ON CONFLICT (sku) DO NOTHING",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := _postgres.DefaultCopyOptions()
+			opts.OnConflict = tt.onConflict
+			opts.ConflictColumns = []string{"sku"}
+			opts.UpdateColumns = tt.update
+
+			plan, err := newCopyPlan(tt.row, opts)
+			if err != nil {
+				t.Fatalf("newCopyPlan() error = %v", err)
+			}
+			row, err := plan.row(tt.row)
+			if err != nil {
+				t.Fatalf("row() error = %v", err)
+			}
+			if row.columns.insert != tt.want {
+				t.Errorf("row() insert = %q, want %q", row.columns.insert, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyPlanRow(t *testing.T) {
+	plan, err := newCopyPlan(copyItem{SKU: "A-1"}, _postgres.DefaultCopyOptions())
+	if err != nil {
+		t.Fatalf("newCopyPlan() error = %v", err)
+	}
+
+	row, err := plan.row(&copyItem{SKU: "B-2", Name: "Bolt", Stock: 5})
+	if err != nil {
+		t.Fatalf("row() error = %v", err)
+	}
+	if !reflect.DeepEqual(row.columns.columns, []string{"sku", "name", "stock"}) {
+		t.Errorf("row() columns = %v, want [sku name stock]", row.columns.columns)
+	}
+	if !reflect.DeepEqual(row.values, []any{"B-2", "Bolt", 5}) {
+		t.Errorf("row() values = %v, want [B-2 Bolt 5]", row.values)
+	}
+
+	// An omitempty field that was empty in the first row may be set in a later one
+	withID, err := plan.row(copyItem{ID: 9, SKU: "C-3"})
+	if err != nil {
+		t.Fatalf("row() with an omitempty column set error = %v", err)
+	}
+	if !reflect.DeepEqual(withID.columns.columns, []string{"id", "sku", "name", "stock"}) {
+		t.Errorf("row() columns = %v, want [id sku name stock]", withID.columns.columns)
+	}
+	if !reflect.DeepEqual(withID.values, []any{int64(9), "C-3", "", 0}) {
+		t.Errorf("row() values = %v, want [9 C-3  0]", withID.values)
+	}
+
+	again, _ := plan.row(copyItem{SKU: "D-4"})
+	if again.columns != row.columns {
+		t.Errorf("row() did not reuse the column set of an earlier row")
+	}
+
+	if _, err := plan.row(queryUser{}); err == nil {
+		t.Errorf("row() with another type error = nil, want error")
+	}
+	if _, err := plan.row((*copyItem)(nil)); err == nil {
+		t.Errorf("row() with a nil pointer error = nil, want error")
+	}
+}
+
+func TestGroupRows(t *testing.T) {
+	plan, err := newCopyPlan(copyItem{}, _postgres.DefaultCopyOptions())
+	if err != nil {
+		t.Fatalf("newCopyPlan() error = %v", err)
+	}
+
+	var rows []copyRow
+	for _, item := range []copyItem{{SKU: "A"}, {ID: 2, SKU: "B"}, {SKU: "C"}, {ID: 4, SKU: "D"}} {
+		row, err := plan.row(item)
+		if err != nil {
+			t.Fatalf("row() error = %v", err)
+		}
+		rows = append(rows, row)
+	}
+
+	groups := groupRows(rows)
+	if len(groups) != 2 {
+		t.Fatalf("groupRows() = %d groups, want 2", len(groups))
+	}
+	if len(groups[0].rows) != 2 || groups[0].rows[1][0] != "C" {
+		t.Errorf("groupRows() first group = %v, want rows A and C", groups[0].rows)
+	}
+	if len(groups[1].rows) != 2 || groups[1].rows[1][1] != "D" {
+		t.Errorf("groupRows() second group = %v, want rows B and D", groups[1].rows)
+	}
+}
@@ -0,0 +1,238 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AdvisoryLocker obtains session advisory locks, it is implemented by Connection and
+// MasterSlaveConnection
+type AdvisoryLocker interface {
+	TryAdvisoryLock(ctx context.Context, name string) (*AdvisoryLock, error)
+}
+
+var (
+	_ AdvisoryLocker = (*Connection)(nil)
+	_ AdvisoryLocker = (*MasterSlaveConnection)(nil)
+)
+
+// ElectionConfig holds the configuration for leader election
+type ElectionConfig struct {
+	// Name identifies the election, candidates with the same name compete for one lock
+	Name string `json:"name" yaml:"name"`
+	// RetryInterval is how often followers try to take the lock
+	RetryInterval time.Duration `json:"retry_interval" yaml:"retry_interval"`
+	// ResignTimeout bounds the release of the lock when leadership ends
+	ResignTimeout time.Duration `json:"resign_timeout" yaml:"resign_timeout"`
+}
+
+// DefaultElectionConfig returns an election configuration with sensible defaults
+func DefaultElectionConfig() ElectionConfig {
+	return ElectionConfig{
+		RetryInterval: 2 * time.Second,
+		ResignTimeout: 5 * time.Second,
+	}
+}
+
+// Validate checks if the election configuration is valid
+func (c *ElectionConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	if c.RetryInterval <= 0 {
+		return errors.New("retry interval must be greater than 0")
+	}
+	if c.ResignTimeout <= 0 {
+		return errors.New("resign timeout must be greater than 0")
+	}
+	return nil
+}
+
+// leaderLock is the lock held by a leader
+type leaderLock interface {
+	Lost() <-chan struct{}
+	Unlock(ctx context.Context) error
+}
+
+// Elector campaigns for leadership of a named election so exactly one instance runs a
+// singleton job at a time. Leadership is a session advisory lock: it is held as long as the
+// leader's connection lives, and a crashed leader's lock is released by the server.
+type Elector struct {
+	tryLock func(ctx context.Context, name string) (leaderLock, error)
+	config  ElectionConfig
+
+	onElected func(ctx context.Context)
+	onRevoked func()
+
+	// Control
+	cancel context.CancelFunc
+	doneCh chan struct{}
+
+	// State
+	running bool
+	leader  bool
+	mu      sync.RWMutex
+}
+
+// NewElector creates a candidate for the election
+func NewElector(locker AdvisoryLocker, config ElectionConfig) (*Elector, error) {
+	return newElector(func(ctx context.Context, name string) (leaderLock, error) {
+		lock, err := locker.TryAdvisoryLock(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return lock, nil
+	}, config)
+}
+
+func newElector(tryLock func(ctx context.Context, name string) (leaderLock, error), config ElectionConfig) (*Elector, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid election config: %w", err)
+	}
+
+	return &Elector{
+		tryLock: tryLock,
+		config:  config,
+	}, nil
+}
+
+// OnElected sets the function run when this instance becomes leader. Its context is
+// cancelled when leadership is lost or the elector is stopped, and the elector waits for
+// it to return before campaigning again. It must be called before Start.
+func (e *Elector) OnElected(fn func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onElected = fn
+}
+
+// OnRevoked sets the function called after leadership ends and OnElected has returned.
+// It must be called before Start.
+func (e *Elector) OnRevoked(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onRevoked = fn
+}
+
+// Start begins campaigning for leadership
+func (e *Elector) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return fmt.Errorf("elector already started")
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	e.doneCh = make(chan struct{})
+	e.running = true
+
+	go e.campaign(runCtx, e.doneCh)
+
+	return nil
+}
+
+// Stop stops campaigning and resigns leadership if held, so another candidate can take
+// over right away
+func (e *Elector) Stop() error {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return nil
+	}
+	cancel, doneCh := e.cancel, e.doneCh
+	e.mu.Unlock()
+
+	// running stays set until the goroutine has exited so Start cannot begin another one
+	cancel()
+	<-doneCh
+
+	e.mu.Lock()
+	e.running = false
+	e.mu.Unlock()
+
+	return nil
+}
+
+// IsLeader reports whether this instance currently holds leadership
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// campaign tries to take the lock until the context is done
+func (e *Elector) campaign(ctx context.Context, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	for {
+		// The lock outlives ctx so it is released by lead once OnElected has returned
+		lock, err := e.tryLock(context.WithoutCancel(ctx), e.config.Name)
+		if err == nil {
+			e.lead(ctx, lock)
+		} else if !errors.Is(err, ErrLockNotObtained) && ctx.Err() == nil {
+			fmt.Printf("Failed to campaign for leadership of %s: %v\n", e.config.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.config.RetryInterval):
+		}
+	}
+}
+
+// lead runs the OnElected callback until the lock is lost or the context is done
+func (e *Elector) lead(ctx context.Context, lock leaderLock) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	e.mu.Lock()
+	e.leader = true
+	onElected, onRevoked := e.onElected, e.onRevoked
+	e.mu.Unlock()
+
+	handlerDone := make(chan struct{})
+	go func() {
+		defer close(handlerDone)
+		if onElected != nil {
+			e.run(leaderCtx, onElected)
+		}
+	}()
+
+	select {
+	case <-lock.Lost():
+	case <-ctx.Done():
+	}
+
+	cancel()
+	<-handlerDone
+
+	e.mu.Lock()
+	e.leader = false
+	e.mu.Unlock()
+
+	// Resign so followers take over on their next attempt
+	releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), e.config.ResignTimeout)
+	if err := lock.Unlock(releaseCtx); err != nil && !errors.Is(err, ErrLockNotHeld) {
+		fmt.Printf("Failed to resign leadership of %s: %v\n", e.config.Name, err)
+	}
+	releaseCancel()
+
+	if onRevoked != nil {
+		onRevoked()
+	}
+}
+
+// run calls the OnElected callback, converting panics into log messages
+func (e *Elector) run(ctx context.Context, fn func(ctx context.Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Leader callback of %s panicked: %v\n", e.config.Name, r)
+		}
+	}()
+	fn(ctx)
+}
@@ -0,0 +1,233 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLockServer grants one lock at a time, like an advisory lock on the server
+type fakeLockServer struct {
+	mu     sync.Mutex
+	holder *fakeLock
+}
+
+func (s *fakeLockServer) tryLock(ctx context.Context, name string) (leaderLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.holder != nil {
+		return nil, ErrLockNotObtained
+	}
+	s.holder = &fakeLock{server: s, lost: make(chan struct{})}
+	return s.holder, nil
+}
+
+// drop simulates the loss of the holder's connection
+func (s *fakeLockServer) drop() {
+	s.mu.Lock()
+	holder := s.holder
+	s.mu.Unlock()
+	if holder != nil {
+		holder.Unlock(context.Background())
+	}
+}
+
+type fakeLock struct {
+	server *fakeLockServer
+	lost   chan struct{}
+	once   sync.Once
+}
+
+func (l *fakeLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *fakeLock) Unlock(ctx context.Context) error {
+	err := ErrLockNotHeld
+	l.once.Do(func() {
+		l.server.mu.Lock()
+		l.server.holder = nil
+		l.server.mu.Unlock()
+		close(l.lost)
+		err = nil
+	})
+	return err
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestElectorLeadership(t *testing.T) {
+	config := DefaultElectionConfig()
+	config.Name = "singleton"
+	config.RetryInterval = 20 * time.Millisecond
+
+	tests := []struct {
+		name       string
+		followers  int
+		stop       bool
+		wantLeader int
+	}{
+		{
+			// Stopping the leader resigns so the follower takes over
+			name:       "Leader stops",
+			followers:  1,
+			stop:       true,
+			wantLeader: 1,
+		},
+		{
+			// Losing the connection revokes leadership, and the elector campaigns again
+			name:       "Connection lost",
+			wantLeader: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeLockServer{}
+
+			var leaders atomic.Int32
+			electors := make([]*Elector, 1+tt.followers)
+			elected := make([]chan struct{}, len(electors))
+			revoked := make([]chan struct{}, len(electors))
+			for i := range electors {
+				elector, err := newElector(server.tryLock, config)
+				if err != nil {
+					t.Fatalf("newElector() error = %v", err)
+				}
+				electedCh, revokedCh := make(chan struct{}, 10), make(chan struct{}, 10)
+				elector.OnElected(func(ctx context.Context) {
+					if leaders.Add(1) > 1 {
+						t.Errorf("more than one leader elected")
+					}
+					electedCh <- struct{}{}
+					<-ctx.Done()
+					leaders.Add(-1)
+				})
+				elector.OnRevoked(func() {
+					revokedCh <- struct{}{}
+				})
+				electors[i], elected[i], revoked[i] = elector, electedCh, revokedCh
+			}
+
+			ctx := context.Background()
+			leader := electors[0]
+			if err := leader.Start(ctx); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer leader.Stop()
+			waitFor(t, elected[0], "leader to be elected")
+
+			// Followers wait while the leader holds the lock
+			for i, follower := range electors[1:] {
+				if err := follower.Start(ctx); err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+				defer follower.Stop()
+
+				select {
+				case <-elected[i+1]:
+					t.Fatalf("follower elected while the leader holds the lock")
+				case <-time.After(100 * time.Millisecond):
+				}
+			}
+
+			if tt.stop {
+				if err := leader.Stop(); err != nil {
+					t.Fatalf("Stop() error = %v", err)
+				}
+			} else {
+				server.drop()
+			}
+			waitFor(t, revoked[0], "leader to be revoked")
+			waitFor(t, elected[tt.wantLeader], "new leader to be elected")
+
+			for i, elector := range electors {
+				if got := elector.IsLeader(); got != (i == tt.wantLeader) {
+					t.Errorf("electors[%d].IsLeader() = %v, want %v", i, got, i == tt.wantLeader)
+				}
+			}
+		})
+	}
+}
+
+func TestElectionConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *ElectionConfig)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(c *ElectionConfig) { c.Name = "jobs" }},
+		{name: "missing name", modify: func(c *ElectionConfig) {}, wantErr: true},
+		{name: "zero retry interval", modify: func(c *ElectionConfig) { c.Name = "jobs"; c.RetryInterval = 0 }, wantErr: true},
+		{name: "zero resign timeout", modify: func(c *ElectionConfig) { c.Name = "jobs"; c.ResignTimeout = 0 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultElectionConfig()
+			tt.modify(&config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestElectorStartWhileStopping(t *testing.T) {
+	config := DefaultElectionConfig()
+	config.Name = "singleton"
+	elector, err := newElector((&fakeLockServer{}).tryLock, config)
+	if err != nil {
+		t.Fatalf("newElector() error = %v", err)
+	}
+
+	elected := make(chan struct{}, 1)
+	release := make(chan struct{})
+	elector.OnElected(func(ctx context.Context) {
+		elected <- struct{}{}
+		<-ctx.Done()
+		// Keep the campaign running after Stop was called
+		<-release
+	})
+
+	ctx := context.Background()
+	if err := elector.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitFor(t, elected, "elector to be elected")
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- elector.Stop() }()
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if err := elector.Start(ctx); err == nil {
+			t.Fatalf("Start() while the campaign is still running error = nil, want error")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(release)
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Stop() did not return")
+	}
+
+	if err := elector.Start(ctx); err != nil {
+		t.Fatalf("Start() after Stop error = %v", err)
+	}
+	elector.Stop()
+}
@@ -0,0 +1,410 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Notification is a message received on a LISTEN channel
+type Notification struct {
+	Channel string
+	Payload string
+	// PID is the process ID of the server backend that sent the notification
+	PID uint32
+}
+
+// NotificationHandler handles a notification. Handlers of a listener run one at a time in the
+// order the notifications arrive.
+type NotificationHandler func(ctx context.Context, n *Notification) error
+
+// JSONHandler returns a handler decoding JSON payloads into T
+func JSONHandler[T any](fn func(ctx context.Context, channel string, payload T) error) NotificationHandler {
+	return func(ctx context.Context, n *Notification) error {
+		var payload T
+		if err := json.Unmarshal([]byte(n.Payload), &payload); err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		return fn(ctx, n.Channel, payload)
+	}
+}
+
+// ListenerConfig holds the configuration of a Listener
+type ListenerConfig struct {
+	// PingInterval is how long the connection may stay idle before it is pinged to detect
+	// a silently dropped connection
+	PingInterval time.Duration `json:"ping_interval" yaml:"ping_interval"`
+	// ReconnectInterval is the first delay between reconnection attempts, it doubles on every
+	// attempt up to MaxReconnectInterval
+	ReconnectInterval    time.Duration `json:"reconnect_interval" yaml:"reconnect_interval"`
+	MaxReconnectInterval time.Duration `json:"max_reconnect_interval" yaml:"max_reconnect_interval"`
+
+	// OnReconnect is called once LISTEN is re-established after a connection loss.
+	// Notifications sent while the connection was down are lost, so callers resync here.
+	OnReconnect func(ctx context.Context) `json:"-" yaml:"-"`
+}
+
+// DefaultListenerConfig returns a listener configuration with sensible defaults
+func DefaultListenerConfig() *ListenerConfig {
+	return &ListenerConfig{
+		PingInterval:         30 * time.Second,
+		ReconnectInterval:    time.Second,
+		MaxReconnectInterval: 30 * time.Second,
+	}
+}
+
+// Validate checks if the listener configuration is valid
+func (c *ListenerConfig) Validate() error {
+	if c.PingInterval <= 0 {
+		return fmt.Errorf("ping_interval must be greater than 0")
+	}
+	if c.ReconnectInterval <= 0 {
+		return fmt.Errorf("reconnect_interval must be greater than 0")
+	}
+	if c.MaxReconnectInterval < c.ReconnectInterval {
+		return fmt.Errorf("max_reconnect_interval cannot be less than reconnect_interval")
+	}
+	return nil
+}
+
+// Listener receives LISTEN/NOTIFY notifications on a dedicated connection, separate from the
+// pool, and re-establishes LISTEN after a connection loss
+type Listener struct {
+	conn   *Connection
+	config *ListenerConfig
+
+	handlers map[string][]NotificationHandler
+	// dirty is set when the channels changed since the loop last synced them
+	dirty      bool
+	waitCancel context.CancelFunc
+	mu         sync.Mutex
+
+	// Control
+	running bool
+	cancel  context.CancelFunc
+	doneCh  chan struct{}
+}
+
+// listenerConn is the dedicated connection of one run of the listener and the channels
+// it listens on. It is owned by the loop.
+type listenerConn struct {
+	pgConn    *pgx.Conn
+	listening map[string]bool
+}
+
+// NewListener creates a listener using the configuration of this connection
+func (c *Connection) NewListener(cfg *ListenerConfig) *Listener {
+	if cfg == nil {
+		cfg = DefaultListenerConfig()
+	}
+	return &Listener{
+		conn:     c,
+		config:   cfg,
+		handlers: make(map[string][]NotificationHandler),
+	}
+}
+
+// Listen registers a handler for channel. It may be called before or after Start.
+func (l *Listener) Listen(channel string, handler NotificationHandler) error {
+	if channel == "" {
+		return fmt.Errorf("channel is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("handler is required")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.handlers[channel] = append(l.handlers[channel], handler)
+	l.wakeLocked()
+	return nil
+}
+
+// Unlisten removes the handlers of channel and stops listening on it
+func (l *Listener) Unlisten(channel string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.handlers, channel)
+	l.wakeLocked()
+}
+
+// wakeLocked interrupts the wait for notifications so the loop syncs the channels
+func (l *Listener) wakeLocked() {
+	l.dirty = true
+	if l.waitCancel != nil {
+		l.waitCancel()
+	}
+}
+
+// Start connects and listens on the registered channels in the background
+func (l *Listener) Start(ctx context.Context) error {
+	if err := l.config.Validate(); err != nil {
+		return fmt.Errorf("invalid listener config: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running {
+		return fmt.Errorf("listener already started")
+	}
+
+	conn, err := l.connect(ctx)
+	if err != nil {
+		return err
+	}
+	if err := l.listenAll(ctx, conn); err != nil {
+		conn.pgConn.Close(context.Background())
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.doneCh = make(chan struct{})
+	l.running = true
+
+	go l.run(runCtx, conn, l.doneCh)
+
+	return nil
+}
+
+// Stop stops listening and closes the dedicated connection
+func (l *Listener) Stop() error {
+	l.mu.Lock()
+	if !l.running {
+		l.mu.Unlock()
+		return nil
+	}
+	cancel, doneCh := l.cancel, l.doneCh
+	l.mu.Unlock()
+
+	// running stays set until the loop has closed its connection so Start cannot open
+	// another one meanwhile
+	cancel()
+	<-doneCh
+
+	l.mu.Lock()
+	l.running = false
+	l.mu.Unlock()
+
+	return nil
+}
+
+// IsListening checks if the listener is active
+func (l *Listener) IsListening() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running
+}
+
+// connect opens a dedicated connection
+func (l *Listener) connect(ctx context.Context) (*listenerConn, error) {
+	connectCtx, cancel := context.WithTimeout(ctx, l.conn.config.ConnectTimeout)
+	defer cancel()
+
+	pgConn, err := pgx.Connect(connectCtx, l.conn.config.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create listener connection: %w", err)
+	}
+
+	return &listenerConn{pgConn: pgConn, listening: make(map[string]bool)}, nil
+}
+
+// listenAll listens on the registered channels of a new connection. The caller holds mu.
+func (l *Listener) listenAll(ctx context.Context, conn *listenerConn) error {
+	l.dirty = false
+	for channel := range l.handlers {
+		if _, err := conn.pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", channel, err)
+		}
+		conn.listening[channel] = true
+	}
+	return nil
+}
+
+// run waits for notifications on conn until ctx is done
+func (l *Listener) run(ctx context.Context, conn *listenerConn, doneCh chan struct{}) {
+	defer close(doneCh)
+	defer func() {
+		conn.pgConn.Close(context.Background())
+	}()
+
+	for {
+		if err := l.sync(ctx, conn); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			fmt.Printf("Failed to listen: %v\n", err)
+			l.reconnect(ctx, conn)
+			continue
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, l.config.PingInterval)
+		l.mu.Lock()
+		l.waitCancel = cancel
+		if l.dirty {
+			cancel()
+		}
+		l.mu.Unlock()
+
+		n, err := conn.pgConn.WaitForNotification(waitCtx)
+
+		l.mu.Lock()
+		l.waitCancel = nil
+		l.mu.Unlock()
+		cancel()
+
+		switch {
+		case err == nil:
+			l.dispatch(ctx, &Notification{Channel: n.Channel, Payload: n.Payload, PID: n.PID})
+		case ctx.Err() != nil:
+			return
+		case errors.Is(waitCtx.Err(), context.Canceled):
+			// Woken up to sync the channels
+		case errors.Is(waitCtx.Err(), context.DeadlineExceeded):
+			if err := l.ping(ctx, conn); err != nil {
+				fmt.Printf("Listener connection is down: %v\n", err)
+				l.reconnect(ctx, conn)
+			}
+		default:
+			fmt.Printf("Listener connection is down: %v\n", err)
+			l.reconnect(ctx, conn)
+		}
+	}
+}
+
+// sync issues LISTEN and UNLISTEN so conn listens on the registered channels
+func (l *Listener) sync(ctx context.Context, conn *listenerConn) error {
+	l.mu.Lock()
+	if !l.dirty {
+		l.mu.Unlock()
+		return nil
+	}
+	l.dirty = false
+	wanted := make(map[string]bool, len(l.handlers))
+	for channel := range l.handlers {
+		wanted[channel] = true
+	}
+	l.mu.Unlock()
+
+	for channel := range wanted {
+		if conn.listening[channel] {
+			continue
+		}
+		if _, err := conn.pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			l.markDirty()
+			return fmt.Errorf("failed to listen on %s: %w", channel, err)
+		}
+		conn.listening[channel] = true
+	}
+
+	for channel := range conn.listening {
+		if wanted[channel] {
+			continue
+		}
+		if _, err := conn.pgConn.Exec(ctx, "UNLISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			l.markDirty()
+			return fmt.Errorf("failed to unlisten on %s: %w", channel, err)
+		}
+		delete(conn.listening, channel)
+	}
+
+	return nil
+}
+
+// markDirty makes the loop sync the channels again
+func (l *Listener) markDirty() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.dirty = true
+}
+
+// ping checks the dedicated connection
+func (l *Listener) ping(ctx context.Context, conn *listenerConn) error {
+	pingCtx, cancel := context.WithTimeout(ctx, l.conn.config.QueryTimeout)
+	defer cancel()
+	return conn.pgConn.Ping(pingCtx)
+}
+
+// reconnect replaces the connection of conn, retrying with backoff until ctx is done, then
+// re-establishes LISTEN and calls OnReconnect
+func (l *Listener) reconnect(ctx context.Context, conn *listenerConn) {
+	conn.pgConn.Close(context.Background())
+
+	delay := l.config.ReconnectInterval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		next, err := l.connect(ctx)
+		if err == nil {
+			*conn = *next
+			l.markDirty()
+			if err = l.sync(ctx, conn); err != nil {
+				conn.pgConn.Close(context.Background())
+			}
+		}
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		fmt.Printf("Failed to reconnect listener: %v\n", err)
+		delay = min(delay*2, l.config.MaxReconnectInterval)
+	}
+
+	fmt.Println("Listener reconnected")
+	if l.config.OnReconnect != nil {
+		l.config.OnReconnect(ctx)
+	}
+}
+
+// dispatch passes a notification to the handlers of its channel
+func (l *Listener) dispatch(ctx context.Context, n *Notification) {
+	l.mu.Lock()
+	handlers := l.handlers[n.Channel]
+	l.mu.Unlock()
+
+	for _, handler := range handlers {
+		if err := l.handle(ctx, handler, n); err != nil {
+			fmt.Printf("Failed to handle notification on %s: %v\n", n.Channel, err)
+		}
+	}
+}
+
+// handle runs a handler, turning a panic into an error
+func (l *Listener) handle(ctx context.Context, handler NotificationHandler, n *Notification) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, n)
+}
+
+// Notify sends a notification on channel. Inside a transaction carried by ctx it is
+// delivered when the transaction commits.
+func (c *Connection) Notify(ctx context.Context, channel, payload string) error {
+	return c.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+}
+
+// NotifyJSON sends a notification on channel with payload encoded as JSON
+func (c *Connection) NotifyJSON(ctx context.Context, channel string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return c.Notify(ctx, channel, string(data))
+}
@@ -0,0 +1,93 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	_postgres "go-libs/pkg/postgres"
+)
+
+type orderEvent struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func TestJSONHandler(t *testing.T) {
+	var got orderEvent
+	handler := JSONHandler(func(ctx context.Context, channel string, payload orderEvent) error {
+		if channel != "orders" {
+			t.Errorf("channel = %q, want orders", channel)
+		}
+		got = payload
+		return nil
+	})
+
+	err := handler(context.Background(), &Notification{Channel: "orders", Payload: `{"id": 42, "status": "paid"}`})
+	if err != nil || got != (orderEvent{ID: 42, Status: "paid"}) {
+		t.Errorf("JSONHandler() = %+v, %v, want order 42 paid", got, err)
+	}
+
+	if err := handler(context.Background(), &Notification{Channel: "orders", Payload: "not json"}); err == nil {
+		t.Errorf("JSONHandler() with an invalid payload error = nil, want error")
+	}
+}
+
+func TestListenerDispatch(t *testing.T) {
+	l := NewConnection(_postgres.DefaultConfig()).NewListener(nil)
+
+	var calls []string
+	l.Listen("orders", func(ctx context.Context, n *Notification) error {
+		calls = append(calls, "first:"+n.Payload)
+		return errors.New("failed")
+	})
+	l.Listen("orders", func(ctx context.Context, n *Notification) error {
+		panic("boom")
+	})
+	l.Listen("orders", func(ctx context.Context, n *Notification) error {
+		calls = append(calls, "third:"+n.Payload)
+		return nil
+	})
+
+	// A failing or panicking handler does not stop the others
+	l.dispatch(context.Background(), &Notification{Channel: "orders", Payload: "1"})
+	l.dispatch(context.Background(), &Notification{Channel: "users", Payload: "2"})
+
+	if len(calls) != 2 || calls[0] != "first:1" || calls[1] != "third:1" {
+		t.Errorf("handler calls = %v, want [first:1 third:1]", calls)
+	}
+
+	l.Unlisten("orders")
+	calls = nil
+	l.dispatch(context.Background(), &Notification{Channel: "orders", Payload: "3"})
+	if len(calls) != 0 {
+		t.Errorf("handler calls after Unlisten() = %v, want none", calls)
+	}
+}
+
+func TestListenerListenValidation(t *testing.T) {
+	l := NewConnection(_postgres.DefaultConfig()).NewListener(nil)
+	handler := func(ctx context.Context, n *Notification) error { return nil }
+
+	if err := l.Listen("", handler); err == nil {
+		t.Errorf("Listen() without a channel error = nil, want error")
+	}
+	if err := l.Listen("orders", nil); err == nil {
+		t.Errorf("Listen() without a handler error = nil, want error")
+	}
+	if err := l.Listen("orders", handler); err != nil || !l.dirty {
+		t.Errorf("Listen() error = %v, dirty = %v, want nil and true", err, l.dirty)
+	}
+}
+
+func TestListenerConfigValidate(t *testing.T) {
+	config := DefaultListenerConfig()
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	config.MaxReconnectInterval = config.ReconnectInterval / 2
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() with max_reconnect_interval < reconnect_interval error = nil, want error")
+	}
+}
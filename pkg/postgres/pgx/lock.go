@@ -0,0 +1,236 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	_postgres "go-libs/pkg/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrLockNotObtained is returned when an advisory lock is held by another session
+	ErrLockNotObtained = errors.New("advisory lock not obtained")
+	// ErrLockNotHeld is returned when releasing an advisory lock that was already released
+	ErrLockNotHeld = errors.New("advisory lock not held")
+)
+
+// advisoryLockCheckInterval is how often the connection of a session lock is pinged, so a
+// lock lost with its connection is noticed
+const advisoryLockCheckInterval = 5 * time.Second
+
+// AdvisoryLock is a session-scoped advisory lock. It keeps a connection of the pool for as
+// long as it is held, since the lock belongs to the database session.
+type AdvisoryLock struct {
+	name string
+	key  int64
+	conn *pgxpool.Conn
+
+	lost     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	released bool
+	mu       sync.Mutex
+}
+
+// AdvisoryLock waits until the session lock of name is obtained. The lock is released when
+// ctx is done, by Unlock, or when its connection is lost; Lost reports all three.
+func (c *Connection) AdvisoryLock(ctx context.Context, name string) (*AdvisoryLock, error) {
+	return c.acquireAdvisoryLock(ctx, name, true)
+}
+
+// TryAdvisoryLock obtains the session lock of name or returns ErrLockNotObtained without
+// waiting. The lock is released as with AdvisoryLock.
+func (c *Connection) TryAdvisoryLock(ctx context.Context, name string) (*AdvisoryLock, error) {
+	return c.acquireAdvisoryLock(ctx, name, false)
+}
+
+// acquireAdvisoryLock takes the lock on a connection of the pool and, when it is obtained,
+// keeps the connection until the lock is released
+func (c *Connection) acquireAdvisoryLock(ctx context.Context, name string, wait bool) (*AdvisoryLock, error) {
+	if c.pool == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	// Waiting for the lock is bounded by ctx only
+	queryCtx, cancel := ctx, context.CancelFunc(func() {})
+	if !wait {
+		queryCtx, cancel = context.WithTimeout(ctx, c.config.QueryTimeout)
+	}
+	defer cancel()
+
+	conn, err := c.pool.Acquire(queryCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	key := _postgres.AdvisoryLockKey(name)
+	obtained := true
+	if wait {
+		_, err = conn.Exec(queryCtx, "SELECT pg_advisory_lock($1)", key)
+	} else {
+		err = conn.QueryRow(queryCtx, "SELECT pg_try_advisory_lock($1)", key).Scan(&obtained)
+	}
+	if err != nil {
+		// The lock may have been granted before the error, closing the session releases it
+		conn.Conn().Close(context.Background())
+		conn.Release()
+		return nil, fmt.Errorf("failed to obtain advisory lock %s: %w", name, err)
+	}
+	if !obtained {
+		conn.Release()
+		return nil, ErrLockNotObtained
+	}
+
+	l := &AdvisoryLock{
+		name: name,
+		key:  key,
+		conn: conn,
+		lost: make(chan struct{}),
+		stop: make(chan struct{}),
+	}
+	go l.watch(ctx)
+
+	return l, nil
+}
+
+// Name returns the name of the lock
+func (l *AdvisoryLock) Name() string {
+	return l.name
+}
+
+// Key returns the key of the lock in pg_locks
+func (l *AdvisoryLock) Key() int64 {
+	return l.key
+}
+
+// Lost returns a channel closed once the lock is no longer held
+func (l *AdvisoryLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Unlock releases the lock and returns its connection to the pool. It returns
+// ErrLockNotHeld if the lock was already released.
+func (l *AdvisoryLock) Unlock(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	return l.release(ctx)
+}
+
+// watch releases the lock when ctx is done and checks its connection until then
+func (l *AdvisoryLock) watch(ctx context.Context) {
+	ticker := time.NewTicker(advisoryLockCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), advisoryLockCheckInterval)
+			if err := l.release(releaseCtx); err != nil && !errors.Is(err, ErrLockNotHeld) {
+				fmt.Printf("Failed to release advisory lock %s: %v\n", l.name, err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := l.ping(ctx); err != nil {
+				fmt.Printf("Lost advisory lock %s: %v\n", l.name, err)
+				return
+			}
+		}
+	}
+}
+
+// ping checks the connection holding the lock, dropping the lock if it is broken
+func (l *AdvisoryLock) ping(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.released {
+		return ErrLockNotHeld
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, advisoryLockCheckInterval)
+	defer cancel()
+	err := l.conn.Ping(pingCtx)
+	if err != nil && ctx.Err() == nil {
+		l.conn.Conn().Close(context.Background())
+		l.releaseLocked()
+		return err
+	}
+	return nil
+}
+
+// release unlocks the lock and returns the connection to the pool
+func (l *AdvisoryLock) release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.released {
+		return ErrLockNotHeld
+	}
+	defer l.releaseLocked()
+
+	var unlocked bool
+	if err := l.conn.QueryRow(ctx, "SELECT pg_advisory_unlock($1)", l.key).Scan(&unlocked); err != nil {
+		// Closing the session releases its locks
+		l.conn.Conn().Close(context.Background())
+		return fmt.Errorf("failed to release advisory lock %s: %w", l.name, err)
+	}
+	if !unlocked {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// releaseLocked returns the connection to the pool and reports the lock lost. The caller
+// holds mu.
+func (l *AdvisoryLock) releaseLocked() {
+	l.released = true
+	l.conn.Release()
+	close(l.lost)
+}
+
+// AdvisoryXactLock waits until the transaction lock of name is obtained. ctx must carry a
+// transaction of this connection, see WithTx; the lock is released when it ends.
+func (c *Connection) AdvisoryXactLock(ctx context.Context, name string) error {
+	if _, ok := c.txFromContext(ctx); !ok {
+		return fmt.Errorf("advisory transaction lock requires a transaction")
+	}
+	if err := c.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", _postgres.AdvisoryLockKey(name)); err != nil {
+		return fmt.Errorf("failed to obtain advisory lock %s: %w", name, err)
+	}
+	return nil
+}
+
+// TryAdvisoryXactLock obtains the transaction lock of name or returns ErrLockNotObtained
+// without waiting. ctx must carry a transaction of this connection, see WithTx.
+func (c *Connection) TryAdvisoryXactLock(ctx context.Context, name string) error {
+	if _, ok := c.txFromContext(ctx); !ok {
+		return fmt.Errorf("advisory transaction lock requires a transaction")
+	}
+
+	var obtained bool
+	if err := c.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", _postgres.AdvisoryLockKey(name)).Scan(&obtained); err != nil {
+		return fmt.Errorf("failed to obtain advisory lock %s: %w", name, err)
+	}
+	if !obtained {
+		return ErrLockNotObtained
+	}
+	return nil
+}
+
+// WithAdvisoryXactLock runs fn in a transaction holding the lock of name, or returns
+// ErrLockNotObtained without running it when another session holds the lock
+func (c *Connection) WithAdvisoryXactLock(ctx context.Context, name string, fn _postgres.TxFunc) error {
+	return c.WithTx(ctx, _postgres.DefaultTxOptions(), func(ctx context.Context, tx _postgres.Transaction) error {
+		if err := c.TryAdvisoryXactLock(ctx, name); err != nil {
+			return err
+		}
+		return fn(ctx, tx)
+	})
+}
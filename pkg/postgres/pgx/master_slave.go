@@ -3,6 +3,7 @@ package _pgx_postgres
 import (
 	"context"
 	"fmt"
+	"iter"
 	"sync"
 	"time"
 
@@ -17,9 +18,13 @@ var _ _postgres.PgxMasterSlaveClient = (*MasterSlaveConnection)(nil)
 // MasterSlaveConnection implements the PgxMasterSlaveClient interface
 type MasterSlaveConnection struct {
 	config       *_postgres.MasterSlaveConfig
-	masterConn   *Connection
-	slaveConn    *Connection
+	masterNode   *Connection // the configured master
+	masterConn   *Connection // the current primary, serving writes
+	primaryName  string
+	replicas     *_postgres.ReplicaSet[*Connection]
 	role         string // "master" or "slave"
+	onRoleChange func(_postgres.RoleChange)
+	tracer       pgx.QueryTracer
 	mu           sync.RWMutex
 	healthTicker *time.Ticker
 	stopChan     chan struct{}
@@ -34,32 +39,68 @@ func NewMasterSlaveConnection(cfg *_postgres.MasterSlaveConfig) *MasterSlaveConn
 	}
 }
 
-// Connect establishes connections to both master and slave (if configured)
+// Connect establishes connections to the master and the replicas (if configured)
 func (c *MasterSlaveConnection) Connect(ctx context.Context) error {
 	if err := c.config.Validate(); err != nil {
 		return fmt.Errorf("invalid master-slave config: %w", err)
 	}
 
-	// Connect to master
-	c.masterConn = NewConnection(c.config.Master)
-	if err := c.masterConn.Connect(ctx); err != nil {
-		return fmt.Errorf("failed to connect to master: %w", err)
+	// Connect to master. With auto failover a master that is down is tolerated as long
+	// as a promoted replica takes the writes.
+	masterConn := c.newMasterConnection()
+	masterErr := masterConn.Connect(ctx)
+	if masterErr != nil && !c.config.AutoFailover {
+		return fmt.Errorf("failed to connect to master: %w", masterErr)
+	}
+	if masterErr == nil {
+		c.masterNode = masterConn
+	} else {
+		fmt.Printf("Failed to connect to master: %v\n", masterErr)
 	}
 
-	// Connect to slave if enabled
-	if c.config.UseSlaveConnection {
-		c.slaveConn = NewConnection(c.config.Slave)
-		if err := c.slaveConn.Connect(ctx); err != nil {
-			// Close master connection
-			c.masterConn.Close()
-			c.masterConn = nil
-			return fmt.Errorf("failed to connect to slave: %w", err)
+	// Connect to the replicas. One that is down is left out of rotation and
+	// connected by the replica checks once it comes up.
+	c.replicas = _postgres.NewReplicaSet[*Connection](
+		c.config.ReplicaBalancing,
+		c.config.MaxReplicationLag,
+		c.replicaCheckInterval(),
+	)
+	for _, rc := range c.config.ReplicaConfigs() {
+		conn := NewConnection(rc.Config)
+		conn.SetQueryTracer(c.tracer)
+		connected := true
+		if err := conn.Connect(ctx); err != nil {
+			fmt.Printf("Failed to connect to replica %s: %v\n", rc.DisplayName(), err)
+			connected = false
+		}
+		c.replicas.Add(rc.DisplayName(), rc.Weight, conn, connected)
+	}
+
+	// Find the node serving writes: the master unless it was demoted and a replica promoted
+	c.masterConn, c.primaryName = c.masterNode, _postgres.MasterNodeName
+	if c.config.AutoFailover {
+		primary, ok := _postgres.DetectPrimary(ctx, c.primaryCandidates())
+		switch {
+		case ok:
+			c.masterConn, c.primaryName = primary.Conn, primary.Name
+			if primary.Name != _postgres.MasterNodeName {
+				fmt.Printf("Master is not the primary, writing to %s\n", primary.Name)
+			}
+		case c.masterNode == nil:
+			c.replicas.Close()
+			return fmt.Errorf("failed to connect to master and no replica is a primary: %w", masterErr)
 		}
 	}
 
 	// Start health check if enabled
 	if c.config.HealthCheckEnabled {
 		c.startHealthCheck()
+		if c.replicas.Len() > 0 {
+			c.replicas.Check(ctx)
+			if err := c.replicas.Start(); err != nil {
+				return fmt.Errorf("failed to start replica checks: %w", err)
+			}
+		}
 	}
 
 	return nil
@@ -76,26 +117,26 @@ func (c *MasterSlaveConnection) Close() error {
 		close(c.stopChan)
 	}
 
-	var masterErr, slaveErr error
+	var masterErr, replicaErr error
 
-	// Close master connection
-	if c.masterConn != nil {
-		masterErr = c.masterConn.Close()
-		c.masterConn = nil
+	// Close master connection. The primary is either the master or a replica.
+	if c.masterNode != nil {
+		masterErr = c.masterNode.Close()
+		c.masterNode = nil
 	}
+	c.masterConn = nil
 
-	// Close slave connection
-	if c.slaveConn != nil {
-		slaveErr = c.slaveConn.Close()
-		c.slaveConn = nil
+	// Close replica connections
+	if c.replicas != nil {
+		replicaErr = c.replicas.Close()
 	}
 
 	// Return the first error encountered
 	if masterErr != nil {
 		return fmt.Errorf("error closing master connection: %w", masterErr)
 	}
-	if slaveErr != nil {
-		return fmt.Errorf("error closing slave connection: %w", slaveErr)
+	if replicaErr != nil {
+		return replicaErr
 	}
 
 	return nil
@@ -123,8 +164,8 @@ func (c *MasterSlaveConnection) IsHealthy(ctx context.Context) bool {
 		return true
 	}
 
-	// If master is down but slave is healthy and auto-failover is enabled
-	if c.config.AutoFailover && c.slaveConn != nil && c.slaveConn.IsHealthy(ctx) {
+	// If master is down but a replica is healthy and auto-failover is enabled
+	if c.config.AutoFailover && c.replicas != nil && c.replicas.HasUsable() {
 		return true
 	}
 
@@ -143,6 +184,93 @@ func (c *MasterSlaveConnection) BeginTx(ctx context.Context) (_postgres.Transact
 	return c.masterConn.BeginTx(ctx)
 }
 
+// BeginTxWithOptions begins a transaction with options on the master
+func (c *MasterSlaveConnection) BeginTxWithOptions(ctx context.Context, opts _postgres.TxOptions) (_postgres.Transaction, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.masterConn == nil {
+		return nil, fmt.Errorf("master connection not established")
+	}
+
+	return c.masterConn.BeginTxWithOptions(ctx, opts)
+}
+
+// WithTx runs fn in a transaction on the master
+func (c *MasterSlaveConnection) WithTx(ctx context.Context, opts _postgres.TxOptions, fn _postgres.TxFunc) error {
+	c.mu.RLock()
+	masterConn := c.masterConn
+	c.mu.RUnlock()
+
+	if masterConn == nil {
+		return fmt.Errorf("master connection not established")
+	}
+
+	_postgres.RecordWrite(ctx)
+	return masterConn.WithTx(ctx, opts, fn)
+}
+
+// Exec executes a statement on the master
+func (c *MasterSlaveConnection) Exec(ctx context.Context, query string, args ...any) error {
+	c.mu.RLock()
+	masterConn := c.masterConn
+	c.mu.RUnlock()
+
+	if masterConn == nil {
+		return fmt.Errorf("master connection not established")
+	}
+
+	_postgres.RecordWrite(ctx)
+	return masterConn.Exec(ctx, query, args...)
+}
+
+// Query executes a query on the slave when it is read-only and read routing allows it,
+// otherwise on the master
+func (c *MasterSlaveConnection) Query(ctx context.Context, query string, args ...any) (_postgres.Rows, error) {
+	conn, err := c.route(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Query(ctx, query, args...)
+}
+
+// QueryRow executes a query returning a single row, routed like Query
+func (c *MasterSlaveConnection) QueryRow(ctx context.Context, query string, args ...any) _postgres.Row {
+	conn, err := c.route(ctx, query)
+	if err != nil {
+		return &RowWrapper{err: err}
+	}
+	return conn.QueryRow(ctx, query, args...)
+}
+
+// route picks the connection for a query. Statements that may write are recorded for
+// read-your-writes.
+func (c *MasterSlaveConnection) route(ctx context.Context, query string) (*Connection, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.config.RouteReadsToSlave && c.replicas != nil &&
+		_postgres.UseSlave(ctx, query, c.config.ReadYourWritesWindow) {
+		if replica, ok := c.replicas.Pick(); ok {
+			return replica, nil
+		}
+	}
+
+	if c.masterConn == nil {
+		// Without a primary the replicas still serve reads
+		if c.replicas != nil && _postgres.IsReadOnlyQuery(query) {
+			if replica, ok := c.replicas.Pick(); ok {
+				return replica, nil
+			}
+		}
+		return nil, fmt.Errorf("master connection not established")
+	}
+	if !_postgres.IsReadOnlyQuery(query) {
+		_postgres.RecordWrite(ctx)
+	}
+	return c.masterConn, nil
+}
+
 // GetMasterClient returns the master client
 func (c *MasterSlaveConnection) GetMasterClient() _postgres.DatabaseClient {
 	c.mu.RLock()
@@ -150,18 +278,61 @@ func (c *MasterSlaveConnection) GetMasterClient() _postgres.DatabaseClient {
 	return c.masterConn
 }
 
-// GetSlaveClient returns the slave client
+// GetSlaveClient returns a replica chosen by the balancing policy, or nil if none is usable
 func (c *MasterSlaveConnection) GetSlaveClient() _postgres.DatabaseClient {
+	if replica := c.pickReplica(); replica != nil {
+		return replica
+	}
+	return nil
+}
+
+// HasSlaveConnected returns true if a replica is usable
+func (c *MasterSlaveConnection) HasSlaveConnected() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.slaveConn
+	return c.replicas != nil && c.replicas.HasUsable()
 }
 
-// HasSlaveConnected returns true if a slave connection is available
-func (c *MasterSlaveConnection) HasSlaveConnected() bool {
+// GetReplicaStatus returns the health and lag of every replica
+func (c *MasterSlaveConnection) GetReplicaStatus() []_postgres.ReplicaStatus {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.slaveConn != nil
+	if c.replicas == nil {
+		return nil
+	}
+	return c.replicas.Status()
+}
+
+// pickReplica returns a usable replica or nil
+func (c *MasterSlaveConnection) pickReplica() *Connection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.replicas == nil {
+		return nil
+	}
+	replica, _ := c.replicas.Pick()
+	return replica
+}
+
+// newMasterConnection creates a connection to the configured master
+func (c *MasterSlaveConnection) newMasterConnection() *Connection {
+	conn := NewConnection(c.config.Master)
+	conn.SetQueryTracer(c.tracer)
+	return conn
+}
+
+// SetQueryTracer attaches a tracer to the master and replica connections. Call it before
+// Connect.
+func (c *MasterSlaveConnection) SetQueryTracer(tracer pgx.QueryTracer) {
+	c.tracer = tracer
+}
+
+// replicaCheckInterval returns the period of replica health and lag checks
+func (c *MasterSlaveConnection) replicaCheckInterval() time.Duration {
+	if c.config.ReplicaCheckInterval > 0 {
+		return c.config.ReplicaCheckInterval
+	}
+	return c.config.HealthCheckInterval
 }
 
 // IsMaster returns true if this connection is a master
@@ -208,12 +379,10 @@ func (c *MasterSlaveConnection) GetMasterPool() *pgxpool.Pool {
 	return nil
 }
 
-// GetSlavePool returns the slave pool
+// GetSlavePool returns the pool of a replica chosen by the balancing policy
 func (c *MasterSlaveConnection) GetSlavePool() *pgxpool.Pool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if c.slaveConn != nil {
-		return c.slaveConn.GetPool()
+	if replica := c.pickReplica(); replica != nil {
+		return replica.GetPool()
 	}
 	return nil
 }
@@ -233,6 +402,7 @@ func (c *MasterSlaveConnection) InsertModel(ctx context.Context, model any) erro
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	if c.masterConn != nil {
+		_postgres.RecordWrite(ctx)
 		return c.masterConn.InsertModel(ctx, model)
 	}
 	return fmt.Errorf("master connection not established")
@@ -243,6 +413,7 @@ func (c *MasterSlaveConnection) UpsertModel(ctx context.Context, model any, prim
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	if c.masterConn != nil {
+		_postgres.RecordWrite(ctx)
 		return c.masterConn.UpsertModel(ctx, model, primaryKeys...)
 	}
 	return fmt.Errorf("master connection not established")
@@ -253,11 +424,82 @@ func (c *MasterSlaveConnection) BatchInsertModel(ctx context.Context, models []a
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	if c.masterConn != nil {
+		_postgres.RecordWrite(ctx)
 		return c.masterConn.BatchInsertModel(ctx, models, batchSize)
 	}
 	return fmt.Errorf("master connection not established")
 }
 
+// CopyModels bulk loads models into the master database
+func (c *MasterSlaveConnection) CopyModels(ctx context.Context, source iter.Seq[any], opts _postgres.CopyOptions) (*_postgres.CopyResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.masterConn != nil {
+		_postgres.RecordWrite(ctx)
+		return c.masterConn.CopyModels(ctx, source, opts)
+	}
+	return nil, fmt.Errorf("master connection not established")
+}
+
+// AdvisoryLock waits until the session lock of name is obtained on the master
+func (c *MasterSlaveConnection) AdvisoryLock(ctx context.Context, name string) (*AdvisoryLock, error) {
+	masterConn, err := c.primary()
+	if err != nil {
+		return nil, err
+	}
+	return masterConn.AdvisoryLock(ctx, name)
+}
+
+// TryAdvisoryLock obtains the session lock of name on the master without waiting
+func (c *MasterSlaveConnection) TryAdvisoryLock(ctx context.Context, name string) (*AdvisoryLock, error) {
+	masterConn, err := c.primary()
+	if err != nil {
+		return nil, err
+	}
+	return masterConn.TryAdvisoryLock(ctx, name)
+}
+
+// AdvisoryXactLock waits until the transaction lock of name is obtained in the transaction
+// carried by ctx
+func (c *MasterSlaveConnection) AdvisoryXactLock(ctx context.Context, name string) error {
+	masterConn, err := c.primary()
+	if err != nil {
+		return err
+	}
+	return masterConn.AdvisoryXactLock(ctx, name)
+}
+
+// TryAdvisoryXactLock obtains the transaction lock of name in the transaction carried by ctx
+// without waiting
+func (c *MasterSlaveConnection) TryAdvisoryXactLock(ctx context.Context, name string) error {
+	masterConn, err := c.primary()
+	if err != nil {
+		return err
+	}
+	return masterConn.TryAdvisoryXactLock(ctx, name)
+}
+
+// WithAdvisoryXactLock runs fn in a transaction on the master holding the lock of name
+func (c *MasterSlaveConnection) WithAdvisoryXactLock(ctx context.Context, name string, fn _postgres.TxFunc) error {
+	masterConn, err := c.primary()
+	if err != nil {
+		return err
+	}
+	_postgres.RecordWrite(ctx)
+	return masterConn.WithAdvisoryXactLock(ctx, name, fn)
+}
+
+// primary returns the connection serving writes. The lock is not held by the caller, so a
+// blocking call does not delay failover.
+func (c *MasterSlaveConnection) primary() (*Connection, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.masterConn == nil {
+		return nil, fmt.Errorf("master connection not established")
+	}
+	return c.masterConn, nil
+}
+
 // startHealthCheck starts a periodic health check
 func (c *MasterSlaveConnection) startHealthCheck() {
 	c.healthTicker = time.NewTicker(c.config.HealthCheckInterval)
@@ -273,48 +515,155 @@ func (c *MasterSlaveConnection) startHealthCheck() {
 	}()
 }
 
-// checkHealth checks the health of master and slave connections
+// checkHealth checks the health of the master connection. With auto failover it follows
+// the primary across the configured hosts.
 func (c *MasterSlaveConnection) checkHealth() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if c.config.AutoFailover {
+		if err := c.RefreshPrimary(ctx); err != nil {
+			fmt.Printf("Failed to find the primary: %v\n", err)
+		}
+		return
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Check master health
-	masterHealthy := c.masterConn != nil && c.masterConn.IsHealthy(ctx)
-	slaveHealthy := c.slaveConn != nil && c.slaveConn.IsHealthy(ctx)
-
-	// If master is down but slave is healthy and auto-failover is enabled
-	if !masterHealthy && slaveHealthy && c.config.AutoFailover {
-		// Promote slave to master
-		c.role = "slave" // This connection is now operating in slave mode
-		fmt.Println("Master connection is down, operating in slave-only mode")
-	} else if masterHealthy && !slaveHealthy && c.slaveConn != nil {
-		// Try to reconnect to slave
-		fmt.Println("Slave connection is down, attempting to reconnect")
-		c.attemptSlaveReconnect(ctx)
-	} else if !masterHealthy && !slaveHealthy {
-		// Both connections are down, try to reconnect to both
-		fmt.Println("Both master and slave connections are down, attempting to reconnect")
+	// Replica health is tracked by the replica checks
+	if c.masterConn == nil || !c.masterConn.IsHealthy(ctx) {
+		fmt.Println("Master connection is down, attempting to reconnect")
 		c.attemptMasterReconnect(ctx)
-		if c.slaveConn != nil {
-			c.attemptSlaveReconnect(ctx)
+	}
+}
+
+// RefreshPrimary queries pg_is_in_recovery() on the master and the replicas and redirects
+// writes to the current primary. Without a primary the connection only serves reads.
+func (c *MasterSlaveConnection) RefreshPrimary(ctx context.Context) error {
+	c.mu.RLock()
+	current, currentName := c.masterConn, c.primaryName
+	connected := c.replicas != nil
+	c.mu.RUnlock()
+
+	if !connected {
+		return fmt.Errorf("master-slave connection not established")
+	}
+
+	// Fast path: the primary has not changed
+	if current != nil {
+		if isPrimary, err := _postgres.IsPrimary(ctx, current); err == nil && isPrimary {
+			c.setPrimary(current, currentName)
+			return nil
 		}
 	}
+
+	c.connectMasterNode(ctx)
+	if primary, ok := _postgres.DetectPrimary(ctx, c.primaryCandidates()); ok {
+		c.setPrimary(primary.Conn, primary.Name)
+		return nil
+	}
+
+	c.setPrimary(nil, "")
+	return fmt.Errorf("none of the configured hosts is a primary")
+}
+
+// PrimaryName returns the name of the node serving writes, empty when there is none
+func (c *MasterSlaveConnection) PrimaryName() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.primaryName
+}
+
+// OnRoleChange registers fn to be called when the node serving writes changes
+func (c *MasterSlaveConnection) OnRoleChange(fn func(_postgres.RoleChange)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onRoleChange = fn
+}
+
+// primaryCandidates returns the connected nodes that may be the primary, the configured
+// master first
+func (c *MasterSlaveConnection) primaryCandidates() []_postgres.ReplicaConn[*Connection] {
+	c.mu.RLock()
+	masterNode := c.masterNode
+	c.mu.RUnlock()
+
+	var candidates []_postgres.ReplicaConn[*Connection]
+	if masterNode != nil {
+		candidates = append(candidates, _postgres.ReplicaConn[*Connection]{
+			Name: _postgres.MasterNodeName,
+			Conn: masterNode,
+		})
+	}
+	return append(candidates, c.replicas.Connections()...)
+}
+
+// connectMasterNode connects the configured master if it could not be reached so far
+func (c *MasterSlaveConnection) connectMasterNode(ctx context.Context) {
+	c.mu.RLock()
+	connected := c.masterNode != nil
+	c.mu.RUnlock()
+
+	if connected {
+		return
+	}
+
+	conn := c.newMasterConnection()
+	if err := conn.Connect(ctx); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.masterNode != nil {
+		conn.Close()
+		return
+	}
+	c.masterNode = conn
+	fmt.Println("Connected to master")
+}
+
+// setPrimary redirects writes to conn and emits a role change when the primary changed
+func (c *MasterSlaveConnection) setPrimary(conn *Connection, name string) {
+	c.mu.Lock()
+	previous := c.primaryName
+	c.masterConn = conn
+	c.primaryName = name
+	if conn != nil {
+		c.role = "master"
+	} else {
+		c.role = "slave"
+	}
+	onRoleChange := c.onRoleChange
+	c.mu.Unlock()
+
+	if name == previous {
+		return
+	}
+	if conn == nil {
+		fmt.Println("No primary is reachable, operating in slave-only mode")
+	} else {
+		fmt.Printf("Primary is now %s\n", name)
+	}
+	if onRoleChange != nil {
+		onRoleChange(_postgres.RoleChange{Previous: previous, Current: name, Time: time.Now()})
+	}
 }
 
 // attemptMasterReconnect attempts to reconnect to the master
 func (c *MasterSlaveConnection) attemptMasterReconnect(ctx context.Context) {
 	for i := 0; i < c.config.FailoverRetries; i++ {
 		// Create a new connection
-		conn := NewConnection(c.config.Master)
+		conn := c.newMasterConnection()
 		if err := conn.Connect(ctx); err == nil {
 			// Successfully reconnected
-			if c.masterConn != nil {
-				c.masterConn.Close()
+			if c.masterNode != nil {
+				c.masterNode.Close()
 			}
+			c.masterNode = conn
 			c.masterConn = conn
+			c.primaryName = _postgres.MasterNodeName
 			c.role = "master"
 			fmt.Println("Successfully reconnected to master")
 			return
@@ -323,22 +672,3 @@ func (c *MasterSlaveConnection) attemptMasterReconnect(ctx context.Context) {
 	}
 	fmt.Println("Failed to reconnect to master after multiple attempts")
 }
-
-// attemptSlaveReconnect attempts to reconnect to the slave
-func (c *MasterSlaveConnection) attemptSlaveReconnect(ctx context.Context) {
-	for i := 0; i < c.config.FailoverRetries; i++ {
-		// Create a new connection
-		conn := NewConnection(c.config.Slave)
-		if err := conn.Connect(ctx); err == nil {
-			// Successfully reconnected
-			if c.slaveConn != nil {
-				c.slaveConn.Close()
-			}
-			c.slaveConn = conn
-			fmt.Println("Successfully reconnected to slave")
-			return
-		}
-		time.Sleep(c.config.FailoverInterval)
-	}
-	fmt.Println("Failed to reconnect to slave after multiple attempts")
-}
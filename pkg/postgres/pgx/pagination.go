@@ -0,0 +1,24 @@
+package _pgx_postgres
+
+import (
+	"context"
+
+	_postgres "go-libs/pkg/postgres"
+)
+
+// Paginate reads a keyset page of T, see _postgres.Paginate. The query uses ? placeholders.
+func Paginate[T any](ctx context.Context, db _postgres.DatabaseClient, q *_postgres.PageQuery, req _postgres.PageRequest) (*_postgres.Page[T], error) {
+	return _postgres.Paginate[T](ctx, db, bindPlaceholders, q, req)
+}
+
+// PaginateOffset reads a page of T by page number with the total count, see
+// _postgres.PaginateOffset. The query uses ? placeholders.
+func PaginateOffset[T any](ctx context.Context, db _postgres.DatabaseClient, q *_postgres.PageQuery, req _postgres.PageRequest) (*_postgres.Page[T], error) {
+	return _postgres.PaginateOffset[T](ctx, db, bindPlaceholders, q, req)
+}
+
+// bindPlaceholders replaces ? placeholders with $n
+func bindPlaceholders(sql string) string {
+	sql, _ = rebind(sql, 0)
+	return sql
+}
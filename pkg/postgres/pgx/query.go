@@ -0,0 +1,195 @@
+package _pgx_postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	_postgres "go-libs/pkg/postgres"
+)
+
+// Query describes the filters, ordering and paging of a Find. Conditions use ? as the
+// placeholder and are combined with AND.
+//
+//	q := NewQuery().Where("status = ?", "active").Where("age >= ?", 18).OrderBy("created_at DESC").Limit(20)
+type Query struct {
+	conditions  []condition
+	orderBy     []string
+	limit       int
+	offset      int
+	withDeleted bool
+}
+
+type condition struct {
+	sql  string
+	args []any
+}
+
+// NewQuery creates an empty query matching every row
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Where adds a condition, for example Where("email = ?", email)
+func (q *Query) Where(sql string, args ...any) *Query {
+	q.conditions = append(q.conditions, condition{sql: sql, args: args})
+	return q
+}
+
+// WhereEq adds a column = value condition
+func (q *Query) WhereEq(column string, value any) *Query {
+	return q.Where(column+" = ?", value)
+}
+
+// WhereIn adds a column = ANY(values) condition
+func (q *Query) WhereIn(column string, values any) *Query {
+	return q.Where(column+" = ANY(?)", values)
+}
+
+// OrderBy adds a sort column with an optional direction, for example "created_at DESC"
+func (q *Query) OrderBy(order string) *Query {
+	q.orderBy = append(q.orderBy, order)
+	return q
+}
+
+// Limit caps the number of rows returned
+func (q *Query) Limit(limit int) *Query {
+	q.limit = limit
+	return q
+}
+
+// Offset skips the first rows
+func (q *Query) Offset(offset int) *Query {
+	q.offset = offset
+	return q
+}
+
+// WithDeleted includes soft deleted rows
+func (q *Query) WithDeleted() *Query {
+	q.withDeleted = true
+	return q
+}
+
+// whereClause renders the conditions, numbering placeholders after offset. Soft deleted
+// rows are excluded unless WithDeleted was called.
+func (q *Query) whereClause(model *_postgres.Model, offset int) (string, []any, error) {
+	var parts []string
+	var args []any
+
+	if q != nil {
+		for _, c := range q.conditions {
+			sql, n := rebind(c.sql, offset+len(args))
+			if n != len(c.args) {
+				return "", nil, fmt.Errorf("condition %q has %d placeholders but %d arguments", c.sql, n, len(c.args))
+			}
+			parts = append(parts, "("+sql+")")
+			args = append(args, c.args...)
+		}
+	}
+
+	if model.SoftDelete != nil && (q == nil || !q.withDeleted) {
+		parts = append(parts, model.SoftDelete.Column+" IS NULL")
+	}
+
+	if len(parts) == 0 {
+		return "", nil, nil
+	}
+	return " WHERE " + strings.Join(parts, " AND "), args, nil
+}
+
+// tailClause renders ORDER BY, LIMIT and OFFSET. Sort columns must belong to the model.
+func (q *Query) tailClause(model *_postgres.Model) (string, error) {
+	if q == nil {
+		return "", nil
+	}
+
+	var sb strings.Builder
+
+	if len(q.orderBy) > 0 {
+		orders := make([]string, len(q.orderBy))
+		for i, order := range q.orderBy {
+			fields := strings.Fields(order)
+			if len(fields) == 0 || len(fields) > 2 {
+				return "", fmt.Errorf("invalid order %q", order)
+			}
+			if _, ok := model.FieldByColumn(fields[0]); !ok {
+				return "", fmt.Errorf("unknown order column %q", fields[0])
+			}
+			if len(fields) == 2 {
+				direction := strings.ToUpper(fields[1])
+				if direction != "ASC" && direction != "DESC" {
+					return "", fmt.Errorf("invalid order direction %q", fields[1])
+				}
+				orders[i] = fields[0] + " " + direction
+			} else {
+				orders[i] = fields[0]
+			}
+		}
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(orders, ", "))
+	}
+
+	if q.limit > 0 {
+		sb.WriteString(" LIMIT ")
+		sb.WriteString(strconv.Itoa(q.limit))
+	}
+	if q.offset > 0 {
+		sb.WriteString(" OFFSET ")
+		sb.WriteString(strconv.Itoa(q.offset))
+	}
+
+	return sb.String(), nil
+}
+
+// rebind replaces ? placeholders with $n starting after offset and returns the number of
+// placeholders replaced. Quoted strings and identifiers and comments are left as they are,
+// ?? is a literal ? such as the jsonb key operator, and the jsonb ?| and ?& operators are
+// kept.
+func rebind(sql string, offset int) (string, int) {
+	var sb strings.Builder
+	n := 0
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"':
+			// Doubled quotes inside are read as two adjacent quoted parts
+			end := strings.IndexByte(sql[i+1:], c)
+			if end < 0 {
+				end = len(sql) - i - 2
+			}
+			sb.WriteString(sql[i : i+end+2])
+			i += end + 1
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			sb.WriteString(sql[i : i+end])
+			i += end - 1
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i
+			} else {
+				end += 4
+			}
+			sb.WriteString(sql[i : i+end])
+			i += end - 1
+		case c == '?' && strings.HasPrefix(sql[i:], "??"):
+			sb.WriteByte('?')
+			i++
+		case c == '?' && (strings.HasPrefix(sql[i:], "?|") || strings.HasPrefix(sql[i:], "?&")):
+			sb.WriteString(sql[i : i+2])
+			i++
+		case c == '?':
+			n++
+			sb.WriteString("$")
+			sb.WriteString(strconv.Itoa(offset + n))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), n
+}
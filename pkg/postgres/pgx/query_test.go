@@ -0,0 +1,120 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	_postgres "go-libs/pkg/postgres"
+)
+
+type queryUser struct {
+	ID        int64      `db:"id,pk"`
+	Status    string     `db:"status"`
+	CreatedAt time.Time  `db:"created_at,readonly"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name  string
+		sql   string
+		want  string
+		wantN int
+	}{
+		{"placeholders", "a = ? AND c = ?", "a = $3 AND c = $4", 2},
+		{"string literal", "a = ? AND b = 'it''s ?' AND c = ?", "a = $3 AND b = 'it''s ?' AND c = $4", 2},
+		{"quoted identifier", `"what?" = ?`, `"what?" = $3`, 1},
+		{"line comment", "a = ? -- why?\nAND c = ?", "a = $3 -- why?\nAND c = $4", 2},
+		{"block comment", "a = ? /* why? */ AND c = ?", "a = $3 /* why? */ AND c = $4", 2},
+		{"escaped", "data ?? ? AND c = ?", "data ? $3 AND c = $4", 2},
+		{"jsonb any and all", "data ?| ? AND data ?& ?", "data ?| $3 AND data ?& $4", 2},
+		{"unterminated string", "a = ? AND b = 'x?", "a = $3 AND b = 'x?", 1},
+		{"unterminated comment", "a = ? /* x?", "a = $3 /* x?", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n := rebind(tt.sql, 2)
+			if got != tt.want || n != tt.wantN {
+				t.Errorf("rebind() = %q, %d, want %q, %d", got, n, tt.want, tt.wantN)
+			}
+		})
+	}
+}
+
+func TestQueryClauses(t *testing.T) {
+	model, err := _postgres.GetModel(queryUser{})
+	if err != nil {
+		t.Fatalf("GetModel() error = %v", err)
+	}
+
+	q := NewQuery().WhereEq("status", "active").Where("created_at >= ?", time.Time{}).OrderBy("created_at desc").OrderBy("id").Limit(10).Offset(20)
+
+	where, args, err := q.whereClause(model, 0)
+	if err != nil {
+		t.Fatalf("whereClause() error = %v", err)
+	}
+	if want := " WHERE (status = $1) AND (created_at >= $2) AND deleted_at IS NULL"; where != want {
+		t.Errorf("whereClause() = %q, want %q", where, want)
+	}
+	if len(args) != 2 {
+		t.Errorf("whereClause() args = %v, want 2", args)
+	}
+
+	tail, err := q.tailClause(model)
+	if err != nil {
+		t.Fatalf("tailClause() error = %v", err)
+	}
+	if want := " ORDER BY created_at DESC, id LIMIT 10 OFFSET 20"; tail != want {
+		t.Errorf("tailClause() = %q, want %q", tail, want)
+	}
+
+	where, _, _ = NewQuery().WithDeleted().whereClause(model, 0)
+	if where != "" {
+		t.Errorf("whereClause() with deleted = %q, want empty", where)
+	}
+}
+
+func TestQueryRejectsInvalidInput(t *testing.T) {
+	model, _ := _postgres.GetModel(queryUser{})
+
+	if _, _, err := NewQuery().Where("status = ? AND id = ?", "active").whereClause(model, 0); err == nil {
+		t.Errorf("whereClause() error = nil, want placeholder mismatch")
+	}
+	if _, err := NewQuery().OrderBy("status; DROP TABLE users").tailClause(model); err == nil {
+		t.Errorf("tailClause() error = nil, want invalid order")
+	}
+	if _, err := NewQuery().OrderBy("password").tailClause(model); err == nil {
+		t.Errorf("tailClause() error = nil, want unknown column")
+	}
+}
+
+// noQuerier panics if the repository reaches the database
+type noQuerier struct {
+	Querier
+}
+
+func TestRepositoryRejectsNilEntity(t *testing.T) {
+	repo, err := NewRepository[queryUser](noQuerier{})
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"insert", func() error { return repo.Insert(context.Background(), nil) }},
+		{"update", func() error { return repo.Update(context.Background(), nil) }},
+		{"update columns", func() error { return repo.Update(context.Background(), nil, "status") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); err == nil {
+				t.Errorf("%s with nil entity error = nil, want error", tt.name)
+			}
+		})
+	}
+}
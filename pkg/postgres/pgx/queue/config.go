@@ -0,0 +1,102 @@
+package _queue_postgres
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// identifierPattern matches a table name, optionally schema-qualified
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Config holds the configuration for a job queue
+type Config struct {
+	// Name identifies the queue, queues with different names share the table
+	Name string `json:"name" yaml:"name"`
+	// Table stores the jobs, it may be schema-qualified
+	Table string `json:"table" yaml:"table"`
+	// VisibilityTimeout is how long a claimed job stays locked without a heartbeat before
+	// the reaper hands it out again
+	VisibilityTimeout time.Duration `json:"visibility_timeout" yaml:"visibility_timeout"`
+	// DefaultMaxRetries is used for jobs that do not set MaxRetries
+	DefaultMaxRetries int `json:"default_max_retries" yaml:"default_max_retries"`
+	// RetryBaseDelay is the first retry delay, it doubles on every attempt
+	RetryBaseDelay time.Duration `json:"retry_base_delay" yaml:"retry_base_delay"`
+	// RetryMaxDelay caps the retry delay
+	RetryMaxDelay time.Duration `json:"retry_max_delay" yaml:"retry_max_delay"`
+}
+
+// DefaultConfig returns a queue configuration with sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Name:              "default",
+		Table:             "jobs",
+		VisibilityTimeout: 5 * time.Minute,
+		DefaultMaxRetries: 3,
+		RetryBaseDelay:    10 * time.Second,
+		RetryMaxDelay:     time.Hour,
+	}
+}
+
+// Validate checks if the queue configuration is valid
+func (c *Config) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	if !identifierPattern.MatchString(c.Table) {
+		return errors.New("table must be a valid identifier")
+	}
+	if c.VisibilityTimeout <= 0 {
+		return errors.New("visibility timeout must be greater than 0")
+	}
+	if c.DefaultMaxRetries < 0 {
+		return errors.New("default max retries must be greater than or equal to 0")
+	}
+	if c.RetryBaseDelay <= 0 {
+		return errors.New("retry base delay must be greater than 0")
+	}
+	if c.RetryMaxDelay < c.RetryBaseDelay {
+		return errors.New("retry max delay must be greater than or equal to retry base delay")
+	}
+	return nil
+}
+
+// WorkerConfig holds the configuration for a worker pool
+type WorkerConfig struct {
+	// Concurrency is the number of jobs processed at the same time
+	Concurrency int `json:"concurrency" yaml:"concurrency"`
+	// PollInterval is how often the queue is polled when it is idle
+	PollInterval time.Duration `json:"poll_interval" yaml:"poll_interval"`
+	// ReapInterval is how often jobs of dead workers are handed out again (0 disables
+	// reaping in this worker)
+	ReapInterval time.Duration `json:"reap_interval" yaml:"reap_interval"`
+	// ShutdownTimeout bounds how long Stop waits for running jobs
+	ShutdownTimeout time.Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+}
+
+// DefaultWorkerConfig returns a worker configuration with sensible defaults
+func DefaultWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		Concurrency:     10,
+		PollInterval:    time.Second,
+		ReapInterval:    time.Minute,
+		ShutdownTimeout: 30 * time.Second,
+	}
+}
+
+// Validate checks if the worker configuration is valid
+func (c *WorkerConfig) Validate() error {
+	if c.Concurrency <= 0 {
+		return errors.New("concurrency must be greater than 0")
+	}
+	if c.PollInterval <= 0 {
+		return errors.New("poll interval must be greater than 0")
+	}
+	if c.ReapInterval < 0 {
+		return errors.New("reap interval must be greater than or equal to 0")
+	}
+	if c.ShutdownTimeout < 0 {
+		return errors.New("shutdown timeout must be greater than or equal to 0")
+	}
+	return nil
+}
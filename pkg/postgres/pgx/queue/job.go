@@ -0,0 +1,54 @@
+package _queue_postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NoRetry disables retries for a job when used as MaxRetries
+const NoRetry = -1
+
+// Job is a unit of work stored in the queue table
+type Job struct {
+	ID      string `db:"id" json:"id"`
+	Type    string `db:"type" json:"type"`
+	Payload []byte `db:"payload" json:"payload"`
+	// Priority orders due jobs, higher first
+	Priority int `db:"priority" json:"priority"`
+	// RunAt is when the job becomes due, zero means now
+	RunAt time.Time `db:"run_at" json:"run_at"`
+	// MaxRetries is how many times a failed job is retried, 0 uses the queue default
+	// and NoRetry disables retries
+	MaxRetries int `db:"max_retries" json:"max_retries"`
+	// UniqueKey prevents enqueueing another job with the same key until this one completes
+	// or is dead
+	UniqueKey  string    `db:"unique_key" json:"unique_key,omitempty"`
+	EnqueuedAt time.Time `db:"enqueued_at" json:"enqueued_at"`
+
+	// Attempts is how many times the job has been claimed, including the current one
+	Attempts int `db:"attempts" json:"-"`
+	// LastError is the error of the previous attempt
+	LastError string `db:"last_error" json:"-"`
+}
+
+// NewJob creates a job with a JSON encoded payload
+func NewJob(jobType string, payload any) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return &Job{
+		ID:      uuid.New().String(),
+		Type:    jobType,
+		Payload: data,
+	}, nil
+}
+
+// Decode unmarshals the JSON payload into v
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
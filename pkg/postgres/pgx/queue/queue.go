@@ -0,0 +1,329 @@
+package _queue_postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	_postgres "go-libs/pkg/postgres"
+	_queue "go-libs/pkg/queue"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrDuplicateJob is returned by Enqueue when a job with the same unique key exists
+	ErrDuplicateJob = errors.New("queue: duplicate job")
+	// ErrJobNotClaimed is returned when completing, retrying or killing a job that is no
+	// longer claimed by this attempt, for example after the reaper handed it out again
+	ErrJobNotClaimed = _queue.ErrJobNotClaimed
+)
+
+// Stats contains the number of jobs in each state
+type Stats struct {
+	Scheduled int64
+	InFlight  int64
+	Dead      int64
+}
+
+// querier runs the queue statements, it is implemented by clients and transactions
+type querier interface {
+	QueryRow(ctx context.Context, query string, args ...any) _postgres.Row
+}
+
+// Queue is a durable job queue stored in a PostgreSQL table. Workers claim jobs with
+// FOR UPDATE SKIP LOCKED, so any number of them can poll the same queue. Statements use
+// pgx placeholders.
+type Queue struct {
+	db     _postgres.DatabaseClient
+	config Config
+}
+
+// NewQueue creates a new job queue
+func NewQueue(db _postgres.DatabaseClient, config Config) (*Queue, error) {
+	if db == nil {
+		return nil, errors.New("database client is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid queue config: %w", err)
+	}
+
+	return &Queue{
+		db:     db,
+		config: config,
+	}, nil
+}
+
+// Schema returns the statements creating the queue table and its indexes, for use in a
+// migration
+func (q *Queue) Schema() string {
+	table := q.config.Table
+	prefix := table[strings.LastIndex(table, ".")+1:]
+
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	id           text PRIMARY KEY,
+	queue        text NOT NULL,
+	type         text NOT NULL,
+	payload      jsonb NOT NULL,
+	priority     integer NOT NULL DEFAULT 0,
+	run_at       timestamptz NOT NULL,
+	max_retries  integer NOT NULL,
+	attempts     integer NOT NULL DEFAULT 0,
+	status       text NOT NULL DEFAULT 'scheduled',
+	unique_key   text,
+	last_error   text,
+	locked_until timestamptz,
+	enqueued_at  timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[2]s_due_idx ON %[1]s (queue, priority DESC, run_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS %[2]s_locked_idx ON %[1]s (queue, locked_until) WHERE status = 'running';
+CREATE UNIQUE INDEX IF NOT EXISTS %[2]s_unique_idx ON %[1]s (queue, unique_key) WHERE unique_key IS NOT NULL AND status <> 'dead';`,
+		table, prefix)
+}
+
+// CreateSchema creates the queue table and its indexes if they do not exist
+func (q *Queue) CreateSchema(ctx context.Context) error {
+	if err := q.db.Exec(ctx, q.Schema()); err != nil {
+		return fmt.Errorf("failed to create queue table: %w", err)
+	}
+	return nil
+}
+
+// Enqueue stores the job and schedules it at RunAt. Called with a context carrying a
+// transaction of the client (see WithTx), the job is enqueued in that transaction. If the
+// job has a unique key that is already taken, the ID of the existing job is returned with
+// ErrDuplicateJob.
+func (q *Queue) Enqueue(ctx context.Context, job *Job) (string, error) {
+	return q.enqueue(ctx, q.db, job)
+}
+
+// EnqueueTx enqueues the job in tx, so it only becomes visible to workers if tx commits
+func (q *Queue) EnqueueTx(ctx context.Context, tx _postgres.Transaction, job *Job) (string, error) {
+	return q.enqueue(ctx, tx, job)
+}
+
+// EnqueueIn schedules the job to run after delay
+func (q *Queue) EnqueueIn(ctx context.Context, job *Job, delay time.Duration) (string, error) {
+	job.RunAt = time.Now().Add(delay)
+	return q.Enqueue(ctx, job)
+}
+
+func (q *Queue) enqueue(ctx context.Context, db querier, job *Job) (string, error) {
+	if job.Type == "" {
+		return "", errors.New("job type is required")
+	}
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	payload := job.Payload
+	if len(payload) == 0 {
+		payload = []byte("null")
+	}
+	var runAt any
+	if !job.RunAt.IsZero() {
+		runAt = job.RunAt
+	}
+	maxRetries := job.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = q.config.DefaultMaxRetries
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (id, queue, type, payload, priority, run_at, max_retries, unique_key, enqueued_at)
+VALUES ($1, $2, $3, $4, $5, COALESCE($6::timestamptz, now()), $7, NULLIF($8, ''), now())
+ON CONFLICT (queue, unique_key) WHERE unique_key IS NOT NULL AND status <> 'dead' DO NOTHING
+RETURNING run_at, enqueued_at`, q.config.Table)
+
+	err := db.QueryRow(ctx, query,
+		job.ID, q.config.Name, job.Type, payload, job.Priority, runAt, maxRetries, job.UniqueKey,
+	).Scan(&job.RunAt, &job.EnqueuedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return q.existing(ctx, db, job.UniqueKey)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	return job.ID, nil
+}
+
+// existing returns the ID of the job holding a unique key with ErrDuplicateJob
+func (q *Queue) existing(ctx context.Context, db querier, uniqueKey string) (string, error) {
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE queue = $1 AND unique_key = $2 AND status <> 'dead'`, q.config.Table)
+
+	var id string
+	if err := db.QueryRow(ctx, query, q.config.Name, uniqueKey).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to find duplicate job: %w", err)
+	}
+	return id, ErrDuplicateJob
+}
+
+// Claim takes up to limit due jobs, highest priority first. Rows locked by other workers
+// are skipped rather than waited for. Claimed jobs stay locked for the visibility timeout
+// and are handed out again by the reaper if they are not completed, retried or extended
+// in time.
+func (q *Queue) Claim(ctx context.Context, limit int) ([]*Job, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`UPDATE %[1]s SET status = 'running', attempts = attempts + 1,
+	locked_until = now() + $3::float8 * interval '1 millisecond'
+WHERE id IN (
+	SELECT id FROM %[1]s
+	WHERE queue = $1 AND status = 'scheduled' AND run_at <= now()
+	ORDER BY priority DESC, run_at
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, type, payload, priority, run_at, max_retries, COALESCE(unique_key, '') AS unique_key,
+	enqueued_at, attempts, COALESCE(last_error, '') AS last_error`, q.config.Table)
+
+	rows, err := q.db.Query(ctx, query, q.config.Name, limit, q.config.VisibilityTimeout.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+	claimed, err := _postgres.ScanAll[Job](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+
+	jobs := make([]*Job, len(claimed))
+	for i := range claimed {
+		jobs[i] = &claimed[i]
+	}
+
+	// RETURNING does not keep the order of the subquery
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].Priority != jobs[j].Priority {
+			return jobs[i].Priority > jobs[j].Priority
+		}
+		return jobs[i].RunAt.Before(jobs[j].RunAt)
+	})
+
+	return jobs, nil
+}
+
+// Complete removes a finished job from the queue
+func (q *Queue) Complete(ctx context.Context, job *Job) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND status = 'running' AND attempts = $2 RETURNING id`, q.config.Table)
+	return q.update(ctx, "complete", query, job.ID, job.Attempts)
+}
+
+// Retry reschedules a failed job with exponential backoff, or marks it dead when it has
+// no retries left
+func (q *Queue) Retry(ctx context.Context, job *Job, cause error) error {
+	if !q.canRetry(job) {
+		return q.Kill(ctx, job, cause)
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET status = 'scheduled', run_at = now() + $3::float8 * interval '1 millisecond',
+	locked_until = NULL, last_error = $4
+WHERE id = $1 AND status = 'running' AND attempts = $2 RETURNING id`, q.config.Table)
+	return q.update(ctx, "retry", query, job.ID, job.Attempts, q.backoff(job.Attempts).Milliseconds(), errorMessage(cause))
+}
+
+// Kill marks a claimed job dead. Dead jobs stay in the table for inspection and release
+// their unique key.
+func (q *Queue) Kill(ctx context.Context, job *Job, cause error) error {
+	query := fmt.Sprintf(`UPDATE %s SET status = 'dead', locked_until = NULL, last_error = $3
+WHERE id = $1 AND status = 'running' AND attempts = $2 RETURNING id`, q.config.Table)
+	return q.update(ctx, "kill", query, job.ID, job.Attempts, errorMessage(cause))
+}
+
+// Extend pushes back the lock deadline of a claimed job
+func (q *Queue) Extend(ctx context.Context, job *Job) error {
+	query := fmt.Sprintf(`UPDATE %s SET locked_until = now() + $3::float8 * interval '1 millisecond'
+WHERE id = $1 AND status = 'running' AND attempts = $2 RETURNING id`, q.config.Table)
+	return q.update(ctx, "extend", query, job.ID, job.Attempts, q.config.VisibilityTimeout.Milliseconds())
+}
+
+// Reap hands out again the jobs whose lock expired because their worker died, or marks
+// them dead when they have no attempts left. It returns the number of jobs reaped.
+func (q *Queue) Reap(ctx context.Context) (int64, error) {
+	query := fmt.Sprintf(`WITH reaped AS (
+	UPDATE %s SET
+		status = CASE WHEN attempts >= GREATEST(max_retries, 0) + 1 THEN 'dead' ELSE 'scheduled' END,
+		run_at = now(), locked_until = NULL, last_error = 'worker lost'
+	WHERE queue = $1 AND status = 'running' AND locked_until < now()
+	RETURNING 1
+)
+SELECT count(*) FROM reaped`, q.config.Table)
+
+	var n int64
+	if err := q.db.QueryRow(ctx, query, q.config.Name).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to reap jobs: %w", err)
+	}
+	return n, nil
+}
+
+// Stats returns the number of scheduled, in-flight and dead jobs
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	query := fmt.Sprintf(`SELECT
+	count(*) FILTER (WHERE status = 'scheduled'),
+	count(*) FILTER (WHERE status = 'running'),
+	count(*) FILTER (WHERE status = 'dead')
+FROM %s WHERE queue = $1`, q.config.Table)
+
+	var stats Stats
+	if err := q.db.QueryRow(ctx, query, q.config.Name).Scan(&stats.Scheduled, &stats.InFlight, &stats.Dead); err != nil {
+		return Stats{}, fmt.Errorf("failed to get queue stats: %w", err)
+	}
+	return stats, nil
+}
+
+// update runs a statement returning the id of the claimed job it changed
+func (q *Queue) update(ctx context.Context, action, query string, args ...any) error {
+	var id string
+	err := q.db.QueryRow(ctx, query, args...).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrJobNotClaimed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s job: %w", action, err)
+	}
+	return nil
+}
+
+// maxAttempts returns how many times a job may run in total
+func (q *Queue) maxAttempts(job *Job) int {
+	maxRetries := job.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = q.config.DefaultMaxRetries
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return maxRetries + 1
+}
+
+// canRetry reports whether the job has retries left
+func (q *Queue) canRetry(job *Job) bool {
+	return job.Attempts < q.maxAttempts(job)
+}
+
+// backoff returns the delay before the next attempt: the base delay doubled for every
+// previous attempt, capped at the max delay, with equal jitter
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.config.RetryMaxDelay
+	if attempts < 32 {
+		if d := q.config.RetryBaseDelay << (max(attempts, 1) - 1); d > 0 && d < delay {
+			delay = d
+		}
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,239 @@
+package _queue_postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	_postgres "go-libs/pkg/postgres"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeRow returns err or sets values into the scan destinations
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, v := range r.values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+// fakeDB records the statements and serves one row per QueryRow call
+type fakeDB struct {
+	_postgres.DatabaseClient
+	queries []string
+	args    [][]any
+	rows    []fakeRow
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, query string, args ...any) _postgres.Row {
+	db.queries = append(db.queries, query)
+	db.args = append(db.args, args)
+	row := db.rows[0]
+	db.rows = db.rows[1:]
+	return row
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "default", modify: func(c *Config) {}},
+		{name: "schema-qualified table", modify: func(c *Config) { c.Table = "app.jobs" }},
+		{name: "invalid table", modify: func(c *Config) { c.Table = "jobs; DROP TABLE users" }, wantErr: true},
+		{name: "missing name", modify: func(c *Config) { c.Name = "" }, wantErr: true},
+		{name: "zero visibility timeout", modify: func(c *Config) { c.VisibilityTimeout = 0 }, wantErr: true},
+		{name: "max delay below base delay", modify: func(c *Config) { c.RetryMaxDelay = time.Second }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(&config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		job      *Job
+		rows     []fakeRow
+		wantID   string
+		wantErr  error
+		wantArgs map[int]any
+	}{
+		{
+			name:     "new job",
+			job:      &Job{ID: "job-1", Type: "email", Priority: 5},
+			rows:     []fakeRow{{values: []any{now, now}}},
+			wantID:   "job-1",
+			wantArgs: map[int]any{1: "default", 4: 5, 5: nil, 6: 3},
+		},
+		{
+			// The insert skips the conflicting row and the existing job is looked up
+			name:     "duplicate",
+			job:      &Job{Type: "report", UniqueKey: "report:2024-05", MaxRetries: NoRetry},
+			rows:     []fakeRow{{err: pgx.ErrNoRows}, {values: []any{"existing"}}},
+			wantID:   "existing",
+			wantErr:  ErrDuplicateJob,
+			wantArgs: map[int]any{6: NoRetry},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{rows: tt.rows}
+			queue, err := NewQueue(db, DefaultConfig())
+			if err != nil {
+				t.Fatalf("NewQueue() error = %v", err)
+			}
+
+			id, err := queue.Enqueue(context.Background(), tt.job)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Enqueue() error = %v, want %v", err, tt.wantErr)
+			}
+			if id != tt.wantID {
+				t.Errorf("Enqueue() = %v, want %v", id, tt.wantID)
+			}
+			if !strings.Contains(db.queries[0], "INSERT INTO jobs") {
+				t.Errorf("Enqueue() query = %v, want an insert into jobs", db.queries[0])
+			}
+			for i, want := range tt.wantArgs {
+				if got := db.args[0][i]; got != want {
+					t.Errorf("Enqueue() args[%d] = %v, want %v", i, got, want)
+				}
+			}
+
+			if err == nil && (!tt.job.RunAt.Equal(now) || !tt.job.EnqueuedAt.Equal(now)) {
+				t.Errorf("Enqueue() RunAt, EnqueuedAt = %v, %v, want %v", tt.job.RunAt, tt.job.EnqueuedAt, now)
+			}
+			if err != nil && db.args[1][1] != tt.job.UniqueKey {
+				t.Errorf("duplicate lookup unique key = %v, want %v", db.args[1][1], tt.job.UniqueKey)
+			}
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     fakeRow
+		wantErr error
+	}{
+		{name: "claimed", row: fakeRow{values: []any{"job-1"}}},
+		{name: "not claimed", row: fakeRow{err: pgx.ErrNoRows}, wantErr: ErrJobNotClaimed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{rows: []fakeRow{tt.row}}
+			queue, err := NewQueue(db, DefaultConfig())
+			if err != nil {
+				t.Fatalf("NewQueue() error = %v", err)
+			}
+
+			if err := queue.Complete(context.Background(), &Job{ID: "job-1", Attempts: 2}); err != tt.wantErr {
+				t.Errorf("Complete() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := db.args[0]; !reflect.DeepEqual(got, []any{"job-1", 2}) {
+				t.Errorf("Complete() args = %v, want the job id and attempt", got)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		job        *Job
+		wantStatus string
+	}{
+		{name: "retries left", job: &Job{ID: "1", Attempts: 1}, wantStatus: "status = 'scheduled'"},
+		{name: "retries exhausted", job: &Job{ID: "2", Attempts: 4}, wantStatus: "status = 'dead'"},
+		{name: "no retry", job: &Job{ID: "3", Attempts: 1, MaxRetries: NoRetry}, wantStatus: "status = 'dead'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{rows: []fakeRow{{values: []any{tt.job.ID}}}}
+			queue, err := NewQueue(db, DefaultConfig())
+			if err != nil {
+				t.Fatalf("NewQueue() error = %v", err)
+			}
+
+			if err := queue.Retry(context.Background(), tt.job, errors.New("boom")); err != nil {
+				t.Fatalf("Retry() error = %v", err)
+			}
+			if !strings.Contains(db.queries[0], tt.wantStatus) {
+				t.Errorf("Retry() query = %v, want %v", db.queries[0], tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	config := DefaultConfig()
+	config.RetryBaseDelay = time.Second
+	config.RetryMaxDelay = 10 * time.Second
+	queue, err := NewQueue(&fakeDB{}, config)
+	if err != nil {
+		t.Fatalf("NewQueue() error = %v", err)
+	}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 3, want: 4 * time.Second},
+		{attempts: 10, want: 10 * time.Second},
+		{attempts: 100, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		got := queue.backoff(tt.attempts)
+		if got < tt.want/2 || got > tt.want {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", tt.attempts, got, tt.want/2, tt.want)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	config := DefaultConfig()
+	config.Table = "app.jobs"
+	queue, err := NewQueue(&fakeDB{}, config)
+	if err != nil {
+		t.Fatalf("NewQueue() error = %v", err)
+	}
+
+	schema := queue.Schema()
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS app.jobs",
+		"jobs_due_idx ON app.jobs",
+		"jobs_unique_idx ON app.jobs (queue, unique_key) WHERE unique_key IS NOT NULL AND status <> 'dead'",
+	} {
+		if !strings.Contains(schema, want) {
+			t.Errorf("Schema() = %s, want it to contain %q", schema, want)
+		}
+	}
+}
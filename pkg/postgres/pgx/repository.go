@@ -0,0 +1,363 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	_postgres "go-libs/pkg/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is the subset of pgx implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Repository provides typed CRUD for a model. Column mapping comes from the db tag and
+// is computed once per type, see _postgres.Field for the supported options.
+type Repository[T any] struct {
+	db    Querier
+	model *_postgres.Model
+}
+
+// NewRepository creates a repository for T on the given pool, connection or transaction
+func NewRepository[T any](db Querier) (*Repository[T], error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	model, err := _postgres.GetModel(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+
+	return &Repository[T]{
+		db:    db,
+		model: model,
+	}, nil
+}
+
+// WithQuerier returns a copy of the repository running on another querier, for example a transaction
+func (r *Repository[T]) WithQuerier(db Querier) *Repository[T] {
+	return &Repository[T]{
+		db:    db,
+		model: r.model,
+	}
+}
+
+// querier returns the transaction carried by ctx when it was started with WithTx on the
+// pool of this repository, so repository calls inside WithTx join the transaction
+func (r *Repository[T]) querier(ctx context.Context) Querier {
+	if tx, ok := _postgres.TxFromContext(ctx); ok {
+		if t, ok := tx.(*Transaction); ok && t.conn.pool != nil && r.db == Querier(t.conn.pool) {
+			return t.tx
+		}
+	}
+	return r.db
+}
+
+// Model returns the table metadata of T
+func (r *Repository[T]) Model() *_postgres.Model {
+	return r.model
+}
+
+// Insert inserts the entity and reads every column back, so database defaults such as
+// serial IDs and timestamps are set on it
+func (r *Repository[T]) Insert(ctx context.Context, entity *T) error {
+	if entity == nil {
+		return fmt.Errorf("entity must not be nil")
+	}
+	v := reflect.ValueOf(entity).Elem()
+	fields := r.model.Insertable(v)
+
+	columns := make([]string, len(fields))
+	placeholders := make([]string, len(fields))
+	args := make([]any, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Column
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = f.Value(v).Interface()
+	}
+
+	var query string
+	if len(fields) == 0 {
+		query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING %s",
+			r.model.Table,
+			r.selectList())
+	} else {
+		query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+			r.model.Table,
+			strings.Join(columns, ", "),
+			strings.Join(placeholders, ", "),
+			r.selectList())
+	}
+
+	if err := r.querier(ctx).QueryRow(ctx, query, args...).Scan(r.scanDest(v)...); err != nil {
+		return fmt.Errorf("failed to insert %s: %w", r.model.Table, err)
+	}
+
+	return nil
+}
+
+// Get returns the record with the given primary key values, or ErrRecordNotFound
+func (r *Repository[T]) Get(ctx context.Context, pk ...any) (*T, error) {
+	where, args, err := r.pkClause(pk, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r.model.SoftDelete != nil {
+		where += " AND " + r.model.SoftDelete.Column + " IS NULL"
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s", r.selectList(), r.model.Table, where)
+
+	entity := new(T)
+	err = r.querier(ctx).QueryRow(ctx, query, args...).Scan(r.scanDest(reflect.ValueOf(entity).Elem())...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, _postgres.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", r.model.Table, err)
+	}
+
+	return entity, nil
+}
+
+// Find returns the records matching the query. A nil query returns every record.
+func (r *Repository[T]) Find(ctx context.Context, q *Query) ([]T, error) {
+	where, args, err := q.whereClause(r.model, 0)
+	if err != nil {
+		return nil, err
+	}
+	tail, err := q.tailClause(r.model)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s%s%s", r.selectList(), r.model.Table, where, tail)
+
+	rows, err := r.querier(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s: %w", r.model.Table, err)
+	}
+	defer rows.Close()
+
+	var result []T
+	for rows.Next() {
+		var entity T
+		if err := rows.Scan(r.scanDest(reflect.ValueOf(&entity).Elem())...); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", r.model.Table, err)
+		}
+		result = append(result, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find %s: %w", r.model.Table, err)
+	}
+
+	return result, nil
+}
+
+// First returns the first record matching the query, or ErrRecordNotFound
+func (r *Repository[T]) First(ctx context.Context, q *Query) (*T, error) {
+	if q == nil {
+		q = NewQuery()
+	}
+	limited := *q
+	limited.limit = 1
+
+	result, err := r.Find(ctx, &limited)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, _postgres.ErrRecordNotFound
+	}
+	return &result[0], nil
+}
+
+// Count returns the number of records matching the query. Ordering and paging are ignored.
+func (r *Repository[T]) Count(ctx context.Context, q *Query) (int64, error) {
+	where, args, err := q.whereClause(r.model, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", r.model.Table, where)
+
+	var count int64
+	if err := r.querier(ctx).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", r.model.Table, err)
+	}
+	return count, nil
+}
+
+// Update writes the given columns of the entity, or every updatable column when none are
+// given, and reads the row back. It returns ErrRecordNotFound if no row has the entity's primary key.
+func (r *Repository[T]) Update(ctx context.Context, entity *T, columns ...string) error {
+	if entity == nil {
+		return fmt.Errorf("entity must not be nil")
+	}
+	v := reflect.ValueOf(entity).Elem()
+
+	var fields []*_postgres.Field
+	if len(columns) == 0 {
+		fields = r.model.Updatable()
+	} else {
+		for _, column := range columns {
+			f, ok := r.model.FieldByColumn(column)
+			if !ok {
+				return fmt.Errorf("unknown column %q", column)
+			}
+			if f.PrimaryKey || f.ReadOnly {
+				return fmt.Errorf("column %q cannot be updated", column)
+			}
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("no columns to update")
+	}
+
+	sets := make([]string, len(fields))
+	args := make([]any, 0, len(fields)+len(r.model.PrimaryKeys))
+	for i, f := range fields {
+		sets[i] = fmt.Sprintf("%s = $%d", f.Column, i+1)
+		args = append(args, f.Value(v).Interface())
+	}
+
+	where, pkArgs, err := r.pkClause(r.pkValues(v), len(args))
+	if err != nil {
+		return err
+	}
+	args = append(args, pkArgs...)
+	if r.model.SoftDelete != nil {
+		where += " AND " + r.model.SoftDelete.Column + " IS NULL"
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s RETURNING %s",
+		r.model.Table,
+		strings.Join(sets, ", "),
+		where,
+		r.selectList())
+
+	err = r.querier(ctx).QueryRow(ctx, query, args...).Scan(r.scanDest(v)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return _postgres.ErrRecordNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", r.model.Table, err)
+	}
+
+	return nil
+}
+
+// Delete removes the record with the given primary key values. Models with a soft delete
+// column are marked as deleted instead. It returns ErrRecordNotFound if nothing was deleted.
+func (r *Repository[T]) Delete(ctx context.Context, pk ...any) error {
+	if r.model.SoftDelete == nil {
+		return r.ForceDelete(ctx, pk...)
+	}
+
+	where, args, err := r.pkClause(pk, 0)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s = now() WHERE %s AND %s IS NULL",
+		r.model.Table,
+		r.model.SoftDelete.Column,
+		where,
+		r.model.SoftDelete.Column)
+
+	return r.execOne(ctx, "delete", query, args)
+}
+
+// ForceDelete permanently removes the record, even for models with a soft delete column
+func (r *Repository[T]) ForceDelete(ctx context.Context, pk ...any) error {
+	where, args, err := r.pkClause(pk, 0)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", r.model.Table, where)
+
+	return r.execOne(ctx, "delete", query, args)
+}
+
+// Restore clears the soft delete marker of the record
+func (r *Repository[T]) Restore(ctx context.Context, pk ...any) error {
+	if r.model.SoftDelete == nil {
+		return fmt.Errorf("model %s has no soft delete column", r.model.Table)
+	}
+
+	where, args, err := r.pkClause(pk, 0)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s AND %s IS NOT NULL",
+		r.model.Table,
+		r.model.SoftDelete.Column,
+		where,
+		r.model.SoftDelete.Column)
+
+	return r.execOne(ctx, "restore", query, args)
+}
+
+// execOne runs a statement that must affect at least one row
+func (r *Repository[T]) execOne(ctx context.Context, action, query string, args []any) error {
+	tag, err := r.querier(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s %s: %w", action, r.model.Table, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return _postgres.ErrRecordNotFound
+	}
+	return nil
+}
+
+// pkClause renders the primary key condition with placeholders numbered after offset
+func (r *Repository[T]) pkClause(pk []any, offset int) (string, []any, error) {
+	if len(r.model.PrimaryKeys) == 0 {
+		return "", nil, fmt.Errorf("model %s has no primary key", r.model.Table)
+	}
+	if len(pk) != len(r.model.PrimaryKeys) {
+		return "", nil, fmt.Errorf("expected %d primary key values, got %d", len(r.model.PrimaryKeys), len(pk))
+	}
+
+	parts := make([]string, len(pk))
+	for i, f := range r.model.PrimaryKeys {
+		parts[i] = fmt.Sprintf("%s = $%d", f.Column, offset+i+1)
+	}
+	return strings.Join(parts, " AND "), pk, nil
+}
+
+// pkValues returns the primary key values of the entity
+func (r *Repository[T]) pkValues(v reflect.Value) []any {
+	values := make([]any, len(r.model.PrimaryKeys))
+	for i, f := range r.model.PrimaryKeys {
+		values[i] = f.Value(v).Interface()
+	}
+	return values
+}
+
+// selectList returns every column of the model for SELECT and RETURNING
+func (r *Repository[T]) selectList() string {
+	return strings.Join(r.model.Columns(), ", ")
+}
+
+// scanDest returns pointers to every field of the entity in column order
+func (r *Repository[T]) scanDest(v reflect.Value) []any {
+	dest := make([]any, len(r.model.Fields))
+	for i, f := range r.model.Fields {
+		dest[i] = f.Dest(v)
+	}
+	return dest
+}
@@ -1,10 +1,16 @@
 package _pgx_postgres
 
-import "github.com/jackc/pgx/v5"
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
 
 // RowsWrapper wraps pgx.Rows to implement interfaces.Rows
 type RowsWrapper struct {
 	rows pgx.Rows
+	// cancel releases the query timeout once the rows are closed
+	cancel context.CancelFunc
 }
 
 func (r *RowsWrapper) Next() bool {
@@ -17,6 +23,9 @@ func (r *RowsWrapper) Scan(dest ...any) error {
 
 func (r *RowsWrapper) Close() error {
 	r.rows.Close()
+	if r.cancel != nil {
+		r.cancel()
+	}
 	return nil
 }
 
@@ -24,11 +33,29 @@ func (r *RowsWrapper) Err() error {
 	return r.rows.Err()
 }
 
+func (r *RowsWrapper) Columns() ([]string, error) {
+	fields := r.rows.FieldDescriptions()
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Name
+	}
+	return columns, nil
+}
+
 // RowWrapper wraps pgx.Row to implement interfaces.Row
 type RowWrapper struct {
 	row pgx.Row
+	err error
+	// cancel releases the query timeout once the row is scanned
+	cancel context.CancelFunc
 }
 
 func (r *RowWrapper) Scan(dest ...any) error {
+	if r.cancel != nil {
+		defer r.cancel()
+	}
+	if r.err != nil {
+		return r.err
+	}
 	return r.row.Scan(dest...)
 }
@@ -0,0 +1,146 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	_postgres "go-libs/pkg/postgres"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	_ pgx.QueryTracer    = (*QueryTracer)(nil)
+	_ pgx.BatchTracer    = (*QueryTracer)(nil)
+	_ pgx.CopyFromTracer = (*QueryTracer)(nil)
+)
+
+// QueryTracer is a pgx tracer recording metrics, traces and slow queries. Attach it with
+// SetQueryTracer before Connect.
+type QueryTracer struct {
+	config _postgres.InstrumentationConfig
+}
+
+// NewQueryTracer creates a pgx tracer from the configuration
+func NewQueryTracer(config _postgres.InstrumentationConfig) *QueryTracer {
+	return &QueryTracer{config: config}
+}
+
+type traceKey struct{}
+
+// trace is the state of a query in progress, carried from the start to the end callback
+type trace struct {
+	statement string
+	sql       string
+	args      []any
+	span      _postgres.Span
+	start     time.Time
+}
+
+// TraceQueryStart starts tracing Query, QueryRow and Exec
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx, _postgres.QueryOperation(data.SQL), data.SQL, data.Args)
+}
+
+// TraceQueryEnd finishes tracing Query, QueryRow and Exec
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.end(ctx, data.Err)
+}
+
+// TraceBatchStart starts tracing a batch, recorded as one operation labelled by its distinct
+// statements so that the label does not grow with the batch size
+func (t *QueryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	var statements []string
+	seen := make(map[string]bool)
+	for _, q := range data.Batch.QueuedQueries {
+		statement := _postgres.NormalizeQuery(q.SQL)
+		if !seen[statement] {
+			seen[statement] = true
+			statements = append(statements, statement)
+		}
+	}
+	return t.start(ctx, "batch", strings.Join(statements, "; "), nil)
+}
+
+// TraceBatchQuery is called for each query of a batch, which is measured as a whole
+func (t *QueryTracer) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+// TraceBatchEnd finishes tracing a batch
+func (t *QueryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	t.end(ctx, data.Err)
+}
+
+// TraceCopyFromStart starts tracing CopyFrom
+func (t *QueryTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	sql := fmt.Sprintf("COPY %s (%s) FROM STDIN", data.TableName.Sanitize(), strings.Join(data.ColumnNames, ", "))
+	return t.start(ctx, "copy", sql, nil)
+}
+
+// TraceCopyFromEnd finishes tracing CopyFrom
+func (t *QueryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	t.end(ctx, data.Err)
+}
+
+// start opens the span of a query and stores its state in the context
+func (t *QueryTracer) start(ctx context.Context, operation, sql string, args []any) context.Context {
+	statement := _postgres.NormalizeQuery(sql)
+	if statement == "" {
+		// Comment-only statements such as the "-- ping" of pgx are not worth recording
+		return ctx
+	}
+
+	tr := &trace{statement: statement, sql: sql, args: args}
+	if t.config.Tracer != nil {
+		ctx, tr.span = t.config.Tracer.StartSpan(ctx, "postgres."+operation, map[string]string{
+			"db.system":    "postgresql",
+			"db.operation": operation,
+			"db.statement": statement,
+		})
+	}
+	tr.start = time.Now()
+
+	return context.WithValue(ctx, traceKey{}, tr)
+}
+
+// end closes the span of a query and records its duration and outcome
+func (t *QueryTracer) end(ctx context.Context, err error) {
+	tr, ok := ctx.Value(traceKey{}).(*trace)
+	if !ok {
+		return
+	}
+	duration := time.Since(tr.start)
+
+	if tr.span != nil {
+		tr.span.End(err)
+	}
+	if t.config.Metrics != nil {
+		t.config.Metrics.RecordQuery(ctx, tr.statement, duration, err)
+	}
+	if t.config.Logger != nil && t.config.SlowThreshold > 0 && duration >= t.config.SlowThreshold {
+		args := _postgres.QueryArgTypes(tr.args)
+		if t.config.LogArgValues {
+			args = _postgres.RedactQueryArgs(tr.sql, tr.args, t.config.Logger.IsHidden, t.config.MaxArgLength)
+		}
+		t.config.Logger.Warn(ctx, "Slow PostgreSQL query",
+			"query", strings.Join(strings.Fields(tr.sql), " "),
+			"args", args,
+			"duration", duration.String(),
+			"error", errorString(err),
+		)
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// SetQueryTracer attaches a tracer to the pool and the direct connection. Call it before
+// Connect.
+func (c *Connection) SetQueryTracer(tracer pgx.QueryTracer) {
+	c.tracer = tracer
+}
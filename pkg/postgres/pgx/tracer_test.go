@@ -0,0 +1,180 @@
+package _pgx_postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	_logger "go-libs/pkg/logger"
+	_postgres "go-libs/pkg/postgres"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type recordedQuery struct {
+	statement string
+	err       error
+}
+
+type fakeMetrics struct {
+	mu      sync.Mutex
+	queries []recordedQuery
+}
+
+func (m *fakeMetrics) RecordQuery(ctx context.Context, statement string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queries = append(m.queries, recordedQuery{statement: statement, err: err})
+}
+
+type fakeSpan struct {
+	name  string
+	attrs map[string]string
+	ended bool
+	err   error
+}
+
+func (s *fakeSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) StartSpan(ctx context.Context, name string, attributes map[string]string) (context.Context, _postgres.Span) {
+	span := &fakeSpan{name: name, attrs: attributes}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestQueryTracerRecordsQueries(t *testing.T) {
+	metrics := &fakeMetrics{}
+	tracer := &fakeTracer{}
+	config := _postgres.DefaultInstrumentationConfig()
+	config.Metrics = metrics
+	config.Tracer = tracer
+	qt := NewQueryTracer(config)
+
+	ctx := context.Background()
+	failure := errors.New("boom")
+
+	queryCtx := qt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT * FROM users WHERE id = $1", Args: []any{1}})
+	qt.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+
+	queryCtx = qt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "DELETE FROM users WHERE id IN ($1, $2)", Args: []any{1, 2}})
+	qt.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: failure})
+
+	// The pgx ping is a comment and is not recorded
+	queryCtx = qt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "-- ping"})
+	qt.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+
+	batch := &pgx.Batch{}
+	batch.Queue("INSERT INTO tags (name) VALUES ($1)", "a")
+	batch.Queue("INSERT INTO tags (name) VALUES ($1)", "b")
+	batch.Queue("UPDATE tags SET used = used + 1")
+	batchCtx := qt.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{Batch: batch})
+	qt.TraceBatchEnd(batchCtx, nil, pgx.TraceBatchEndData{})
+
+	copyCtx := qt.TraceCopyFromStart(ctx, nil, pgx.TraceCopyFromStartData{TableName: pgx.Identifier{"tags"}, ColumnNames: []string{"name"}})
+	qt.TraceCopyFromEnd(copyCtx, nil, pgx.TraceCopyFromEndData{})
+
+	wantStatements := []string{
+		"SELECT * FROM users WHERE id = ?",
+		"DELETE FROM users WHERE id IN (?)",
+		"INSERT INTO tags (name) VALUES (?); UPDATE tags SET used = used + ?",
+		`COPY "tags" (name) FROM STDIN`,
+	}
+	if len(metrics.queries) != len(wantStatements) {
+		t.Fatalf("RecordQuery() calls = %v, want %v", len(metrics.queries), len(wantStatements))
+	}
+	for i, want := range wantStatements {
+		if got := metrics.queries[i].statement; got != want {
+			t.Errorf("RecordQuery() statement = %q, want %q", got, want)
+		}
+	}
+	if metrics.queries[1].err != failure {
+		t.Errorf("RecordQuery() error = %v, want %v", metrics.queries[1].err, failure)
+	}
+
+	wantSpans := []string{"postgres.select", "postgres.delete", "postgres.batch", "postgres.copy"}
+	if len(tracer.spans) != len(wantSpans) {
+		t.Fatalf("StartSpan() calls = %v, want %v", len(tracer.spans), len(wantSpans))
+	}
+	for i, want := range wantSpans {
+		span := tracer.spans[i]
+		if span.name != want {
+			t.Errorf("StartSpan() name = %v, want %v", span.name, want)
+		}
+		if !span.ended {
+			t.Errorf("span %s not ended", span.name)
+		}
+		if span.attrs["db.system"] != "postgresql" {
+			t.Errorf("span %s db.system = %v, want %v", span.name, span.attrs["db.system"], "postgresql")
+		}
+	}
+	if tracer.spans[1].err != failure {
+		t.Errorf("span error = %v, want %v", tracer.spans[1].err, failure)
+	}
+}
+
+func TestQueryTracerLogsSlowQueries(t *testing.T) {
+	tests := []struct {
+		name      string
+		logValues bool
+		want      []string
+		notWant   []string
+	}{
+		{
+			name:    "types only",
+			want:    []string{"Slow PostgreSQL query", "UPDATE users SET password = crypt($2, gen_salt('bf')) WHERE email = $1", "string"},
+			notWant: []string{"a@b.c", "secret"},
+		},
+		{
+			name:      "values",
+			logValues: true,
+			want:      []string{"a@b.c", "secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "slow.log")
+			config := _postgres.DefaultInstrumentationConfig()
+			config.Logger = _logger.New(_logger.Config{Output: output, Format: "json", HideFields: []string{"password"}})
+			config.SlowThreshold = time.Nanosecond
+			config.LogArgValues = tt.logValues
+			qt := NewQueryTracer(config)
+
+			// The password is a function argument, which column-based masking cannot see
+			ctx := qt.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+				SQL:  "UPDATE users\n\tSET password = crypt($2, gen_salt('bf')) WHERE email = $1",
+				Args: []any{"a@b.c", "secret"},
+			})
+			time.Sleep(time.Millisecond)
+			qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			log := string(data)
+			for _, want := range tt.want {
+				if !strings.Contains(log, want) {
+					t.Errorf("slow query log = %s, want it to contain %q", log, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(log, notWant) {
+					t.Errorf("slow query log = %s, want it not to contain %q", log, notWant)
+				}
+			}
+		})
+	}
+}
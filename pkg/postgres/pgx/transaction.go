@@ -8,10 +8,11 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-// Transaction wraps GORM transaction
+// Transaction wraps pgx transaction
 type Transaction struct {
-	tx  pgx.Tx
-	ctx context.Context
+	tx   pgx.Tx
+	ctx  context.Context
+	conn *Connection
 }
 
 // Commit commits the transaction
@@ -19,20 +20,21 @@ func (t *Transaction) Commit() error {
 	return t.tx.Commit(t.ctx)
 }
 
-// Rollback rolls back the transaction
+// Rollback rolls back the transaction. It also runs when the context of the transaction
+// is done, so the connection is released.
 func (t *Transaction) Rollback() error {
-	return t.tx.Rollback(t.ctx)
+	return t.tx.Rollback(context.WithoutCancel(t.ctx))
 }
 
 // Exec executes a query within transaction
 func (t *Transaction) Exec(ctx context.Context, query string, args ...any) error {
-	_, err := t.tx.Exec(t.ctx, query, args...)
+	_, err := t.tx.Exec(ctx, query, args...)
 	return err
 }
 
 // Query executes a query and returns rows within transaction
 func (t *Transaction) Query(ctx context.Context, query string, args ...any) (_postgres.Rows, error) {
-	rows, err := t.tx.Query(t.ctx, query, args...)
+	rows, err := t.tx.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +43,11 @@ func (t *Transaction) Query(ctx context.Context, query string, args ...any) (_po
 
 // QueryRow executes a query and returns a single row within transaction
 func (t *Transaction) QueryRow(ctx context.Context, query string, args ...any) _postgres.Row {
-	row := t.tx.QueryRow(t.ctx, query, args...)
+	row := t.tx.QueryRow(ctx, query, args...)
 	return &RowWrapper{row: row}
 }
+
+// Tx returns the underlying pgx transaction
+func (t *Transaction) Tx() pgx.Tx {
+	return t.tx
+}
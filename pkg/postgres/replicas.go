@@ -0,0 +1,373 @@
+package _postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Replica balancing policies for MasterSlaveConfig.ReplicaBalancing
+const (
+	// ReplicaBalancingRoundRobin spreads reads over replicas in proportion to their weight
+	ReplicaBalancingRoundRobin = "round_robin"
+	// ReplicaBalancingLeastConnections sends reads to the replica with the fewest
+	// connections in use relative to its weight
+	ReplicaBalancingLeastConnections = "least_connections"
+)
+
+// ReplicationLagQuery returns the replication lag of a standby in seconds. A standby that
+// has replayed everything it received is not lagging even if the primary has been idle,
+// and a primary reports 0.
+const ReplicationLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END::float8`
+
+// ReplicaClient is a connection usable as a read replica
+type ReplicaClient interface {
+	DatabaseClient
+	StatsProvider
+}
+
+// ReplicaStatus describes a read replica
+type ReplicaStatus struct {
+	Name      string
+	Weight    int
+	Connected bool
+	Healthy   bool
+	Lag       time.Duration
+	InUse     int
+	LastCheck time.Time
+	LastError string
+}
+
+// replica is a member of a ReplicaSet
+type replica[C ReplicaClient] struct {
+	conn   C
+	status ReplicaStatus
+	// current is the smooth weighted round-robin counter
+	current int
+}
+
+// ReplicaSet balances reads over replicas, skipping the ones that are unhealthy or lag
+// too much. Health and lag are measured by Check, periodically once Start is called.
+type ReplicaSet[C ReplicaClient] struct {
+	balancing string
+	maxLag    time.Duration
+	interval  time.Duration
+
+	replicas []*replica[C]
+	mu       sync.Mutex
+
+	// Control
+	running bool
+	cancel  context.CancelFunc
+	doneCh  chan struct{}
+}
+
+// NewReplicaSet creates an empty replica set. Replicas lagging more than maxLag are skipped
+// (0 disables the check) and interval is the period of background checks.
+func NewReplicaSet[C ReplicaClient](balancing string, maxLag, interval time.Duration) *ReplicaSet[C] {
+	if balancing == "" {
+		balancing = ReplicaBalancingRoundRobin
+	}
+	return &ReplicaSet[C]{
+		balancing: balancing,
+		maxLag:    maxLag,
+		interval:  interval,
+	}
+}
+
+// Add adds a replica. A replica that is not connected yet is connected by Check.
+func (s *ReplicaSet[C]) Add(name string, weight int, conn C, connected bool) {
+	if weight <= 0 {
+		weight = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replicas = append(s.replicas, &replica[C]{
+		conn: conn,
+		status: ReplicaStatus{
+			Name:      name,
+			Weight:    weight,
+			Connected: connected,
+			Healthy:   connected,
+		},
+	})
+}
+
+// Pick returns a replica for a read, or false when none is usable
+func (s *ReplicaSet[C]) Pick() (C, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var picked *replica[C]
+	switch s.balancing {
+	case ReplicaBalancingLeastConnections:
+		picked = s.pickLeastConnections()
+	default:
+		picked = s.pickRoundRobin()
+	}
+
+	if picked == nil {
+		var zero C
+		return zero, false
+	}
+	return picked.conn, true
+}
+
+// pickRoundRobin implements smooth weighted round-robin: each usable replica gains its
+// weight, the one with the highest counter wins and pays back the total weight
+func (s *ReplicaSet[C]) pickRoundRobin() *replica[C] {
+	var picked *replica[C]
+	total := 0
+
+	for _, r := range s.replicas {
+		if !s.usable(r) {
+			continue
+		}
+		r.current += r.status.Weight
+		total += r.status.Weight
+		if picked == nil || r.current > picked.current {
+			picked = r
+		}
+	}
+
+	if picked != nil {
+		picked.current -= total
+	}
+	return picked
+}
+
+// pickLeastConnections picks the replica with the fewest connections in use per weight
+func (s *ReplicaSet[C]) pickLeastConnections() *replica[C] {
+	var picked *replica[C]
+	var pickedLoad float64
+
+	for _, r := range s.replicas {
+		if !s.usable(r) {
+			continue
+		}
+		load := float64(r.conn.Stats().InUseConnections) / float64(r.status.Weight)
+		if picked == nil || load < pickedLoad {
+			picked = r
+			pickedLoad = load
+		}
+	}
+	return picked
+}
+
+// usable reports whether a replica may serve reads
+func (s *ReplicaSet[C]) usable(r *replica[C]) bool {
+	if !r.status.Connected || !r.status.Healthy {
+		return false
+	}
+	return s.maxLag <= 0 || r.status.Lag <= s.maxLag
+}
+
+// HasUsable reports whether at least one replica may serve reads
+func (s *ReplicaSet[C]) HasUsable() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.replicas {
+		if s.usable(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplicaConn is a connected member of a ReplicaSet
+type ReplicaConn[C ReplicaClient] struct {
+	Name string
+	Conn C
+}
+
+// Connections returns the connected replicas
+func (s *ReplicaSet[C]) Connections() []ReplicaConn[C] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conns := make([]ReplicaConn[C], 0, len(s.replicas))
+	for _, r := range s.replicas {
+		if r.status.Connected {
+			conns = append(conns, ReplicaConn[C]{Name: r.status.Name, Conn: r.conn})
+		}
+	}
+	return conns
+}
+
+// Len returns the number of replicas
+func (s *ReplicaSet[C]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.replicas)
+}
+
+// Status returns the state of every replica
+func (s *ReplicaSet[C]) Status() []ReplicaStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]ReplicaStatus, len(s.replicas))
+	for i, r := range s.replicas {
+		statuses[i] = r.status
+		if r.status.Connected {
+			statuses[i].InUse = r.conn.Stats().InUseConnections
+		}
+	}
+	return statuses
+}
+
+// Check connects replicas that are not connected yet and measures the health and lag of
+// the others
+func (s *ReplicaSet[C]) Check(ctx context.Context) {
+	s.mu.Lock()
+	replicas := append([]*replica[C](nil), s.replicas...)
+	s.mu.Unlock()
+
+	for _, r := range replicas {
+		s.mu.Lock()
+		connected := r.status.Connected
+		s.mu.Unlock()
+
+		status := ReplicaStatus{Connected: connected, LastCheck: time.Now()}
+
+		if !connected {
+			if err := r.conn.Connect(ctx); err != nil {
+				status.LastError = err.Error()
+				s.update(r, status)
+				continue
+			}
+			status.Connected = true
+			fmt.Printf("Connected to replica %s\n", r.status.Name)
+		}
+
+		lag, err := measureLag(ctx, r.conn)
+		if err != nil {
+			status.LastError = err.Error()
+		} else {
+			status.Healthy = true
+			status.Lag = lag
+		}
+		s.update(r, status)
+	}
+}
+
+// update stores the outcome of a check, logging changes of usability
+func (s *ReplicaSet[C]) update(r *replica[C], status ReplicaStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wasUsable := s.usable(r)
+	r.status.Connected = status.Connected
+	r.status.Healthy = status.Healthy
+	r.status.Lag = status.Lag
+	r.status.LastCheck = status.LastCheck
+	r.status.LastError = status.LastError
+	isUsable := s.usable(r)
+
+	switch {
+	case wasUsable && !isUsable && !status.Healthy:
+		fmt.Printf("Replica %s is unhealthy, removing it from rotation: %s\n", r.status.Name, status.LastError)
+	case wasUsable && !isUsable:
+		fmt.Printf("Replica %s lags %v behind, removing it from rotation\n", r.status.Name, status.Lag)
+	case !wasUsable && isUsable:
+		fmt.Printf("Replica %s is back in rotation\n", r.status.Name)
+	}
+}
+
+// measureLag pings a replica and returns its replication lag
+func measureLag(ctx context.Context, conn ReplicaClient) (time.Duration, error) {
+	if err := conn.Ping(ctx); err != nil {
+		return 0, fmt.Errorf("failed to ping: %w", err)
+	}
+
+	var seconds float64
+	if err := conn.QueryRow(ctx, ReplicationLagQuery).Scan(&seconds); err != nil {
+		return 0, fmt.Errorf("failed to measure replication lag: %w", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Start checks the replicas every interval in the background
+func (s *ReplicaSet[C]) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return fmt.Errorf("replica set already started")
+	}
+	if s.interval <= 0 {
+		return fmt.Errorf("check interval must be greater than 0")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.doneCh = make(chan struct{})
+	s.running = true
+
+	go s.run(ctx, s.doneCh)
+
+	return nil
+}
+
+// run performs the periodic checks
+func (s *ReplicaSet[C]) run(ctx context.Context, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, s.interval)
+			s.Check(checkCtx)
+			cancel()
+		}
+	}
+}
+
+// Stop stops the background checks
+func (s *ReplicaSet[C]) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.cancel()
+	doneCh := s.doneCh
+	s.running = false
+	s.mu.Unlock()
+
+	<-doneCh
+}
+
+// Close stops the background checks and closes every replica connection
+func (s *ReplicaSet[C]) Close() error {
+	s.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for _, r := range s.replicas {
+		if !r.status.Connected {
+			continue
+		}
+		if err := r.conn.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("error closing replica %s: %w", r.status.Name, err)
+		}
+		r.status.Connected = false
+		r.status.Healthy = false
+	}
+	return firstErr
+}
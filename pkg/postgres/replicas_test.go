@@ -0,0 +1,181 @@
+package _postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeReplica is a replica with a settable health, lag and load
+type fakeReplica struct {
+	name       string
+	connectErr error
+	pingErr    error
+	lag        float64
+	inUse      int
+	standby    bool
+}
+
+func (r *fakeReplica) Connect(ctx context.Context) error { return r.connectErr }
+func (r *fakeReplica) Close() error                      { return nil }
+func (r *fakeReplica) Ping(ctx context.Context) error    { return r.pingErr }
+func (r *fakeReplica) IsHealthy(ctx context.Context) bool {
+	return r.pingErr == nil
+}
+
+func (r *fakeReplica) Exec(ctx context.Context, query string, args ...any) error {
+	return nil
+}
+
+func (r *fakeReplica) Query(ctx context.Context, query string, args ...any) (Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeReplica) QueryRow(ctx context.Context, query string, args ...any) Row {
+	if query == RecoveryQuery {
+		return recoveryRow{inRecovery: r.standby, err: r.pingErr}
+	}
+	return lagRow{lag: r.lag}
+}
+
+func (r *fakeReplica) BeginTx(ctx context.Context) (Transaction, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeReplica) BeginTxWithOptions(ctx context.Context, opts TxOptions) (Transaction, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeReplica) WithTx(ctx context.Context, opts TxOptions, fn TxFunc) error {
+	return errors.New("not implemented")
+}
+
+func (r *fakeReplica) Stats() ConnectionStats {
+	return ConnectionStats{InUseConnections: r.inUse}
+}
+
+// lagRow returns a replication lag in seconds
+type lagRow struct {
+	lag float64
+}
+
+func (r lagRow) Scan(dest ...any) error {
+	reflect.ValueOf(dest[0]).Elem().SetFloat(r.lag)
+	return nil
+}
+
+func pickNames(s *ReplicaSet[*fakeReplica], n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		r, ok := s.Pick()
+		if !ok {
+			counts[""]++
+			continue
+		}
+		counts[r.name]++
+	}
+	return counts
+}
+
+func TestReplicaSetRoundRobin(t *testing.T) {
+	s := NewReplicaSet[*fakeReplica](ReplicaBalancingRoundRobin, 0, time.Second)
+	s.Add("a", 2, &fakeReplica{name: "a"}, true)
+	s.Add("b", 1, &fakeReplica{name: "b"}, true)
+
+	counts := pickNames(s, 9)
+	if counts["a"] != 6 || counts["b"] != 3 {
+		t.Errorf("Pick() counts = %v, want a:6 b:3", counts)
+	}
+}
+
+func TestReplicaSetLeastConnections(t *testing.T) {
+	s := NewReplicaSet[*fakeReplica](ReplicaBalancingLeastConnections, 0, time.Second)
+	s.Add("busy", 1, &fakeReplica{name: "busy", inUse: 8}, true)
+	s.Add("idle", 1, &fakeReplica{name: "idle", inUse: 2}, true)
+	s.Add("big", 4, &fakeReplica{name: "big", inUse: 12}, true)
+
+	r, ok := s.Pick()
+	if !ok || r.name != "idle" {
+		t.Errorf("Pick() = %v, want idle", r)
+	}
+}
+
+func TestReplicaSetCheck(t *testing.T) {
+	lagging := &fakeReplica{name: "lagging", lag: 30}
+	down := &fakeReplica{name: "down", pingErr: errors.New("connection refused")}
+	late := &fakeReplica{name: "late", connectErr: errors.New("connection refused")}
+	good := &fakeReplica{name: "good", lag: 0.5}
+
+	s := NewReplicaSet[*fakeReplica](ReplicaBalancingRoundRobin, 10*time.Second, time.Second)
+	s.Add("lagging", 1, lagging, true)
+	s.Add("down", 1, down, true)
+	s.Add("late", 1, late, false)
+	s.Add("good", 1, good, true)
+
+	s.Check(context.Background())
+
+	if counts := pickNames(s, 4); counts["good"] != 4 {
+		t.Errorf("Pick() counts = %v, want only good", counts)
+	}
+
+	status := s.Status()
+	if status[0].Lag != 30*time.Second || !status[0].Healthy {
+		t.Errorf("Status()[0] = %+v, want healthy with 30s lag", status[0])
+	}
+	if status[1].Healthy || status[1].LastError == "" {
+		t.Errorf("Status()[1] = %+v, want unhealthy with an error", status[1])
+	}
+	if status[2].Connected {
+		t.Errorf("Status()[2] = %+v, want not connected", status[2])
+	}
+
+	// Replicas come back once they recover
+	lagging.lag = 1
+	down.pingErr = nil
+	late.connectErr = nil
+	s.Check(context.Background())
+
+	if counts := pickNames(s, 8); len(counts) != 4 || counts[""] != 0 {
+		t.Errorf("Pick() counts = %v, want every replica", counts)
+	}
+
+	// Reads fall back to the master when no replica is usable
+	for _, r := range []*fakeReplica{lagging, down, late, good} {
+		r.pingErr = errors.New("connection refused")
+	}
+	s.Check(context.Background())
+	if _, ok := s.Pick(); ok || s.HasUsable() {
+		t.Errorf("Pick() found a replica, want none")
+	}
+}
+
+func TestMasterSlaveConfigReplicaConfigs(t *testing.T) {
+	config := DefaultMasterSlaveConfig()
+	replicas := config.ReplicaConfigs()
+	if len(replicas) != 1 || replicas[0].Config != config.Slave {
+		t.Errorf("ReplicaConfigs() = %v, want the slave", replicas)
+	}
+
+	config.Replicas = []*ReplicaConfig{
+		{Config: DefaultConfig(), Name: "replica-1", Weight: 2},
+		{Config: DefaultConfig()},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if replicas := config.ReplicaConfigs(); len(replicas) != 2 || replicas[1].DisplayName() != "localhost:5432" {
+		t.Errorf("ReplicaConfigs() = %v, want the two replicas", replicas)
+	}
+
+	config.Replicas[1].Weight = -1
+	if err := config.Validate(); err == nil {
+		t.Errorf("Validate() with a negative weight error = nil, want error")
+	}
+
+	config.UseSlaveConnection = false
+	if replicas := config.ReplicaConfigs(); len(replicas) != 0 {
+		t.Errorf("ReplicaConfigs() = %v, want none", replicas)
+	}
+}
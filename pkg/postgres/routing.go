@@ -0,0 +1,172 @@
+package _postgres
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// routingKey is the context key of the routing preferences
+type routingKey struct{}
+
+// routing holds the read routing preferences carried by a context
+type routing struct {
+	forceMaster bool
+	// lastWrite is the time of the last write of the session in unix nanoseconds, nil
+	// when the context does not track writes
+	lastWrite *atomic.Int64
+}
+
+// ForceMaster returns a context whose queries always run on the master, for reads that
+// must see the latest data
+func ForceMaster(ctx context.Context) context.Context {
+	r := routingFromContext(ctx)
+	r.forceMaster = true
+	return context.WithValue(ctx, routingKey{}, &r)
+}
+
+// WithReadYourWrites returns a context that tracks its writes: reads made with it, or a
+// context derived from it, go to the master for the connection's read-your-writes window
+// after each write. Call it once per request or session.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	r := routingFromContext(ctx)
+	r.lastWrite = new(atomic.Int64)
+	return context.WithValue(ctx, routingKey{}, &r)
+}
+
+// RecordWrite notes a write on a context created with WithReadYourWrites
+func RecordWrite(ctx context.Context) {
+	if r, ok := ctx.Value(routingKey{}).(*routing); ok && r.lastWrite != nil {
+		r.lastWrite.Store(time.Now().UnixNano())
+	}
+}
+
+// UseSlave reports whether a query may run on a slave: it must be read-only, outside a
+// transaction, not forced to the master, and not within window of a write of the session
+func UseSlave(ctx context.Context, query string, window time.Duration) bool {
+	if _, ok := TxFromContext(ctx); ok {
+		return false
+	}
+
+	r := routingFromContext(ctx)
+	if r.forceMaster {
+		return false
+	}
+	if r.lastWrite != nil && window > 0 {
+		if last := r.lastWrite.Load(); last != 0 && time.Since(time.Unix(0, last)) < window {
+			return false
+		}
+	}
+
+	return IsReadOnlyQuery(query)
+}
+
+// routingFromContext returns a copy of the routing preferences of ctx
+func routingFromContext(ctx context.Context) routing {
+	if r, ok := ctx.Value(routingKey{}).(*routing); ok {
+		return *r
+	}
+	return routing{}
+}
+
+// writeKeywords are the keywords that make a statement write, even inside WITH
+var writeKeywords = map[string]bool{
+	"INSERT":   true,
+	"UPDATE":   true,
+	"DELETE":   true,
+	"MERGE":    true,
+	"CREATE":   true,
+	"DROP":     true,
+	"ALTER":    true,
+	"TRUNCATE": true,
+	"GRANT":    true,
+	"REVOKE":   true,
+	"LOCK":     true,
+	"COPY":     true,
+	"CALL":     true,
+	"DO":       true,
+	"INTO":     true,
+}
+
+// readKeywords are the first keywords of read-only statements
+var readKeywords = map[string]bool{
+	"SELECT":  true,
+	"WITH":    true,
+	"SHOW":    true,
+	"VALUES":  true,
+	"TABLE":   true,
+	"EXPLAIN": true,
+}
+
+// IsReadOnlyQuery reports whether a statement only reads data. It starts with SELECT,
+// WITH, SHOW, VALUES, TABLE or EXPLAIN and contains no write keyword or row lock such as
+// SELECT ... FOR UPDATE. Functions with side effects (nextval, pg_advisory_lock) are not
+// detected; use ForceMaster for those.
+func IsReadOnlyQuery(query string) bool {
+	words := sqlKeywords(query)
+	if len(words) == 0 || !readKeywords[words[0]] {
+		return false
+	}
+
+	for i, word := range words {
+		if writeKeywords[word] {
+			return false
+		}
+		// Row locks: FOR UPDATE, FOR NO KEY UPDATE, FOR SHARE, FOR KEY SHARE
+		if word == "FOR" && i+1 < len(words) {
+			switch words[i+1] {
+			case "UPDATE", "SHARE", "NO", "KEY":
+				return false
+			}
+		}
+		// EXPLAIN ANALYZE runs the statement
+		if i == 1 && words[0] == "EXPLAIN" && word == "ANALYZE" {
+			return false
+		}
+	}
+	return true
+}
+
+// sqlKeywords returns the upper-cased words of a statement, skipping comments, string
+// literals and quoted identifiers
+func sqlKeywords(query string) []string {
+	var words []string
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i++
+		case r == '\'' || r == '"':
+			i++
+			for i < len(runes) && runes[i] != r {
+				i++
+			}
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '$') {
+				i++
+			}
+			words = append(words, strings.ToUpper(string(runes[start:i])))
+			i--
+		case unicode.IsDigit(r) || r == '$':
+			// Skip numbers and parameters so they do not join the next word
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+		}
+	}
+
+	return words
+}
@@ -0,0 +1,82 @@
+package _postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT id FROM users WHERE id = $1", true},
+		{"  select count(*) from orders", true},
+		{"-- latest\nSELECT * FROM users ORDER BY created_at DESC", true},
+		{"/* report */ WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"SELECT 'insert into x' AS note", true},
+		{`SELECT "update" FROM audit`, true},
+		{"SHOW server_version", true},
+		{"EXPLAIN SELECT 1", true},
+		{"SELECT created_at, updated_at FROM users", true},
+		{"INSERT INTO users (name) VALUES ($1) RETURNING id", false},
+		{"UPDATE users SET name = $1", false},
+		{"WITH moved AS (DELETE FROM queue RETURNING *) SELECT * FROM moved", false},
+		{"SELECT * FROM jobs FOR UPDATE SKIP LOCKED", false},
+		{"SELECT * FROM jobs FOR NO KEY UPDATE", false},
+		{"SELECT * FROM jobs FOR SHARE", false},
+		{"SELECT * INTO archive FROM users", false},
+		{"EXPLAIN ANALYZE SELECT 1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsReadOnlyQuery(tt.query); got != tt.want {
+			t.Errorf("IsReadOnlyQuery(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestUseSlave(t *testing.T) {
+	ctx := context.Background()
+	query := "SELECT * FROM users"
+
+	if !UseSlave(ctx, query, time.Second) {
+		t.Errorf("UseSlave() = false, want true for a read")
+	}
+	if UseSlave(ctx, "DELETE FROM users", time.Second) {
+		t.Errorf("UseSlave() = true, want false for a write")
+	}
+	if UseSlave(ForceMaster(ctx), query, time.Second) {
+		t.Errorf("UseSlave() = true, want false when forced to master")
+	}
+
+	txCtx := context.WithValue(ctx, txKey{}, &txState{tx: &fakeTx{}})
+	if UseSlave(txCtx, query, time.Second) {
+		t.Errorf("UseSlave() = true, want false inside a transaction")
+	}
+
+	session := WithReadYourWrites(ctx)
+	if !UseSlave(session, query, time.Second) {
+		t.Errorf("UseSlave() = false, want true before any write")
+	}
+	RecordWrite(session)
+	if UseSlave(session, query, time.Second) {
+		t.Errorf("UseSlave() = true, want false right after a write")
+	}
+	if UseSlave(ForceMaster(session), query, 0) {
+		t.Errorf("UseSlave() = true, want false when a derived context is forced to master")
+	}
+	if !UseSlave(session, query, 0) {
+		t.Errorf("UseSlave() = false, want true without a window")
+	}
+
+	// Derived contexts share the session
+	derived := ForceMaster(session)
+	RecordWrite(derived)
+	time.Sleep(20 * time.Millisecond)
+	if !UseSlave(session, query, 10*time.Millisecond) {
+		t.Errorf("UseSlave() = false, want true after the window")
+	}
+}
@@ -0,0 +1,128 @@
+package _postgres
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ScanStruct scans the current row into dest, which must be a pointer to a struct.
+// Columns are matched to fields by db tag or snake_cased field name, the same way as
+// GetModel; columns without a matching field are discarded. Nullable columns should
+// map to pointer fields, which are set to nil for NULL.
+//
+//	for rows.Next() {
+//		var user User
+//		if err := _postgres.ScanStruct(rows, &user); err != nil {
+//			return err
+//		}
+//	}
+func ScanStruct(rows Rows, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("dest must be a non-nil pointer to a struct")
+	}
+
+	s, err := newStructScanner(rows, v.Elem().Type())
+	if err != nil {
+		return err
+	}
+	return s.scan(rows, v.Elem())
+}
+
+// ScanAll scans every remaining row into a slice of T and closes rows
+func ScanAll[T any](rows Rows) ([]T, error) {
+	defer rows.Close()
+
+	s, err := newStructScanner(rows, reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	var result []T
+	for rows.Next() {
+		var entity T
+		if err := s.scan(rows, reflect.ValueOf(&entity).Elem()); err != nil {
+			return nil, err
+		}
+		result = append(result, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// ScanOne scans the first row into a T and closes rows. It returns ErrRecordNotFound
+// when there are no rows.
+func ScanOne[T any](rows Rows) (*T, error) {
+	defer rows.Close()
+
+	s, err := newStructScanner(rows, reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read rows: %w", err)
+		}
+		return nil, ErrRecordNotFound
+	}
+
+	entity := new(T)
+	if err := s.scan(rows, reflect.ValueOf(entity).Elem()); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// structScanner maps the columns of a result set to the fields of a model
+type structScanner struct {
+	model *Model
+	// fields holds the field of each column, nil for columns that are discarded
+	fields []*Field
+}
+
+// newStructScanner resolves the columns of rows against the model of t
+func newStructScanner(rows Rows, t reflect.Type) (*structScanner, error) {
+	model, err := GetModel(t)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	fields := make([]*Field, len(columns))
+	for i, column := range columns {
+		if f, ok := model.FieldByColumn(column); ok {
+			fields[i] = f
+		}
+	}
+
+	return &structScanner{
+		model:  model,
+		fields: fields,
+	}, nil
+}
+
+// scan reads the current row into v, an addressable struct of the model type
+func (s *structScanner) scan(rows Rows, v reflect.Value) error {
+	dest := make([]any, len(s.fields))
+	for i, f := range s.fields {
+		if f == nil {
+			dest[i] = new(any)
+			continue
+		}
+		dest[i] = f.Dest(v)
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return fmt.Errorf("failed to scan %s: %w", s.model.Table, err)
+	}
+	return nil
+}
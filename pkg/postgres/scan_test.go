@@ -0,0 +1,149 @@
+package _postgres
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeRows serves fixed rows, assigning each value to the matching destination
+type fakeRows struct {
+	columns []string
+	rows    [][]any
+	pos     int
+	closed  bool
+}
+
+func (r *fakeRows) Next() bool {
+	r.pos++
+	return r.pos <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	row := r.rows[r.pos-1]
+	if len(dest) != len(row) {
+		return errors.New("destination count mismatch")
+	}
+	for i, value := range row {
+		target := reflect.ValueOf(dest[i]).Elem()
+		if value == nil {
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		}
+		target.Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func (r *fakeRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *fakeRows) Err() error {
+	return nil
+}
+
+func (r *fakeRows) Columns() ([]string, error) {
+	return r.columns, nil
+}
+
+type Audit struct {
+	UpdatedBy string `db:"updated_by"`
+}
+
+type testProfile struct {
+	testBase
+	*Audit
+	Email string  `db:"email"`
+	Phone *string `db:"phone"`
+}
+
+func TestScanAll(t *testing.T) {
+	phone := "0900000000"
+	rows := &fakeRows{
+		columns: []string{"id", "email", "phone", "updated_by", "extra"},
+		rows: [][]any{
+			{int64(1), "a@example.com", &phone, "admin", 42},
+			{int64(2), "b@example.com", nil, "system", 43},
+		},
+	}
+
+	result, err := ScanAll[testProfile](rows)
+	if err != nil {
+		t.Fatalf("ScanAll() error = %v", err)
+	}
+	if !rows.closed {
+		t.Errorf("ScanAll() did not close rows")
+	}
+	if len(result) != 2 {
+		t.Fatalf("ScanAll() len = %v, want %v", len(result), 2)
+	}
+
+	if result[0].ID != 1 || result[0].Email != "a@example.com" {
+		t.Errorf("ScanAll()[0] = %+v, want id 1 and a@example.com", result[0])
+	}
+	if result[0].Phone == nil || *result[0].Phone != phone {
+		t.Errorf("ScanAll()[0].Phone = %v, want %v", result[0].Phone, phone)
+	}
+	if result[1].Phone != nil {
+		t.Errorf("ScanAll()[1].Phone = %v, want nil", *result[1].Phone)
+	}
+	if result[1].Audit == nil || result[1].UpdatedBy != "system" {
+		t.Errorf("ScanAll()[1].Audit = %v, want updated_by system", result[1].Audit)
+	}
+}
+
+func TestScanOne(t *testing.T) {
+	rows := &fakeRows{
+		columns: []string{"id", "email"},
+		rows:    [][]any{{int64(7), "c@example.com"}},
+	}
+
+	user, err := ScanOne[testUser](rows)
+	if err != nil {
+		t.Fatalf("ScanOne() error = %v", err)
+	}
+	if user.ID != 7 || user.Email != "c@example.com" {
+		t.Errorf("ScanOne() = %+v, want id 7 and c@example.com", user)
+	}
+
+	_, err = ScanOne[testUser](&fakeRows{columns: []string{"id"}})
+	if !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("ScanOne() error = %v, want %v", err, ErrRecordNotFound)
+	}
+}
+
+func TestScanStruct(t *testing.T) {
+	rows := &fakeRows{
+		columns: []string{"email", "nickname"},
+		rows:    [][]any{{"d@example.com", "dee"}},
+	}
+	rows.Next()
+
+	var user testUser
+	if err := ScanStruct(rows, &user); err != nil {
+		t.Fatalf("ScanStruct() error = %v", err)
+	}
+	if user.Email != "d@example.com" || user.Nickname != "dee" {
+		t.Errorf("ScanStruct() = %+v, want d@example.com and dee", user)
+	}
+
+	if err := ScanStruct(rows, user); err == nil {
+		t.Errorf("ScanStruct() with a non-pointer error = nil, want error")
+	}
+}
+
+func TestFieldValueNilEmbedded(t *testing.T) {
+	m, err := GetModel(testProfile{})
+	if err != nil {
+		t.Fatalf("GetModel() error = %v", err)
+	}
+	f, ok := m.FieldByColumn("updated_by")
+	if !ok {
+		t.Fatalf("GetModel() missing column updated_by")
+	}
+	if got := f.Value(reflect.ValueOf(testProfile{})).Interface(); got != "" {
+		t.Errorf("Field.Value() = %v, want empty string", got)
+	}
+}
@@ -0,0 +1,197 @@
+package _postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IsolationLevel is a transaction isolation level
+type IsolationLevel string
+
+const (
+	// IsolationDefault uses the server default (normally read committed)
+	IsolationDefault        IsolationLevel = ""
+	IsolationReadCommitted  IsolationLevel = "read committed"
+	IsolationRepeatableRead IsolationLevel = "repeatable read"
+	IsolationSerializable   IsolationLevel = "serializable"
+)
+
+// SQLSTATE codes of errors that are resolved by running the transaction again
+const (
+	SQLStateSerializationFailure = "40001"
+	SQLStateDeadlockDetected     = "40P01"
+)
+
+// TxOptions controls a transaction started by WithTx
+type TxOptions struct {
+	Isolation IsolationLevel `json:"isolation" yaml:"isolation"`
+	ReadOnly  bool           `json:"read_only" yaml:"read_only"`
+
+	// MaxRetries is how many times the transaction is run again after a serialization
+	// failure or deadlock (0 disables retries)
+	MaxRetries int `json:"max_retries" yaml:"max_retries"`
+	// RetryBackoff is the wait before the first retry, doubled after each attempt
+	RetryBackoff time.Duration `json:"retry_backoff" yaml:"retry_backoff"`
+	// MaxRetryBackoff caps the wait between retries
+	MaxRetryBackoff time.Duration `json:"max_retry_backoff" yaml:"max_retry_backoff"`
+}
+
+// DefaultTxOptions returns transaction options with sensible defaults
+func DefaultTxOptions() TxOptions {
+	return TxOptions{
+		Isolation:       IsolationDefault,
+		MaxRetries:      3,
+		RetryBackoff:    10 * time.Millisecond,
+		MaxRetryBackoff: 500 * time.Millisecond,
+	}
+}
+
+// Validate checks if the transaction options are valid
+func (o *TxOptions) Validate() error {
+	switch o.Isolation {
+	case IsolationDefault, IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable:
+	default:
+		return fmt.Errorf("invalid isolation level: %s", o.Isolation)
+	}
+	if o.MaxRetries < 0 {
+		return fmt.Errorf("max retries cannot be negative")
+	}
+	if o.MaxRetries > 0 && o.RetryBackoff < 0 {
+		return fmt.Errorf("retry backoff cannot be negative")
+	}
+	return nil
+}
+
+// TxFunc is the body of a transaction. The context carries the transaction, so clients
+// and repositories called with it join the transaction.
+type TxFunc func(ctx context.Context, tx Transaction) error
+
+// TxBeginner starts transactions with options
+type TxBeginner interface {
+	BeginTxWithOptions(ctx context.Context, opts TxOptions) (Transaction, error)
+}
+
+// txKey is the context key of the active transaction
+type txKey struct{}
+
+// txState is the active transaction stored in the context
+type txState struct {
+	tx         Transaction
+	owner      TxBeginner
+	savepoints int
+}
+
+// TxFromContext returns the transaction started by WithTx that ctx belongs to
+func TxFromContext(ctx context.Context) (Transaction, bool) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return state.tx, true
+}
+
+// IsRetryable reports whether err is a serialization failure or deadlock, after which
+// the whole transaction can be run again
+func IsRetryable(err error) bool {
+	var sqlErr interface{ SQLState() string }
+	if !errors.As(err, &sqlErr) {
+		return false
+	}
+	code := sqlErr.SQLState()
+	return code == SQLStateSerializationFailure || code == SQLStateDeadlockDetected
+}
+
+// RunTx runs fn in a transaction of db, committing when it returns nil and rolling back
+// when it returns an error or panics. Serialization failures and deadlocks are retried
+// up to opts.MaxRetries times, so fn must be safe to run more than once.
+//
+// When ctx already carries a transaction of db, fn runs inside a savepoint of it instead:
+// an error rolls back to the savepoint only, and retries are left to the outermost call.
+// Drivers implement WithTx with it.
+func RunTx(ctx context.Context, db TxBeginner, opts TxOptions, fn TxFunc) error {
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("invalid transaction options: %w", err)
+	}
+
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.owner == db {
+		return runSavepoint(ctx, state, fn)
+	}
+
+	backoff := opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := runTx(ctx, db, opts, fn)
+		if err == nil || !IsRetryable(err) || attempt >= opts.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if opts.MaxRetryBackoff > 0 && backoff > opts.MaxRetryBackoff {
+			backoff = opts.MaxRetryBackoff
+		}
+	}
+}
+
+// runTx runs one attempt of a transaction
+func runTx(ctx context.Context, db TxBeginner, opts TxOptions, fn TxFunc) (err error) {
+	tx, err := db.BeginTxWithOptions(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	txCtx := context.WithValue(ctx, txKey{}, &txState{tx: tx, owner: db})
+	if err := fn(txCtx, tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// runSavepoint runs fn inside a savepoint of the active transaction
+func runSavepoint(ctx context.Context, state *txState, fn TxFunc) (err error) {
+	state.savepoints++
+	name := fmt.Sprintf("sp_%d", state.savepoints)
+
+	if err := state.tx.Exec(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = state.tx.Exec(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+
+	if err := fn(ctx, state.tx); err != nil {
+		if rbErr := state.tx.Exec(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := state.tx.Exec(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
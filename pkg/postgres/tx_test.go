@@ -0,0 +1,193 @@
+package _postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// sqlStateError mimics a driver error carrying a SQLSTATE code
+type sqlStateError struct {
+	code string
+}
+
+func (e *sqlStateError) Error() string {
+	return "sqlstate " + e.code
+}
+
+func (e *sqlStateError) SQLState() string {
+	return e.code
+}
+
+// fakeTx records the statements and outcome of a transaction
+type fakeTx struct {
+	statements []string
+	committed  bool
+	rolledBack bool
+	commitErr  error
+}
+
+func (t *fakeTx) Commit() error {
+	t.committed = true
+	return t.commitErr
+}
+
+func (t *fakeTx) Rollback() error {
+	t.rolledBack = true
+	return nil
+}
+
+func (t *fakeTx) Exec(ctx context.Context, query string, args ...any) error {
+	t.statements = append(t.statements, query)
+	return nil
+}
+
+func (t *fakeTx) Query(ctx context.Context, query string, args ...any) (Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (t *fakeTx) QueryRow(ctx context.Context, query string, args ...any) Row {
+	return nil
+}
+
+// fakeBeginner hands out fake transactions
+type fakeBeginner struct {
+	txs []*fakeTx
+}
+
+func (b *fakeBeginner) BeginTxWithOptions(ctx context.Context, opts TxOptions) (Transaction, error) {
+	tx := &fakeTx{}
+	b.txs = append(b.txs, tx)
+	return tx, nil
+}
+
+func testTxOptions() TxOptions {
+	opts := DefaultTxOptions()
+	opts.RetryBackoff = 0
+	return opts
+}
+
+func TestRunTx(t *testing.T) {
+	db := &fakeBeginner{}
+
+	err := RunTx(context.Background(), db, testTxOptions(), func(ctx context.Context, tx Transaction) error {
+		if got, ok := TxFromContext(ctx); !ok || got != tx {
+			t.Errorf("TxFromContext() = %v, %v, want the active transaction", got, ok)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunTx() error = %v", err)
+	}
+	if len(db.txs) != 1 || !db.txs[0].committed || db.txs[0].rolledBack {
+		t.Errorf("RunTx() did not commit exactly one transaction")
+	}
+
+	wantErr := errors.New("boom")
+	err = RunTx(context.Background(), db, testTxOptions(), func(ctx context.Context, tx Transaction) error {
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("RunTx() error = %v, want %v", err, wantErr)
+	}
+	if last := db.txs[len(db.txs)-1]; last.committed || !last.rolledBack {
+		t.Errorf("RunTx() did not roll back on error")
+	}
+}
+
+func TestRunTxRetry(t *testing.T) {
+	db := &fakeBeginner{}
+
+	attempts := 0
+	err := RunTx(context.Background(), db, testTxOptions(), func(ctx context.Context, tx Transaction) error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("failed to update: %w", &sqlStateError{code: SQLStateSerializationFailure})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunTx() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("RunTx() attempts = %v, want %v", attempts, 3)
+	}
+
+	// Retries stop after MaxRetries, and failures at commit are retried too
+	db = &fakeBeginner{}
+	opts := testTxOptions()
+	opts.MaxRetries = 2
+	attempts = 0
+	err = RunTx(context.Background(), db, opts, func(ctx context.Context, tx Transaction) error {
+		attempts++
+		tx.(*fakeTx).commitErr = &sqlStateError{code: SQLStateDeadlockDetected}
+		return nil
+	})
+	if !IsRetryable(err) {
+		t.Errorf("RunTx() error = %v, want a deadlock", err)
+	}
+	if attempts != 3 {
+		t.Errorf("RunTx() attempts = %v, want %v", attempts, 3)
+	}
+
+	// Other errors are not retried
+	attempts = 0
+	_ = RunTx(context.Background(), db, testTxOptions(), func(ctx context.Context, tx Transaction) error {
+		attempts++
+		return &sqlStateError{code: "23505"}
+	})
+	if attempts != 1 {
+		t.Errorf("RunTx() attempts = %v, want %v", attempts, 1)
+	}
+}
+
+func TestRunTxSavepoint(t *testing.T) {
+	db := &fakeBeginner{}
+
+	err := RunTx(context.Background(), db, testTxOptions(), func(ctx context.Context, tx Transaction) error {
+		if err := RunTx(ctx, db, testTxOptions(), func(ctx context.Context, tx Transaction) error {
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := RunTx(ctx, db, testTxOptions(), func(ctx context.Context, tx Transaction) error {
+			return errors.New("nested failure")
+		}); err == nil {
+			t.Errorf("RunTx() nested error = nil, want error")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunTx() error = %v", err)
+	}
+
+	if len(db.txs) != 1 {
+		t.Fatalf("RunTx() began %d transactions, want 1", len(db.txs))
+	}
+	want := []string{"SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1", "SAVEPOINT sp_2", "ROLLBACK TO SAVEPOINT sp_2"}
+	got := db.txs[0].statements
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("RunTx() statements = %v, want %v", got, want)
+	}
+	if !db.txs[0].committed {
+		t.Errorf("RunTx() did not commit the outer transaction")
+	}
+}
+
+func TestRunTxPanic(t *testing.T) {
+	db := &fakeBeginner{}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RunTx() did not propagate the panic")
+		}
+		if !db.txs[0].rolledBack {
+			t.Errorf("RunTx() did not roll back on panic")
+		}
+	}()
+
+	_ = RunTx(context.Background(), db, testTxOptions(), func(ctx context.Context, tx Transaction) error {
+		panic("boom")
+	})
+}
@@ -0,0 +1,292 @@
+package _queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrJobNotClaimed is returned by a Queue when completing, retrying, killing or extending a
+// job that is no longer claimed by the caller
+var ErrJobNotClaimed = errors.New("queue: job not claimed")
+
+// Queue is a job store a Pool claims jobs from and records their outcome in
+type Queue[J any] interface {
+	// Claim takes up to limit due jobs
+	Claim(ctx context.Context, limit int) ([]J, error)
+	// Extend keeps a running job claimed
+	Extend(ctx context.Context, job J) error
+	// Complete removes a finished job
+	Complete(ctx context.Context, job J) error
+	// Retry schedules a failed job again, or marks it dead when it has no attempts left
+	Retry(ctx context.Context, job J, cause error) error
+	// Kill marks a job dead without retrying it
+	Kill(ctx context.Context, job J, cause error) error
+}
+
+// HandleFunc runs a claimed job. Returning an error retries the job, unless it is wrapped
+// with Permanent.
+type HandleFunc[J any] func(ctx context.Context, job J) error
+
+// permanentError is a failure that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err so the job is killed instead of retried
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// PoolConfig holds the configuration of a Pool
+type PoolConfig struct {
+	// Concurrency is the number of jobs processed at the same time
+	Concurrency int
+	// PollInterval is how often the queue is polled when it is idle
+	PollInterval time.Duration
+	// ShutdownTimeout bounds how long Stop waits for running jobs
+	ShutdownTimeout time.Duration
+	// HeartbeatInterval is how often a running job is extended
+	HeartbeatInterval time.Duration
+	// BeforePoll is called before every poll, for example to hand out the jobs of dead
+	// workers again. It may be nil.
+	BeforePoll func(ctx context.Context)
+}
+
+// Pool is a pool of goroutines that claims jobs from a queue and runs them. Running jobs are
+// extended every HeartbeatInterval, and a job that is no longer claimed has its context
+// cancelled so it does not keep running next to the worker it was handed to.
+type Pool[J any] struct {
+	queue  Queue[J]
+	handle HandleFunc[J]
+	config PoolConfig
+
+	// Control
+	cancel    context.CancelFunc
+	jobCancel context.CancelFunc
+	doneCh    chan struct{}
+	jobs      sync.WaitGroup
+
+	// State
+	running bool
+	mu      sync.Mutex
+}
+
+// NewPool creates a pool running the jobs of queue with handle
+func NewPool[J any](queue Queue[J], handle HandleFunc[J], config PoolConfig) *Pool[J] {
+	return &Pool[J]{
+		queue:  queue,
+		handle: handle,
+		config: config,
+	}
+}
+
+// Start begins polling the queue
+func (p *Pool[J]) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		return fmt.Errorf("worker already started")
+	}
+
+	pollCtx, cancel := context.WithCancel(ctx)
+	jobCtx, jobCancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	p.jobCancel = jobCancel
+	p.doneCh = make(chan struct{})
+	p.running = true
+
+	go p.poll(pollCtx, jobCtx, p.doneCh)
+
+	return nil
+}
+
+// Stop stops claiming jobs and waits up to ShutdownTimeout for running jobs to finish.
+// Jobs still running after the timeout are cancelled and left claimed, so the queue hands
+// them out again once their claim expires.
+func (p *Pool[J]) Stop() error {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return nil
+	}
+	cancel, jobCancel, doneCh := p.cancel, p.jobCancel, p.doneCh
+	p.mu.Unlock()
+
+	// running stays set until the jobs are done so Start cannot reuse the wait group
+	cancel()
+	<-doneCh
+
+	finished := make(chan struct{})
+	go func() {
+		p.jobs.Wait()
+		close(finished)
+	}()
+
+	var err error
+	select {
+	case <-finished:
+	case <-time.After(p.config.ShutdownTimeout):
+		jobCancel()
+		<-finished
+		err = errors.New("shutdown timeout exceeded, running jobs were cancelled")
+	}
+
+	jobCancel()
+
+	p.mu.Lock()
+	p.running = false
+	p.mu.Unlock()
+
+	return err
+}
+
+// IsRunning checks if the pool is active
+func (p *Pool[J]) IsRunning() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running
+}
+
+// poll claims jobs while there is free capacity
+func (p *Pool[J]) poll(ctx context.Context, jobCtx context.Context, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	slots := make(chan struct{}, p.config.Concurrency)
+
+	for {
+		if p.config.BeforePoll != nil {
+			p.config.BeforePoll(ctx)
+		}
+
+		free := p.config.Concurrency - len(slots)
+
+		var jobs []J
+		if free > 0 {
+			var err error
+			jobs, err = p.queue.Claim(ctx, free)
+			if err != nil && ctx.Err() == nil {
+				fmt.Printf("Failed to claim jobs: %v\n", err)
+			}
+		}
+
+		for _, job := range jobs {
+			slots <- struct{}{}
+			p.jobs.Add(1)
+			go func(job J) {
+				defer func() {
+					<-slots
+					p.jobs.Done()
+				}()
+				p.process(jobCtx, job)
+			}(job)
+		}
+
+		// Poll again right away while the queue keeps the pool busy
+		if len(jobs) > 0 && len(jobs) == free {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.config.PollInterval):
+		}
+	}
+}
+
+// process runs a job and records the outcome
+func (p *Pool[J]) process(jobCtx context.Context, job J) {
+	// Use a context that survives cancellation so the outcome is always recorded
+	recordCtx := context.WithoutCancel(jobCtx)
+
+	ctx, cancel := context.WithCancel(jobCtx)
+	defer cancel()
+
+	stopHeartbeat := p.heartbeat(ctx, cancel, job)
+	err := p.run(ctx, job)
+	claimed := stopHeartbeat()
+
+	var permanent *permanentError
+	switch {
+	case !claimed:
+		// The job was handed to another worker, which records the outcome
+	case err == nil:
+		p.record(p.queue.Complete(recordCtx, job))
+	case jobCtx.Err() != nil:
+		// Cancelled by Stop, the job is handed out again once its claim expires instead of
+		// using up an attempt
+	case errors.As(err, &permanent):
+		p.record(p.queue.Kill(recordCtx, job, permanent.err))
+	default:
+		p.record(p.queue.Retry(recordCtx, job, err))
+	}
+}
+
+// run calls the handler, converting panics into errors
+func (p *Pool[J]) run(ctx context.Context, job J) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return p.handle(ctx, job)
+}
+
+// heartbeat extends the claim of a running job until the returned function is called,
+// which reports whether the job is still claimed. It calls cancel when the job is no
+// longer claimed.
+func (p *Pool[J]) heartbeat(ctx context.Context, cancel context.CancelFunc, job J) func() bool {
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	claimed := true
+
+	go func() {
+		defer close(doneCh)
+
+		ticker := time.NewTicker(p.config.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.queue.Extend(ctx, job); errors.Is(err, ErrJobNotClaimed) {
+					claimed = false
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return func() bool {
+		close(stopCh)
+		<-doneCh
+		return claimed
+	}
+}
+
+// record logs failures to update the job state
+func (p *Pool[J]) record(err error) {
+	if err != nil && !errors.Is(err, ErrJobNotClaimed) {
+		fmt.Printf("Failed to update job state: %v\n", err)
+	}
+}
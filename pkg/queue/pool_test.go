@@ -0,0 +1,189 @@
+package _queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeQueue hands out pending jobs once and records their outcome
+type fakeQueue struct {
+	mu        sync.Mutex
+	pending   []int
+	extendErr error
+	outcomes  chan string
+}
+
+func (q *fakeQueue) Claim(ctx context.Context, limit int) ([]int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := min(limit, len(q.pending))
+	jobs := q.pending[:n]
+	q.pending = q.pending[n:]
+	return jobs, nil
+}
+
+func (q *fakeQueue) Extend(ctx context.Context, job int) error {
+	return q.extendErr
+}
+
+func (q *fakeQueue) Complete(ctx context.Context, job int) error {
+	q.outcomes <- "complete"
+	return nil
+}
+
+func (q *fakeQueue) Retry(ctx context.Context, job int, cause error) error {
+	q.outcomes <- "retry: " + cause.Error()
+	return nil
+}
+
+func (q *fakeQueue) Kill(ctx context.Context, job int, cause error) error {
+	q.outcomes <- "kill: " + cause.Error()
+	return nil
+}
+
+func TestPoolOutcome(t *testing.T) {
+	tests := []struct {
+		name      string
+		handle    HandleFunc[int]
+		extendErr error
+		stop      bool
+		want      string
+	}{
+		{
+			name:   "success",
+			handle: func(ctx context.Context, job int) error { return nil },
+			want:   "complete",
+		},
+		{
+			name:   "failure",
+			handle: func(ctx context.Context, job int) error { return errors.New("timeout") },
+			want:   "retry: timeout",
+		},
+		{
+			name:   "permanent failure",
+			handle: func(ctx context.Context, job int) error { return Permanent(errors.New("bad payload")) },
+			want:   "kill: bad payload",
+		},
+		{
+			name:   "panic",
+			handle: func(ctx context.Context, job int) error { panic("boom") },
+			want:   "retry: job panicked: boom",
+		},
+		{
+			// The job was handed to another worker, the handler is stopped and records nothing
+			name: "claim lost",
+			handle: func(ctx context.Context, job int) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			extendErr: ErrJobNotClaimed,
+		},
+		{
+			// Cancelled by Stop, the job is left for the queue to hand out again
+			name: "shutdown timeout",
+			handle: func(ctx context.Context, job int) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			stop: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &fakeQueue{pending: []int{1}, extendErr: tt.extendErr, outcomes: make(chan string, 1)}
+			done := make(chan struct{})
+			pool := NewPool(queue, func(ctx context.Context, job int) error {
+				defer close(done)
+				return tt.handle(ctx, job)
+			}, PoolConfig{
+				Concurrency:       1,
+				PollInterval:      10 * time.Millisecond,
+				HeartbeatInterval: 10 * time.Millisecond,
+				ShutdownTimeout:   time.Second,
+			})
+			if tt.stop {
+				pool.config.ShutdownTimeout = 50 * time.Millisecond
+			}
+
+			if err := pool.Start(context.Background()); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if !tt.stop {
+				select {
+				case <-done:
+				case <-time.After(2 * time.Second):
+					t.Fatalf("timed out waiting for the handler")
+				}
+			}
+
+			// Stop waits for the outcome to be recorded
+			if err := pool.Stop(); (err != nil) != tt.stop {
+				t.Errorf("Stop() error = %v, want error %v", err, tt.stop)
+			}
+
+			var got string
+			select {
+			case got = <-queue.outcomes:
+			default:
+			}
+			if got != tt.want {
+				t.Errorf("outcome = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolStopWhileJobsStart(t *testing.T) {
+	const jobs = 50
+	queue := &fakeQueue{outcomes: make(chan string, jobs)}
+	for i := 0; i < jobs; i++ {
+		queue.pending = append(queue.pending, i)
+	}
+
+	var pool *Pool[int]
+	started := make(chan struct{}, jobs)
+	pool = NewPool(queue, func(ctx context.Context, job int) error {
+		started <- struct{}{}
+		// Handlers may inspect the pool while it is stopping
+		pool.IsRunning()
+		return nil
+	}, PoolConfig{
+		Concurrency:       jobs,
+		PollInterval:      10 * time.Millisecond,
+		ShutdownTimeout:   time.Second,
+		HeartbeatInterval: time.Second,
+	})
+
+	ctx := context.Background()
+	if err := pool.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Stop as soon as the first job runs, while the others are still starting
+	<-started
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- pool.Stop()
+	}()
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Stop() did not return")
+	}
+
+	if pool.IsRunning() {
+		t.Errorf("IsRunning() = true after Stop")
+	}
+	if err := pool.Start(ctx); err != nil {
+		t.Errorf("Start() after Stop error = %v", err)
+	}
+	pool.Stop()
+}
@@ -0,0 +1,370 @@
+package _redis
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	// ErrCacheMiss is returned when a key is not present in the cache
+	ErrCacheMiss = errors.New("redis: cache miss")
+	// ErrNotFound is returned by loaders when the value does not exist in the source.
+	// It is cached for NegativeTTL so repeated lookups do not hit the source.
+	ErrNotFound = errors.New("redis: not found")
+)
+
+const (
+	entryValue    byte = 1
+	entryNegative byte = 2
+
+	// entry header: kind (1 byte) + compute time (8 bytes) + expiry (8 bytes)
+	entryHeaderSize = 17
+)
+
+// CacheConfig holds the configuration for a cache
+type CacheConfig struct {
+	// KeyPrefix is prepended to every cache key
+	KeyPrefix string
+	// DefaultTTL is used when a TTL of 0 is passed to Set, MSet or GetOrLoad
+	DefaultTTL time.Duration
+	// NegativeTTL is how long ErrNotFound results are cached, 0 disables negative caching
+	NegativeTTL time.Duration
+	// Jitter randomizes TTLs by up to this fraction to avoid synchronized expiry
+	Jitter float64
+	// EarlyRefreshBeta controls probabilistic early refresh (XFetch), 0 disables it.
+	// Values above 1 favour earlier refreshes.
+	EarlyRefreshBeta float64
+	// Codec serializes cached values, defaults to JSONCodec
+	Codec Codec
+}
+
+// DefaultCacheConfig returns a cache configuration with sensible defaults
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		KeyPrefix:        "cache:",
+		DefaultTTL:       5 * time.Minute,
+		NegativeTTL:      30 * time.Second,
+		Jitter:           0.1,
+		EarlyRefreshBeta: 1,
+		Codec:            JSONCodec{},
+	}
+}
+
+// Validate checks if the cache configuration is valid
+func (c *CacheConfig) Validate() error {
+	if c.DefaultTTL <= 0 {
+		return errors.New("default ttl must be greater than 0")
+	}
+	if c.NegativeTTL < 0 {
+		return errors.New("negative ttl must be greater than or equal to 0")
+	}
+	if c.Jitter < 0 || c.Jitter >= 1 {
+		return errors.New("jitter must be between 0 and 1")
+	}
+	if c.EarlyRefreshBeta < 0 {
+		return errors.New("early refresh beta must be greater than or equal to 0")
+	}
+	return nil
+}
+
+// Cache is a typed cache-aside helper on top of a Redis client
+type Cache[T any] struct {
+	client redis.UniversalClient
+	config CacheConfig
+	group  singleflight.Group
+}
+
+// cacheEntry is the decoded form of a value stored in Redis
+type cacheEntry[T any] struct {
+	value    T
+	negative bool
+	delta    time.Duration // how long the value took to compute
+	expiry   time.Time
+}
+
+// NewCache creates a new cache backed by the given client
+func NewCache[T any](client redis.UniversalClient, config CacheConfig) (*Cache[T], error) {
+	if client == nil {
+		return nil, errors.New("redis client is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cache config: %w", err)
+	}
+	if config.Codec == nil {
+		config.Codec = JSONCodec{}
+	}
+
+	return &Cache[T]{
+		client: client,
+		config: config,
+	}, nil
+}
+
+// Get returns the cached value, ErrCacheMiss if it is absent or ErrNotFound if a
+// negative result is cached
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, error) {
+	var zero T
+
+	entry, err := c.get(ctx, key)
+	if err != nil {
+		return zero, err
+	}
+	if entry.negative {
+		return zero, ErrNotFound
+	}
+
+	return entry.value, nil
+}
+
+// Set stores a value with the given TTL
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, ttl, err := c.encode(value, false, 0, ttl)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(ctx, c.key(key), data, ttl).Err()
+}
+
+// Delete removes the given keys from the cache
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// Keys may live in different cluster slots, so delete them one by one in a pipeline
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, c.key(key))
+		}
+		return nil
+	})
+
+	return err
+}
+
+// GetOrLoad returns the cached value or calls loader to compute it. Concurrent calls for
+// the same key share a single loader call, which runs detached from the caller's
+// cancellation so one cancelled request does not fail the other waiters. Values close to
+// expiry are refreshed early with a probability that grows as expiry approaches. If
+// Redis is unavailable the loader is called directly.
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+
+	entry, err := c.get(ctx, key)
+	if err == nil && !c.shouldRefresh(entry) {
+		if entry.negative {
+			return zero, ErrNotFound
+		}
+		return entry.value, nil
+	}
+
+	resultCh := c.group.DoChan(key, func() (any, error) {
+		return c.load(context.WithoutCancel(ctx), key, ttl, loader)
+	})
+
+	var result singleflight.Result
+	select {
+	case result = <-resultCh:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+
+	if loadErr := result.Err; loadErr != nil {
+		// Serve the stale value if an early refresh failed
+		if err == nil && !entry.negative && !errors.Is(loadErr, ErrNotFound) {
+			return entry.value, nil
+		}
+		return zero, loadErr
+	}
+
+	// A nil value of an interface type T does not hold a T
+	value, _ := result.Val.(T)
+	return value, nil
+}
+
+// MGet returns the cached values for the given keys. Missing and negative entries
+// are omitted from the result.
+func (c *Cache[T]) MGet(ctx context.Context, keys []string) (map[string]T, error) {
+	result := make(map[string]T, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	// A pipeline of GETs works across cluster slots, unlike MGET
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, c.key(key))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if err != nil {
+			continue
+		}
+
+		entry, err := c.decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key %s: %w", keys[i], err)
+		}
+		if !entry.negative {
+			result[keys[i]] = entry.value
+		}
+	}
+
+	return result, nil
+}
+
+// MSet stores multiple values with the same TTL in a single pipeline
+func (c *Cache[T]) MSet(ctx context.Context, items map[string]T, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range items {
+			data, itemTTL, err := c.encode(value, false, 0, ttl)
+			if err != nil {
+				return fmt.Errorf("failed to encode key %s: %w", key, err)
+			}
+			pipe.Set(ctx, c.key(key), data, itemTTL)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// load calls the loader and stores its result
+func (c *Cache[T]) load(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+
+	start := time.Now()
+	value, err := loader(ctx)
+	delta := time.Since(start)
+
+	if errors.Is(err, ErrNotFound) {
+		if c.config.NegativeTTL > 0 {
+			if data, negativeTTL, encErr := c.encode(zero, true, delta, c.config.NegativeTTL); encErr == nil {
+				c.client.Set(ctx, c.key(key), data, negativeTTL)
+			}
+		}
+		return zero, ErrNotFound
+	}
+	if err != nil {
+		return zero, err
+	}
+
+	// A failed write only means the next call loads again
+	if data, valueTTL, encErr := c.encode(value, false, delta, ttl); encErr == nil {
+		c.client.Set(ctx, c.key(key), data, valueTTL)
+	}
+
+	return value, nil
+}
+
+// get fetches and decodes a cache entry
+func (c *Cache[T]) get(ctx context.Context, key string) (cacheEntry[T], error) {
+	data, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return cacheEntry[T]{}, ErrCacheMiss
+	}
+	if err != nil {
+		return cacheEntry[T]{}, err
+	}
+
+	return c.decode(data)
+}
+
+// shouldRefresh implements XFetch: a value is recomputed early when
+// now - delta * beta * ln(rand) >= expiry
+func (c *Cache[T]) shouldRefresh(entry cacheEntry[T]) bool {
+	if c.config.EarlyRefreshBeta == 0 || entry.delta <= 0 {
+		return false
+	}
+
+	gap := float64(entry.delta) * c.config.EarlyRefreshBeta * -math.Log(1-rand.Float64())
+	return !time.Now().Add(time.Duration(gap)).Before(entry.expiry)
+}
+
+// encode serializes a value with its metadata header and returns the jittered TTL
+func (c *Cache[T]) encode(value T, negative bool, delta, ttl time.Duration) ([]byte, time.Duration, error) {
+	if ttl <= 0 {
+		ttl = c.config.DefaultTTL
+	}
+	ttl = c.jitter(ttl)
+
+	kind := entryValue
+	var payload []byte
+	if negative {
+		kind = entryNegative
+	} else {
+		var err error
+		payload, err = c.config.Codec.Marshal(value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal value: %w", err)
+		}
+	}
+
+	data := make([]byte, entryHeaderSize, entryHeaderSize+len(payload))
+	data[0] = kind
+	binary.BigEndian.PutUint64(data[1:9], uint64(delta))
+	binary.BigEndian.PutUint64(data[9:17], uint64(time.Now().Add(ttl).UnixNano()))
+	data = append(data, payload...)
+
+	return data, ttl, nil
+}
+
+// decode parses a value written by encode
+func (c *Cache[T]) decode(data []byte) (cacheEntry[T], error) {
+	var entry cacheEntry[T]
+
+	if len(data) < entryHeaderSize {
+		return entry, errors.New("invalid cache entry")
+	}
+
+	entry.delta = time.Duration(binary.BigEndian.Uint64(data[1:9]))
+	entry.expiry = time.Unix(0, int64(binary.BigEndian.Uint64(data[9:17])))
+
+	switch data[0] {
+	case entryNegative:
+		entry.negative = true
+	case entryValue:
+		if err := c.config.Codec.Unmarshal(data[entryHeaderSize:], &entry.value); err != nil {
+			return entry, fmt.Errorf("failed to unmarshal value: %w", err)
+		}
+	default:
+		return entry, errors.New("invalid cache entry")
+	}
+
+	return entry, nil
+}
+
+// jitter randomizes the TTL by up to ±Jitter
+func (c *Cache[T]) jitter(ttl time.Duration) time.Duration {
+	if c.config.Jitter == 0 {
+		return ttl
+	}
+
+	offset := (rand.Float64()*2 - 1) * c.config.Jitter * float64(ttl)
+	return ttl + time.Duration(offset)
+}
+
+// key returns the prefixed Redis key
+func (c *Cache[T]) key(key string) string {
+	return c.config.KeyPrefix + key
+}
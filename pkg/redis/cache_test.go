@@ -0,0 +1,202 @@
+package _redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type cachedUser struct {
+	ID   int
+	Name string
+}
+
+func TestCacheEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec Codec
+	}{
+		{name: "JSON", codec: JSONCodec{}},
+		{name: "Msgpack", codec: MsgpackCodec{}},
+		{name: "Gob", codec: GobCodec{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Cache[cachedUser]{config: CacheConfig{DefaultTTL: time.Minute, Codec: tt.codec}}
+			value := cachedUser{ID: 7, Name: "alice"}
+
+			data, ttl, err := c.encode(value, false, 50*time.Millisecond, 0)
+			if err != nil {
+				t.Fatalf("encode() error = %v", err)
+			}
+			if ttl != time.Minute {
+				t.Errorf("encode() ttl = %v, want %v", ttl, time.Minute)
+			}
+
+			entry, err := c.decode(data)
+			if err != nil {
+				t.Fatalf("decode() error = %v", err)
+			}
+			if entry.negative {
+				t.Errorf("decode() negative = true, want false")
+			}
+			if entry.value != value {
+				t.Errorf("decode() value = %v, want %v", entry.value, value)
+			}
+			if entry.delta != 50*time.Millisecond {
+				t.Errorf("decode() delta = %v, want %v", entry.delta, 50*time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestCacheEncodeNegative(t *testing.T) {
+	c := &Cache[cachedUser]{config: CacheConfig{DefaultTTL: time.Minute, Codec: JSONCodec{}}}
+
+	data, _, err := c.encode(cachedUser{}, true, 0, time.Second)
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+
+	entry, err := c.decode(data)
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if !entry.negative {
+		t.Errorf("decode() negative = false, want true")
+	}
+}
+
+func TestCacheJitter(t *testing.T) {
+	c := &Cache[cachedUser]{config: CacheConfig{Jitter: 0.2}}
+
+	for i := 0; i < 100; i++ {
+		ttl := c.jitter(10 * time.Second)
+		if ttl < 8*time.Second || ttl > 12*time.Second {
+			t.Fatalf("jitter() = %v, want within [8s, 12s]", ttl)
+		}
+	}
+}
+
+func TestCacheShouldRefresh(t *testing.T) {
+	c := &Cache[cachedUser]{config: CacheConfig{EarlyRefreshBeta: 1}}
+
+	expired := cacheEntry[cachedUser]{delta: time.Second, expiry: time.Now().Add(-time.Second)}
+	if !c.shouldRefresh(expired) {
+		t.Errorf("shouldRefresh() = false for expired entry, want true")
+	}
+
+	fresh := cacheEntry[cachedUser]{delta: time.Millisecond, expiry: time.Now().Add(time.Hour)}
+	if c.shouldRefresh(fresh) {
+		t.Errorf("shouldRefresh() = true for fresh entry, want false")
+	}
+}
+
+func TestCacheGetOrLoadValue(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	config := DefaultCacheConfig()
+	config.EarlyRefreshBeta = 0
+
+	cache, err := NewCache[any](client, config)
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		load any
+		want any
+	}{
+		{name: "Nil interface value", load: nil, want: nil},
+		{name: "String value", load: "alice", want: "alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := cache.GetOrLoad(context.Background(), tt.name, 0, func(ctx context.Context) (any, error) {
+				return tt.load, nil
+			})
+			if err != nil {
+				t.Fatalf("GetOrLoad() error = %v", err)
+			}
+			if value != tt.want {
+				t.Errorf("GetOrLoad() = %v, want %v", value, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheGetOrLoadCancelledWaiter(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	config := DefaultCacheConfig()
+	config.EarlyRefreshBeta = 0
+
+	cache, err := NewCache[cachedUser](client, config)
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (cachedUser, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		if err := ctx.Err(); err != nil {
+			return cachedUser{}, err
+		}
+		return cachedUser{ID: 1, Name: "alice"}, nil
+	}
+
+	// The first caller starts the load and gives up
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := cache.GetOrLoad(firstCtx, "user:1", 0, loader)
+		firstErr <- err
+	}()
+	<-started
+
+	// The second caller joins the same load
+	type result struct {
+		value cachedUser
+		err   error
+	}
+	secondResult := make(chan result, 1)
+	go func() {
+		value, err := cache.GetOrLoad(context.Background(), "user:1", 0, loader)
+		secondResult <- result{value: value, err: err}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("GetOrLoad() cancelled caller error = %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+	second := <-secondResult
+	if second.err != nil {
+		t.Fatalf("GetOrLoad() waiter error = %v", second.err)
+	}
+	if second.value.Name != "alice" {
+		t.Errorf("GetOrLoad() waiter value = %v, want alice", second.value)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("loader calls = %d, want 1", calls.Load())
+	}
+}
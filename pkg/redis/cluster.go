@@ -13,6 +13,7 @@ type ClusterConnection struct {
 	config       ClusterConfig
 	masterClient *redis.ClusterClient
 	slaveClient  *redis.ClusterClient
+	hooks        []redis.Hook
 }
 
 // NewClusterConnection creates a new Redis cluster connection
@@ -31,14 +32,16 @@ func (c *ClusterConnection) Connect(ctx context.Context) error {
 	connectCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	options, err := c.config.ClusterOptions()
+	if err != nil {
+		return fmt.Errorf("invalid cluster config: %w", err)
+	}
+
 	// Create the master client for write operations
-	c.masterClient = redis.NewClusterClient(&redis.ClusterOptions{
-		Addrs:          c.config.Addresses,
-		Password:       c.config.Password,
-		RouteByLatency: c.config.RouteByLatency,
-		RouteRandomly:  c.config.RouteRandomly,
-		MaxRedirects:   c.config.MaxRedirects,
-	})
+	c.masterClient = redis.NewClusterClient(options)
+	for _, hook := range c.hooks {
+		c.masterClient.AddHook(hook)
+	}
 
 	// Test the master connection
 	if err := c.masterClient.Ping(connectCtx).Err(); err != nil {
@@ -48,14 +51,13 @@ func (c *ClusterConnection) Connect(ctx context.Context) error {
 	// If slave connection is requested, create it
 	if c.config.UseSlaveConnection {
 		// Create a separate client for read-only operations
-		c.slaveClient = redis.NewClusterClient(&redis.ClusterOptions{
-			Addrs:          c.config.Addresses,
-			Password:       c.config.Password,
-			RouteByLatency: c.config.RouteByLatency || c.config.SlaveReadOnly, // Prefer replicas for read operations
-			RouteRandomly:  c.config.RouteRandomly,
-			MaxRedirects:   c.config.MaxRedirects,
-			ReadOnly:       true, // This is the key setting for read-only mode
-		})
+		slaveOptions := *options
+		slaveOptions.RouteByLatency = c.config.RouteByLatency || c.config.SlaveReadOnly // Prefer replicas for read operations
+		slaveOptions.ReadOnly = true                                                    // This is the key setting for read-only mode
+		c.slaveClient = redis.NewClusterClient(&slaveOptions)
+		for _, hook := range c.hooks {
+			c.slaveClient.AddHook(hook)
+		}
 
 		// Test the slave connection
 		if err := c.slaveClient.Ping(connectCtx).Err(); err != nil {
@@ -119,5 +121,17 @@ func (c *ClusterConnection) HasSlaveConnected() bool {
 	return c.slaveClient != nil
 }
 
+// AddHook attaches a go-redis hook, such as NewInstrumentationHook, to the master and
+// slave clients. Hooks added before Connect are installed when the clients are created.
+func (c *ClusterConnection) AddHook(hook redis.Hook) {
+	c.hooks = append(c.hooks, hook)
+	if c.masterClient != nil {
+		c.masterClient.AddHook(hook)
+	}
+	if c.slaveClient != nil {
+		c.slaveClient.AddHook(hook)
+	}
+}
+
 // Ensure ClusterConnection implements ClusterClient interface
 var _ ClusterClient = (*ClusterConnection)(nil)
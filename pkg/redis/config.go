@@ -3,58 +3,158 @@ package _redis
 import (
 	"errors"
 	"strconv"
+	"time"
 )
 
 // Config represents configuration for a single Redis node
 type Config struct {
-	Host     string
-	Port     int
-	Password string
-	DB       int
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	DB       int    `json:"db" yaml:"db"`
+
+	TLS  TLSConfig  `json:"tls" yaml:"tls"`
+	Pool PoolConfig `json:"pool" yaml:"pool"`
 }
 
 // ClusterConfig represents configuration for a Redis cluster
 type ClusterConfig struct {
 	// Addresses is a list of Redis node addresses in the format "host:port"
-	Addresses []string
+	Addresses []string `json:"addresses" yaml:"addresses"`
+	// Username for Redis ACL authentication
+	Username string `json:"username" yaml:"username"`
 	// Password for the Redis cluster
-	Password string
+	Password string `json:"password" yaml:"password"`
 	// RouteByLatency allows routing read-only commands to the closest master or replica node
-	RouteByLatency bool
+	RouteByLatency bool `json:"route_by_latency" yaml:"route_by_latency"`
 	// RouteRandomly allows routing read-only commands randomly across master and replica nodes
-	RouteRandomly bool
+	RouteRandomly bool `json:"route_randomly" yaml:"route_randomly"`
 	// MaxRedirects limits the number of redirects before giving up
-	MaxRedirects int
+	MaxRedirects int `json:"max_redirects" yaml:"max_redirects"`
 	// UseSlaveConnection indicates whether to create a separate client for slave/read-only operations
-	UseSlaveConnection bool
+	UseSlaveConnection bool `json:"use_slave_connection" yaml:"use_slave_connection"`
 	// SlaveReadOnly forces slave client to read from replicas
-	SlaveReadOnly bool
+	SlaveReadOnly bool `json:"slave_read_only" yaml:"slave_read_only"`
+
+	TLS  TLSConfig  `json:"tls" yaml:"tls"`
+	Pool PoolConfig `json:"pool" yaml:"pool"`
 }
 
 // SentinelConfig represents configuration for Redis Sentinel
 type SentinelConfig struct {
 	// MasterName is the name of the master in Sentinel configuration
-	MasterName string
+	MasterName string `json:"master_name" yaml:"master_name"`
 	// SentinelAddresses is a list of Sentinel addresses in the format "host:port"
-	SentinelAddresses []string
+	SentinelAddresses []string `json:"sentinel_addresses" yaml:"sentinel_addresses"`
+	// Username for Redis ACL authentication on the master and replicas
+	Username string `json:"username" yaml:"username"`
 	// Password for the Redis master
-	Password string
+	Password string `json:"password" yaml:"password"`
 	// DB is the database to select
-	DB int
+	DB int `json:"db" yaml:"db"`
+	// SentinelUsername is the ACL username for Sentinel if different from Redis username
+	SentinelUsername string `json:"sentinel_username" yaml:"sentinel_username"`
 	// SentinelPassword is the password for Sentinel if different from Redis password
-	SentinelPassword string
+	SentinelPassword string `json:"sentinel_password" yaml:"sentinel_password"`
 	// UseSlaveConnection indicates whether to establish a connection to a slave/replica
-	UseSlaveConnection bool
+	UseSlaveConnection bool `json:"use_slave_connection" yaml:"use_slave_connection"`
 	// SlaveReadOnly forces slave connection to be read-only (recommended)
-	SlaveReadOnly bool
+	SlaveReadOnly bool `json:"slave_read_only" yaml:"slave_read_only"`
+	// ReplicaRouting selects how reads are spread across replicas: "random", "round_robin" or "latency"
+	ReplicaRouting string `json:"replica_routing" yaml:"replica_routing"`
+	// ReplicaRefreshInterval is how often replicas are rediscovered and health checked (0 uses 10s)
+	ReplicaRefreshInterval time.Duration `json:"replica_refresh_interval" yaml:"replica_refresh_interval"`
+	// MaxReplicaLag excludes replicas lagging behind the master by more than this (0 disables the check)
+	MaxReplicaLag time.Duration `json:"max_replica_lag" yaml:"max_replica_lag"`
+
+	TLS  TLSConfig  `json:"tls" yaml:"tls"`
+	Pool PoolConfig `json:"pool" yaml:"pool"`
+}
+
+// Mode is the Redis deployment mode
+type Mode string
+
+// Supported deployment modes
+const (
+	ModeSingle   Mode = "single"
+	ModeCluster  Mode = "cluster"
+	ModeSentinel Mode = "sentinel"
+)
+
+// UniversalConfig selects a deployment mode and holds the configuration for it
+type UniversalConfig struct {
+	// Mode selects which of the configurations below is used
+	Mode     Mode           `json:"mode" yaml:"mode"`
+	Single   Config         `json:"single" yaml:"single"`
+	Cluster  ClusterConfig  `json:"cluster" yaml:"cluster"`
+	Sentinel SentinelConfig `json:"sentinel" yaml:"sentinel"`
+}
+
+// TLSConfig represents TLS settings for connecting to Redis
+type TLSConfig struct {
+	// Enabled turns on TLS (implied by a rediss:// URL)
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// CAFile is a PEM file with the CA certificates used to verify the server
+	CAFile string `json:"ca_file" yaml:"ca_file"`
+	// CertFile and KeyFile are the client certificate for mutual TLS
+	CertFile string `json:"cert_file" yaml:"cert_file"`
+	KeyFile  string `json:"key_file" yaml:"key_file"`
+	// ServerName overrides the name used to verify the server certificate
+	ServerName string `json:"server_name" yaml:"server_name"`
+	// InsecureSkipVerify disables certificate verification (testing only)
+	InsecureSkipVerify bool `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+}
+
+// PoolConfig represents connection pool, timeout and retry settings
+type PoolConfig struct {
+	// Connection pool settings (0 uses the go-redis default)
+	PoolSize        int           `json:"pool_size" yaml:"pool_size"`
+	MinIdleConns    int           `json:"min_idle_conns" yaml:"min_idle_conns"`
+	MaxIdleConns    int           `json:"max_idle_conns" yaml:"max_idle_conns"`
+	ConnMaxIdleTime time.Duration `json:"conn_max_idle_time" yaml:"conn_max_idle_time"`
+	ConnMaxLifetime time.Duration `json:"conn_max_lifetime" yaml:"conn_max_lifetime"`
+	PoolTimeout     time.Duration `json:"pool_timeout" yaml:"pool_timeout"`
+
+	// Connection timeout settings
+	DialTimeout  time.Duration `json:"dial_timeout" yaml:"dial_timeout"`
+	ReadTimeout  time.Duration `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout"`
+
+	// Retry settings (MaxRetries -1 disables retries)
+	MaxRetries      int           `json:"max_retries" yaml:"max_retries"`
+	MinRetryBackoff time.Duration `json:"min_retry_backoff" yaml:"min_retry_backoff"`
+	MaxRetryBackoff time.Duration `json:"max_retry_backoff" yaml:"max_retry_backoff"`
+}
+
+// DefaultPoolConfig returns pool settings matching the go-redis defaults
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		PoolSize:        0,
+		MinIdleConns:    0,
+		MaxIdleConns:    0,
+		ConnMaxIdleTime: 30 * time.Minute,
+		ConnMaxLifetime: 0,
+		PoolTimeout:     4 * time.Second,
+
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 3 * time.Second,
+
+		MaxRetries:      3,
+		MinRetryBackoff: 8 * time.Millisecond,
+		MaxRetryBackoff: 512 * time.Millisecond,
+	}
 }
 
 func DefaultConfig() Config {
 	return Config{
 		Host:     "localhost",
 		Port:     6379,
+		Username: "",
 		Password: "",
 		DB:       0,
+		Pool:     DefaultPoolConfig(),
 	}
 }
 
@@ -67,18 +167,32 @@ func DefaultClusterConfig() ClusterConfig {
 		MaxRedirects:       3,
 		UseSlaveConnection: false,
 		SlaveReadOnly:      true,
+		Pool:               DefaultPoolConfig(),
 	}
 }
 
 func DefaultSentinelConfig() SentinelConfig {
 	return SentinelConfig{
-		MasterName:         "mymaster",
-		SentinelAddresses:  []string{"localhost:26379"},
-		Password:           "",
-		DB:                 0,
-		SentinelPassword:   "",
-		UseSlaveConnection: false,
-		SlaveReadOnly:      true,
+		MasterName:             "mymaster",
+		SentinelAddresses:      []string{"localhost:26379"},
+		Password:               "",
+		DB:                     0,
+		SentinelPassword:       "",
+		UseSlaveConnection:     false,
+		SlaveReadOnly:          true,
+		ReplicaRouting:         ReplicaRoutingRandom,
+		ReplicaRefreshInterval: defaultReplicaRefreshInterval,
+		MaxReplicaLag:          10 * time.Second,
+		Pool:                   DefaultPoolConfig(),
+	}
+}
+
+func DefaultUniversalConfig() UniversalConfig {
+	return UniversalConfig{
+		Mode:     ModeSingle,
+		Single:   DefaultConfig(),
+		Cluster:  DefaultClusterConfig(),
+		Sentinel: DefaultSentinelConfig(),
 	}
 }
 
@@ -92,7 +206,10 @@ func (c *Config) Validate() error {
 	if c.DB < 0 {
 		return errors.New("db must be greater than or equal to 0")
 	}
-	return nil
+	if err := c.TLS.Validate(); err != nil {
+		return err
+	}
+	return c.Pool.Validate()
 }
 
 func (c *Config) GetDNS() string {
@@ -106,7 +223,10 @@ func (c *ClusterConfig) Validate() error {
 	if c.MaxRedirects < 0 {
 		return errors.New("max redirects must be greater than or equal to 0")
 	}
-	return nil
+	if err := c.TLS.Validate(); err != nil {
+		return err
+	}
+	return c.Pool.Validate()
 }
 
 func (c *SentinelConfig) Validate() error {
@@ -119,5 +239,58 @@ func (c *SentinelConfig) Validate() error {
 	if c.DB < 0 {
 		return errors.New("db must be greater than or equal to 0")
 	}
+	switch c.ReplicaRouting {
+	case "", ReplicaRoutingRandom, ReplicaRoutingRoundRobin, ReplicaRoutingLatency:
+	default:
+		return errors.New("replica routing must be random, round_robin or latency")
+	}
+	if c.ReplicaRefreshInterval < 0 {
+		return errors.New("replica refresh interval must be greater than or equal to 0")
+	}
+	if c.MaxReplicaLag < 0 {
+		return errors.New("max replica lag must be greater than or equal to 0")
+	}
+	if err := c.TLS.Validate(); err != nil {
+		return err
+	}
+	return c.Pool.Validate()
+}
+
+func (c *UniversalConfig) Validate() error {
+	switch c.Mode {
+	case ModeSingle:
+		return c.Single.Validate()
+	case ModeCluster:
+		return c.Cluster.Validate()
+	case ModeSentinel:
+		return c.Sentinel.Validate()
+	default:
+		return errors.New("mode must be single, cluster or sentinel")
+	}
+}
+
+func (c *TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("tls cert file and key file must be set together")
+	}
+	return nil
+}
+
+func (c *PoolConfig) Validate() error {
+	if c.PoolSize < 0 {
+		return errors.New("pool size must be greater than or equal to 0")
+	}
+	if c.MinIdleConns < 0 || c.MaxIdleConns < 0 {
+		return errors.New("idle connections must be greater than or equal to 0")
+	}
+	if c.PoolSize > 0 && c.MinIdleConns > c.PoolSize {
+		return errors.New("min idle connections must not exceed pool size")
+	}
+	if c.MaxRetries < -1 {
+		return errors.New("max retries must be greater than or equal to -1")
+	}
+	if c.MaxRetryBackoff > 0 && c.MinRetryBackoff > c.MaxRetryBackoff {
+		return errors.New("min retry backoff must not exceed max retry backoff")
+	}
 	return nil
 }
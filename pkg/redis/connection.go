@@ -11,6 +11,7 @@ import (
 type Connection struct {
 	config Config
 	client *redis.Client
+	hooks  []redis.Hook
 }
 
 // NewConnection creates a new Redis connection
@@ -29,11 +30,15 @@ func (c *Connection) Connect(ctx context.Context) error {
 	connectCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	c.client = redis.NewClient(&redis.Options{
-		Addr:     c.config.GetDNS(),
-		Password: c.config.Password,
-		DB:       c.config.DB,
-	})
+	options, err := c.config.Options()
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	c.client = redis.NewClient(options)
+	for _, hook := range c.hooks {
+		c.client.AddHook(hook)
+	}
 
 	// Test the connection
 	if err := c.client.Ping(connectCtx).Err(); err != nil {
@@ -68,3 +73,12 @@ func (c *Connection) IsHealthy(ctx context.Context) bool {
 func (c *Connection) GetClient() *redis.Client {
 	return c.client
 }
+
+// AddHook attaches a go-redis hook, such as NewInstrumentationHook, to the client. Hooks
+// added before Connect are installed when the client is created.
+func (c *Connection) AddHook(hook redis.Hook) {
+	c.hooks = append(c.hooks, hook)
+	if c.client != nil {
+		c.client.AddHook(hook)
+	}
+}
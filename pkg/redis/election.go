@@ -0,0 +1,243 @@
+package _redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ElectionConfig holds the configuration for leader election
+type ElectionConfig struct {
+	// Name identifies the election, candidates with the same name compete for one lease
+	Name string `json:"name" yaml:"name"`
+	// KeyPrefix is prepended to the lease key
+	KeyPrefix string `json:"key_prefix" yaml:"key_prefix"`
+	// LeaseTTL is how long leadership survives without renewal, for example after a crash
+	LeaseTTL time.Duration `json:"lease_ttl" yaml:"lease_ttl"`
+	// RenewInterval is how often the leader renews its lease
+	RenewInterval time.Duration `json:"renew_interval" yaml:"renew_interval"`
+	// RetryInterval is how often followers try to take over the lease
+	RetryInterval time.Duration `json:"retry_interval" yaml:"retry_interval"`
+}
+
+// DefaultElectionConfig returns an election configuration with sensible defaults
+func DefaultElectionConfig() ElectionConfig {
+	return ElectionConfig{
+		KeyPrefix:     "leader:",
+		LeaseTTL:      15 * time.Second,
+		RenewInterval: 5 * time.Second,
+		RetryInterval: 2 * time.Second,
+	}
+}
+
+// Validate checks if the election configuration is valid
+func (c *ElectionConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	if c.LeaseTTL <= 0 {
+		return errors.New("lease ttl must be greater than 0")
+	}
+	if c.RenewInterval <= 0 || c.RenewInterval >= c.LeaseTTL {
+		return errors.New("renew interval must be between 0 and lease ttl")
+	}
+	if c.RetryInterval <= 0 {
+		return errors.New("retry interval must be greater than 0")
+	}
+	return nil
+}
+
+// Elector campaigns for leadership of a named election so exactly one instance runs a
+// singleton job at a time. Leadership is a lease renewed in the background; when renewals
+// fail the leader context is cancelled a safety margin (LeaseTTL/10) before the lease can
+// expire, so before another candidate can take over.
+type Elector struct {
+	locker *Locker
+	config ElectionConfig
+
+	onElected func(ctx context.Context)
+	onRevoked func()
+
+	// Control
+	cancel context.CancelFunc
+	doneCh chan struct{}
+
+	// State
+	running bool
+	leader  bool
+	token   int64
+	mu      sync.RWMutex
+}
+
+// NewElector creates a candidate for the election
+func NewElector(client redis.UniversalClient, config ElectionConfig) (*Elector, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid election config: %w", err)
+	}
+
+	locker, err := NewLocker(client, LockConfig{
+		KeyPrefix:     config.KeyPrefix,
+		TTL:           config.LeaseTTL,
+		RetryInterval: config.RetryInterval,
+		AutoRenew:     true,
+		RenewInterval: config.RenewInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Elector{
+		locker: locker,
+		config: config,
+	}, nil
+}
+
+// OnElected sets the function run when this instance becomes leader. Its context is
+// cancelled when leadership is lost or the elector is stopped, and the elector waits for
+// it to return before campaigning again. It must be called before Start.
+func (e *Elector) OnElected(fn func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onElected = fn
+}
+
+// OnRevoked sets the function called after leadership ends and OnElected has returned.
+// It must be called before Start.
+func (e *Elector) OnRevoked(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onRevoked = fn
+}
+
+// Start begins campaigning for leadership
+func (e *Elector) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return fmt.Errorf("elector already started")
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	e.doneCh = make(chan struct{})
+	e.running = true
+
+	go e.campaign(runCtx, e.doneCh)
+
+	return nil
+}
+
+// Stop stops campaigning and resigns leadership if held, so another candidate can take
+// over without waiting for the lease to expire
+func (e *Elector) Stop() error {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return nil
+	}
+	cancel, doneCh := e.cancel, e.doneCh
+	e.mu.Unlock()
+
+	// running stays set until the goroutine has exited so Start cannot begin another one
+	cancel()
+	<-doneCh
+
+	e.mu.Lock()
+	e.running = false
+	e.mu.Unlock()
+
+	return nil
+}
+
+// IsLeader reports whether this instance currently holds leadership
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Token returns the fencing token of the current term. Tokens increase with every new
+// leader and can be passed to downstream systems to reject writes from a stale leader.
+func (e *Elector) Token() int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.token
+}
+
+// campaign tries to acquire the lease until the context is done
+func (e *Elector) campaign(ctx context.Context, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	for {
+		lock, err := e.locker.TryLock(ctx, e.config.Name)
+		if err == nil {
+			e.lead(ctx, lock)
+		} else if !errors.Is(err, ErrLockNotObtained) && ctx.Err() == nil {
+			fmt.Printf("Failed to campaign for leadership of %s: %v\n", e.config.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.config.RetryInterval):
+		}
+	}
+}
+
+// lead runs the OnElected callback until the lease is lost or the context is done
+func (e *Elector) lead(ctx context.Context, lock *Lock) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	e.mu.Lock()
+	e.leader = true
+	e.token = lock.Token()
+	onElected, onRevoked := e.onElected, e.onRevoked
+	e.mu.Unlock()
+
+	handlerDone := make(chan struct{})
+	go func() {
+		defer close(handlerDone)
+		if onElected != nil {
+			e.run(leaderCtx, onElected)
+		}
+	}()
+
+	select {
+	case <-lock.Lost():
+	case <-ctx.Done():
+	}
+
+	cancel()
+	<-handlerDone
+
+	e.mu.Lock()
+	e.leader = false
+	e.mu.Unlock()
+
+	// Resign so followers do not wait for the lease to expire
+	releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), e.config.RenewInterval)
+	if err := lock.Unlock(releaseCtx); err != nil && !errors.Is(err, ErrLockNotHeld) {
+		fmt.Printf("Failed to resign leadership of %s: %v\n", e.config.Name, err)
+	}
+	releaseCancel()
+
+	if onRevoked != nil {
+		onRevoked()
+	}
+}
+
+// run calls the OnElected callback, converting panics into log messages
+func (e *Elector) run(ctx context.Context, fn func(ctx context.Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Leader callback of %s panicked: %v\n", e.config.Name, r)
+		}
+	}()
+	fn(ctx)
+}
@@ -0,0 +1,199 @@
+package _redis
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestElectorLeadership(t *testing.T) {
+	config := DefaultElectionConfig()
+	config.Name = "singleton"
+	config.LeaseTTL = time.Second
+	config.RenewInterval = 50 * time.Millisecond
+	config.RetryInterval = 20 * time.Millisecond
+
+	tests := []struct {
+		name         string
+		followers    int
+		stop         bool
+		takeLease    bool
+		failRenewals bool
+	}{
+		{
+			// Stopping the leader resigns and hands leadership over
+			name:      "Leader stops",
+			followers: 1,
+			stop:      true,
+		},
+		{
+			// Another owner takes the lease, the next renewal fails and leadership is revoked
+			name:      "Lease taken by another owner",
+			takeLease: true,
+		},
+		{
+			// Renewals fail, the lease was last renewed at most one renew interval ago and
+			// is gone from Redis one lease TTL after that
+			name:         "Renewals failing",
+			failRenewals: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+			defer client.Close()
+
+			var leaders atomic.Int32
+			electors := make([]*Elector, 1+tt.followers)
+			elected := make([]chan struct{}, len(electors))
+			revoked := make([]chan struct{}, len(electors))
+			for i := range electors {
+				elector, err := NewElector(client, config)
+				if err != nil {
+					t.Fatalf("NewElector() error = %v", err)
+				}
+				electedCh, revokedCh := make(chan struct{}, 10), make(chan struct{}, 10)
+				elector.OnElected(func(ctx context.Context) {
+					if leaders.Add(1) > 1 {
+						t.Errorf("more than one leader elected")
+					}
+					electedCh <- struct{}{}
+					<-ctx.Done()
+					leaders.Add(-1)
+				})
+				elector.OnRevoked(func() {
+					revokedCh <- struct{}{}
+				})
+				electors[i], elected[i], revoked[i] = elector, electedCh, revokedCh
+			}
+
+			ctx := context.Background()
+			leader := electors[0]
+			if err := leader.Start(ctx); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer leader.Stop()
+			waitFor(t, elected[0], "leader to be elected")
+
+			// Followers must not be elected while the leader renews its lease
+			for i, follower := range electors[1:] {
+				if err := follower.Start(ctx); err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+				defer follower.Stop()
+
+				select {
+				case <-elected[i+1]:
+					t.Fatalf("follower elected while the leader holds the lease")
+				case <-time.After(200 * time.Millisecond):
+				}
+				if follower.IsLeader() {
+					t.Errorf("follower IsLeader() = true, want false")
+				}
+			}
+			if !leader.IsLeader() {
+				t.Errorf("leader IsLeader() = false, want true")
+			}
+
+			token := leader.Token()
+			failedAt := time.Now()
+			switch {
+			case tt.stop:
+				if err := leader.Stop(); err != nil {
+					t.Fatalf("Stop() error = %v", err)
+				}
+			case tt.takeLease:
+				server.Set("leader:{singleton}", "someone-else")
+			case tt.failRenewals:
+				server.SetError("LOADING Redis is loading the dataset in memory")
+			}
+			waitFor(t, revoked[0], "leader to be revoked")
+
+			if tt.failRenewals {
+				deadline := config.LeaseTTL - config.RenewInterval
+				if elapsed := time.Since(failedAt); elapsed >= deadline {
+					t.Errorf("revoked %v after renewals started failing, want before %v when the lease may expire", elapsed, deadline)
+				}
+			}
+			if leader.IsLeader() {
+				t.Errorf("leader IsLeader() = true after being revoked")
+			}
+
+			if tt.followers > 0 {
+				waitFor(t, elected[1], "follower to be elected")
+				if electors[1].Token() <= token {
+					t.Errorf("Token() = %v, want greater than %v", electors[1].Token(), token)
+				}
+			}
+		})
+	}
+}
+
+func TestElectorStartWhileStopping(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	config := DefaultElectionConfig()
+	config.Name = "singleton"
+	elector, err := NewElector(client, config)
+	if err != nil {
+		t.Fatalf("NewElector() error = %v", err)
+	}
+
+	elected := make(chan struct{}, 1)
+	release := make(chan struct{})
+	elector.OnElected(func(ctx context.Context) {
+		elected <- struct{}{}
+		<-ctx.Done()
+		// Keep the campaign running after Stop was called
+		<-release
+	})
+
+	ctx := context.Background()
+	if err := elector.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitFor(t, elected, "elector to be elected")
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- elector.Stop() }()
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if err := elector.Start(ctx); err == nil {
+			t.Fatalf("Start() while the campaign is still running error = nil, want error")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(release)
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Stop() did not return")
+	}
+
+	if err := elector.Start(ctx); err != nil {
+		t.Fatalf("Start() after Stop error = %v", err)
+	}
+	elector.Stop()
+}
@@ -0,0 +1,189 @@
+package _redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	_logger "go-libs/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MetricsRecorder receives a measurement for every command or pipeline. Implementations
+// typically observe duration in a latency histogram and count non-nil errors.
+type MetricsRecorder interface {
+	RecordCommand(ctx context.Context, name string, duration time.Duration, err error)
+}
+
+// Tracer starts a span around every command or pipeline
+type Tracer interface {
+	StartSpan(ctx context.Context, name string, attributes map[string]string) (context.Context, Span)
+}
+
+// Span is a trace span started by a Tracer
+type Span interface {
+	// End finishes the span, recording err if it is not nil
+	End(err error)
+}
+
+// InstrumentationConfig holds the collaborators of the instrumentation hook. Any of
+// them may be nil to disable that part.
+type InstrumentationConfig struct {
+	// Metrics records command latency and errors
+	Metrics MetricsRecorder
+	// Tracer emits a span per command or pipeline
+	Tracer Tracer
+	// Logger logs commands slower than SlowThreshold. Arguments following a field name
+	// listed in the logger's HideFields are masked.
+	Logger *_logger.Logger
+	// SlowThreshold is the duration from which a command is logged (0 disables slow logging)
+	SlowThreshold time.Duration
+	// MaxArgLength truncates long arguments in logs and spans
+	MaxArgLength int
+}
+
+// DefaultInstrumentationConfig returns an instrumentation configuration with sensible defaults
+func DefaultInstrumentationConfig() InstrumentationConfig {
+	return InstrumentationConfig{
+		SlowThreshold: 100 * time.Millisecond,
+		MaxArgLength:  64,
+	}
+}
+
+// secretCommands are commands whose arguments are always masked
+var secretCommands = map[string]bool{
+	"auth":  true,
+	"hello": true,
+}
+
+// instrumentationHook is a go-redis hook recording metrics, traces and slow commands
+type instrumentationHook struct {
+	config InstrumentationConfig
+}
+
+// NewInstrumentationHook creates a go-redis hook from the configuration. Attach it with
+// AddHook on any connection type.
+func NewInstrumentationHook(config InstrumentationConfig) redis.Hook {
+	return &instrumentationHook{config: config}
+}
+
+// DialHook passes dials through unchanged
+func (h *instrumentationHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook instruments a single command
+func (h *instrumentationHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return h.instrument(ctx, cmd.FullName(), []redis.Cmder{cmd}, func(ctx context.Context) error {
+			return next(ctx, cmd)
+		})
+	}
+}
+
+// ProcessPipelineHook instruments a pipeline as one operation
+func (h *instrumentationHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return h.instrument(ctx, "pipeline", cmds, func(ctx context.Context) error {
+			return next(ctx, cmds)
+		})
+	}
+}
+
+// instrument runs fn inside a span and records its duration and outcome
+func (h *instrumentationHook) instrument(ctx context.Context, name string, cmds []redis.Cmder, fn func(ctx context.Context) error) error {
+	var span Span
+	if h.config.Tracer != nil {
+		ctx, span = h.config.Tracer.StartSpan(ctx, "redis."+name, map[string]string{
+			"db.system":    "redis",
+			"db.operation": name,
+			"db.statement": h.statement(cmds),
+		})
+	}
+
+	start := time.Now()
+	err := fn(ctx)
+	duration := time.Since(start)
+
+	// A missing key is a normal result, not a failure
+	result := err
+	if errors.Is(result, redis.Nil) {
+		result = nil
+	}
+
+	if span != nil {
+		span.End(result)
+	}
+	if h.config.Metrics != nil {
+		h.config.Metrics.RecordCommand(ctx, name, duration, result)
+	}
+	if h.config.Logger != nil && h.config.SlowThreshold > 0 && duration >= h.config.SlowThreshold {
+		h.config.Logger.Warn(ctx, "Slow Redis command",
+			"command", h.statement(cmds),
+			"duration", duration.String(),
+			"error", errorString(result),
+		)
+	}
+
+	return err
+}
+
+// statement formats the commands with sensitive arguments masked
+func (h *instrumentationHook) statement(cmds []redis.Cmder) string {
+	var hidden func(string) bool
+	if h.config.Logger != nil {
+		hidden = h.config.Logger.IsHidden
+	}
+
+	parts := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		parts[i] = redactArgs(cmd.Args(), hidden, h.config.MaxArgLength)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// redactArgs formats command arguments. The argument following one that names a hidden
+// field is masked, for example the value in "HSET user:1 password secret", and every
+// argument of AUTH and HELLO is masked.
+func redactArgs(args []any, hidden func(string) bool, maxLen int) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	name := fmt.Sprint(args[0])
+	parts := make([]string, len(args))
+	parts[0] = name
+
+	maskAll := secretCommands[strings.ToLower(name)]
+	maskNext := false
+	for i := 1; i < len(args); i++ {
+		arg := fmt.Sprint(args[i])
+
+		switch {
+		case maskAll || maskNext:
+			parts[i] = "***"
+			maskNext = false
+		default:
+			maskNext = hidden != nil && hidden(arg)
+			if maxLen > 0 && len(arg) > maxLen {
+				arg = arg[:maxLen] + "..."
+			}
+			parts[i] = arg
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,113 @@
+package _redis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	_logger "go-libs/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type recordedCommand struct {
+	name string
+	err  error
+}
+
+type fakeMetrics struct {
+	mu       sync.Mutex
+	commands []recordedCommand
+}
+
+func (m *fakeMetrics) RecordCommand(ctx context.Context, name string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commands = append(m.commands, recordedCommand{name: name, err: err})
+}
+
+func TestRedactArgs(t *testing.T) {
+	log := _logger.New(_logger.Config{HideFields: []string{"password", "token"}})
+
+	tests := []struct {
+		name string
+		args []any
+		want string
+	}{
+		{name: "plain", args: []any{"get", "user:1"}, want: "get user:1"},
+		{name: "hidden field", args: []any{"hset", "user:1", "name", "alice", "password", "secret"}, want: "hset user:1 name alice password ***"},
+		{name: "auth", args: []any{"auth", "app", "secret"}, want: "auth *** ***"},
+		{name: "truncated", args: []any{"set", "k", "0123456789abcdef"}, want: "set k 01234567..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactArgs(tt.args, log.IsHidden, 8); got != tt.want {
+				t.Errorf("redactArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstrumentationHookRecordsCommands(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	config := DefaultConfig()
+	config.Host = server.Host()
+	config.Port, _ = strconv.Atoi(server.Port())
+
+	conn := NewConnection(config)
+	metrics := &fakeMetrics{}
+	instrumentation := DefaultInstrumentationConfig()
+	instrumentation.Metrics = metrics
+	conn.AddHook(NewInstrumentationHook(instrumentation))
+
+	ctx := context.Background()
+	if err := conn.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer conn.Close()
+
+	// Only count the commands issued below, not the connection handshake
+	metrics.mu.Lock()
+	metrics.commands = nil
+	metrics.mu.Unlock()
+
+	client := conn.GetClient()
+	if err := client.Get(ctx, "missing").Err(); err != redis.Nil {
+		t.Fatalf("Get() error = %v, want redis.Nil", err)
+	}
+	if err := client.Incr(ctx, "missing-list").Err(); err != nil {
+		t.Fatalf("Incr() error = %v", err)
+	}
+	client.LPush(ctx, "list", "a")
+	if err := client.Incr(ctx, "list").Err(); err == nil {
+		t.Fatalf("Incr() on a list error = nil, want WRONGTYPE")
+	}
+	client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "a")
+		pipe.Get(ctx, "b")
+		return nil
+	})
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	calls := map[string]int{}
+	var failed int
+	for _, cmd := range metrics.commands {
+		calls[cmd.name]++
+		if cmd.err != nil {
+			failed++
+		}
+	}
+	if calls["get"] != 1 || calls["incr"] != 2 || calls["pipeline"] != 1 {
+		t.Errorf("RecordCommand() calls = %v, want get, incr x2 and pipeline", calls)
+	}
+	if failed != 1 {
+		t.Errorf("RecordCommand() errors = %v, want %v", failed, 1)
+	}
+}
@@ -56,3 +56,22 @@ type SentinelClient interface {
 	// HasSlaveConnected returns true if a slave connection is available
 	HasSlaveConnected() bool
 }
+
+// UniversalClient defines a mode independent interface implemented by every connection
+// type, so helpers can be written once against redis.UniversalClient
+type UniversalClient interface {
+	RedisClient
+
+	// Mode returns the deployment mode of the connection
+	Mode() Mode
+
+	// GetUniversalClient returns the client for write operations
+	GetUniversalClient() redis.UniversalClient
+
+	// GetReadClient returns the client for read operations. It is a replica client when
+	// one is connected and the write client otherwise.
+	GetReadClient() redis.UniversalClient
+
+	// AddHook attaches a go-redis hook to every underlying client
+	AddHook(hook redis.Hook)
+}
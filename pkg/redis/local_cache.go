@@ -0,0 +1,132 @@
+package _redis
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// localCache is an in-process LRU cache with per-entry expiry
+type localCache[T any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	items   map[string]*list.Element
+	evictor *list.List
+
+	// generation changes on every delete and purge, so a value read from the remote
+	// tier before an invalidation is not stored after it
+	generation uint64
+}
+
+type localEntry[T any] struct {
+	key     string
+	value   T
+	expires time.Time
+}
+
+// newLocalCache creates a local cache holding at most size entries for up to ttl
+func newLocalCache[T any](size int, ttl time.Duration) *localCache[T] {
+	return &localCache[T]{
+		size:    size,
+		ttl:     ttl,
+		items:   make(map[string]*list.Element, size),
+		evictor: list.New(),
+	}
+}
+
+// get returns the value for key if present and not expired
+func (c *localCache[T]) get(key string) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero T
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := elem.Value.(*localEntry[T])
+	if time.Now().After(entry.expires) {
+		c.removeElement(elem)
+		return zero, false
+	}
+
+	c.evictor.MoveToFront(elem)
+	return entry.value, true
+}
+
+// set stores a value, evicting the least recently used entry when full
+func (c *localCache[T]) set(key string, value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setLocked(key, value)
+}
+
+// currentGeneration returns the generation to pass to setIfCurrent
+func (c *localCache[T]) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// setIfCurrent stores a value only if nothing was deleted since generation was read
+func (c *localCache[T]) setIfCurrent(key string, value T, generation uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != generation {
+		return false
+	}
+	c.setLocked(key, value)
+	return true
+}
+
+func (c *localCache[T]) setLocked(key string, value T) {
+	expires := time.Now().Add(c.ttl)
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*localEntry[T])
+		entry.value = value
+		entry.expires = expires
+		c.evictor.MoveToFront(elem)
+		return
+	}
+
+	elem := c.evictor.PushFront(&localEntry[T]{key: key, value: value, expires: expires})
+	c.items[key] = elem
+
+	if c.evictor.Len() > c.size {
+		c.removeElement(c.evictor.Back())
+	}
+}
+
+// delete removes the given keys
+func (c *localCache[T]) delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, key := range keys {
+		if elem, ok := c.items[key]; ok {
+			c.removeElement(elem)
+		}
+	}
+}
+
+// purge removes all entries
+func (c *localCache[T]) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.items = make(map[string]*list.Element, c.size)
+	c.evictor.Init()
+}
+
+func (c *localCache[T]) removeElement(elem *list.Element) {
+	c.evictor.Remove(elem)
+	delete(c.items, elem.Value.(*localEntry[T]).key)
+}
@@ -0,0 +1,60 @@
+package _redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocalCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLocalCache[int](2, time.Minute)
+
+	c.set("a", 1)
+	c.set("b", 2)
+	c.get("a")
+	c.set("c", 3)
+
+	if _, ok := c.get("b"); ok {
+		t.Errorf("get(b) found, want evicted")
+	}
+	if v, ok := c.get("a"); !ok || v != 1 {
+		t.Errorf("get(a) = %v, %v, want 1, true", v, ok)
+	}
+	if v, ok := c.get("c"); !ok || v != 3 {
+		t.Errorf("get(c) = %v, %v, want 3, true", v, ok)
+	}
+}
+
+func TestLocalCacheExpires(t *testing.T) {
+	c := newLocalCache[int](2, 10*time.Millisecond)
+
+	c.set("a", 1)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.get("a"); ok {
+		t.Errorf("get(a) found, want expired")
+	}
+	if len(c.items) != 0 {
+		t.Errorf("len(items) = %d, want 0", len(c.items))
+	}
+}
+
+func TestLocalCacheDeleteAndPurge(t *testing.T) {
+	c := newLocalCache[int](10, time.Minute)
+
+	c.set("a", 1)
+	c.set("b", 2)
+	c.set("c", 3)
+
+	c.delete("a", "missing")
+	if _, ok := c.get("a"); ok {
+		t.Errorf("get(a) found after delete")
+	}
+
+	c.purge()
+	if _, ok := c.get("b"); ok {
+		t.Errorf("get(b) found after purge")
+	}
+	if c.evictor.Len() != 0 {
+		t.Errorf("evictor.Len() = %d, want 0", c.evictor.Len())
+	}
+}
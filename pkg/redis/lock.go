@@ -0,0 +1,430 @@
+package _redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrLockNotObtained is returned when the lock is held by another owner
+	ErrLockNotObtained = errors.New("redis: lock not obtained")
+	// ErrLockNotHeld is returned when releasing or extending a lock that is no longer owned
+	ErrLockNotHeld = errors.New("redis: lock not held")
+)
+
+// acquireScript sets the lock key if it does not exist and returns a new fencing token.
+// KEYS[1] = lock key, KEYS[2] = fencing counter key, ARGV[1] = owner, ARGV[2] = ttl in ms
+var acquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lock key only if it is still owned by the caller
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendScript resets the lock TTL only if it is still owned by the caller
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// LockConfig holds the configuration for distributed locks
+type LockConfig struct {
+	// KeyPrefix is prepended to every lock key
+	KeyPrefix string
+	// TTL is how long the lock is held before it expires if not renewed
+	TTL time.Duration
+	// RetryInterval is the delay between attempts when waiting in Lock
+	RetryInterval time.Duration
+	// AutoRenew keeps extending the TTL in the background while the lock is held
+	AutoRenew bool
+	// RenewInterval is how often the TTL is extended, defaults to TTL/3
+	RenewInterval time.Duration
+	// DriftFactor is the clock drift allowance used by Redlock to compute lock validity
+	DriftFactor float64
+	// SafetyMargin is how long before the end of its validity an auto-renewed lock is
+	// reported lost, leaving time to stop work before the key expires. Defaults to TTL/10.
+	SafetyMargin time.Duration
+}
+
+// DefaultLockConfig returns a lock configuration with sensible defaults
+func DefaultLockConfig() LockConfig {
+	return LockConfig{
+		KeyPrefix:     "lock:",
+		TTL:           30 * time.Second,
+		RetryInterval: 100 * time.Millisecond,
+		AutoRenew:     true,
+		RenewInterval: 10 * time.Second,
+		DriftFactor:   0.01,
+	}
+}
+
+// Validate checks if the lock configuration is valid
+func (c *LockConfig) Validate() error {
+	if c.TTL <= 0 {
+		return errors.New("ttl must be greater than 0")
+	}
+	if c.RetryInterval <= 0 {
+		return errors.New("retry interval must be greater than 0")
+	}
+	if c.RenewInterval < 0 || (c.AutoRenew && c.RenewInterval >= c.TTL) {
+		return errors.New("renew interval must be between 0 and ttl")
+	}
+	if c.DriftFactor < 0 || c.DriftFactor >= 1 {
+		return errors.New("drift factor must be between 0 and 1")
+	}
+	if c.SafetyMargin < 0 || c.SafetyMargin >= c.TTL {
+		return errors.New("safety margin must be between 0 and ttl")
+	}
+	return nil
+}
+
+// Locker acquires distributed locks on one Redis deployment, or on several
+// independent nodes using the Redlock algorithm
+type Locker struct {
+	clients []redis.UniversalClient
+	config  LockConfig
+}
+
+// NewLocker creates a locker backed by a single client. Any of the clients returned
+// by Connection, ClusterConnection or SentinelConnection can be used.
+func NewLocker(client redis.UniversalClient, config LockConfig) (*Locker, error) {
+	if client == nil {
+		return nil, errors.New("redis client is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid lock config: %w", err)
+	}
+
+	return &Locker{
+		clients: []redis.UniversalClient{client},
+		config:  config,
+	}, nil
+}
+
+// NewRedlock creates a locker that uses the Redlock algorithm across independent Redis nodes.
+// A lock is only obtained when a majority of the nodes grant it.
+func NewRedlock(nodes []SingleNodeClient, config LockConfig) (*Locker, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("at least one node is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid lock config: %w", err)
+	}
+
+	clients := make([]redis.UniversalClient, 0, len(nodes))
+	for i, node := range nodes {
+		if node == nil || node.GetClient() == nil {
+			return nil, fmt.Errorf("node %d is not connected", i)
+		}
+		clients = append(clients, node.GetClient())
+	}
+
+	return &Locker{
+		clients: clients,
+		config:  config,
+	}, nil
+}
+
+// TryLock attempts to acquire the lock once and returns ErrLockNotObtained if it is held elsewhere
+func (l *Locker) TryLock(ctx context.Context, name string) (*Lock, error) {
+	lockKey, fenceKey := l.keys(name)
+	owner := uuid.New().String()
+
+	token, validity, err := l.acquire(ctx, lockKey, fenceKey, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{
+		locker:   l,
+		key:      lockKey,
+		owner:    owner,
+		token:    token,
+		validity: validity,
+		lostCh:   make(chan struct{}),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+
+	if l.config.AutoRenew {
+		go lock.renew()
+	} else {
+		close(lock.doneCh)
+	}
+
+	return lock, nil
+}
+
+// Lock blocks until the lock is acquired or the context is done
+func (l *Locker) Lock(ctx context.Context, name string) (*Lock, error) {
+	for {
+		lock, err := l.TryLock(ctx, name)
+		if err == nil {
+			return lock, nil
+		}
+		if !errors.Is(err, ErrLockNotObtained) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(l.config.RetryInterval):
+		}
+	}
+}
+
+// keys returns the lock key and the fencing counter key. Both share a hash tag so they
+// map to the same slot on a Redis cluster.
+func (l *Locker) keys(name string) (string, string) {
+	lockKey := l.config.KeyPrefix + "{" + name + "}"
+	return lockKey, lockKey + ":fence"
+}
+
+// quorum returns the number of nodes that must agree for a lock operation to succeed
+func (l *Locker) quorum() int {
+	return len(l.clients)/2 + 1
+}
+
+// acquire runs the acquire script on every node and returns the fencing token
+// and the remaining validity of the lock
+func (l *Locker) acquire(ctx context.Context, lockKey, fenceKey, owner string) (int64, time.Duration, error) {
+	start := time.Now()
+	ttl := l.config.TTL.Milliseconds()
+
+	var token int64
+	var lastErr error
+	acquired := 0
+
+	for _, client := range l.clients {
+		n, err := acquireScript.Run(ctx, client, []string{lockKey, fenceKey}, owner, ttl).Int64()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if n > 0 {
+			acquired++
+			// Tokens from independent nodes are not coordinated, the highest one wins
+			// but is only monotonic with a single node
+			if n > token {
+				token = n
+			}
+		}
+	}
+
+	validity := l.validity(start)
+
+	if acquired >= l.quorum() && validity > 0 {
+		return token, validity, nil
+	}
+
+	// Release whatever was acquired on a minority of nodes
+	l.release(context.WithoutCancel(ctx), lockKey, owner)
+
+	if acquired == 0 && lastErr != nil {
+		return 0, 0, fmt.Errorf("failed to acquire lock: %w", lastErr)
+	}
+	return 0, 0, ErrLockNotObtained
+}
+
+// validity returns how long a lock set or extended by an operation that began at start is
+// guaranteed to be held, allowing for clock drift between the client and the nodes
+func (l *Locker) validity(start time.Time) time.Duration {
+	drift := time.Duration(float64(l.config.TTL)*l.config.DriftFactor) + 2*time.Millisecond
+	return l.config.TTL - time.Since(start) - drift
+}
+
+// safetyMargin returns how long before the end of its validity a lock is reported lost
+func (l *Locker) safetyMargin() time.Duration {
+	if l.config.SafetyMargin > 0 {
+		return l.config.SafetyMargin
+	}
+	return l.config.TTL / 10
+}
+
+// release runs the release script on every node and returns how many nodes released the lock
+func (l *Locker) release(ctx context.Context, lockKey, owner string) (int, error) {
+	var lastErr error
+	released := 0
+
+	for _, client := range l.clients {
+		n, err := releaseScript.Run(ctx, client, []string{lockKey}, owner).Int64()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if n > 0 {
+			released++
+		}
+	}
+
+	return released, lastErr
+}
+
+// extend runs the extend script on every node and returns how many nodes extended the lock
+func (l *Locker) extend(ctx context.Context, lockKey, owner string, ttl time.Duration) (int, error) {
+	var lastErr error
+	extended := 0
+
+	for _, client := range l.clients {
+		n, err := extendScript.Run(ctx, client, []string{lockKey}, owner, ttl.Milliseconds()).Int64()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if n > 0 {
+			extended++
+		}
+	}
+
+	return extended, lastErr
+}
+
+// Lock represents a held distributed lock
+type Lock struct {
+	locker   *Locker
+	key      string
+	owner    string
+	token    int64
+	validity time.Duration
+
+	// lostCh is closed when auto-renewal fails and the lock can no longer be trusted
+	lostCh chan struct{}
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	mu       sync.Mutex
+	released bool
+	lostOnce sync.Once
+}
+
+// Key returns the Redis key of the lock
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token returns the fencing token of the lock. With a single client, tokens increase
+// monotonically for each acquisition of the same name and should be passed to the
+// protected resource so it can reject writes from stale owners. With Redlock the token
+// is the highest of independent per-node counters, a later acquisition on a different
+// majority of nodes can get a smaller token, so it must not be used for fencing.
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// Validity returns how long the lock was guaranteed to be held at acquisition time
+func (l *Lock) Validity() time.Duration {
+	return l.validity
+}
+
+// Lost returns a channel that is closed when the lock is lost during auto-renewal. It is
+// closed SafetyMargin before the lock validity ends when renewals keep failing, so before
+// the key can expire and be taken by another owner.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lostCh
+}
+
+// Extend resets the lock TTL, returning ErrLockNotHeld if the lock has expired
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	extended, err := l.locker.extend(ctx, l.key, l.owner, ttl)
+	if extended >= l.locker.quorum() {
+		return nil
+	}
+	if extended == 0 && err != nil {
+		return fmt.Errorf("failed to extend lock: %w", err)
+	}
+	return ErrLockNotHeld
+}
+
+// Unlock stops auto-renewal and releases the lock if it is still owned by the caller
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	if l.released {
+		l.mu.Unlock()
+		return ErrLockNotHeld
+	}
+	l.released = true
+	l.mu.Unlock()
+
+	select {
+	case <-l.doneCh:
+	default:
+		close(l.stopCh)
+		<-l.doneCh
+	}
+
+	released, err := l.locker.release(ctx, l.key, l.owner)
+	if released >= l.locker.quorum() {
+		return nil
+	}
+	if released == 0 && err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return ErrLockNotHeld
+}
+
+// renew periodically extends the lock TTL until it is unlocked or lost
+func (l *Lock) renew() {
+	defer close(l.doneCh)
+
+	interval := l.locker.config.RenewInterval
+	if interval <= 0 {
+		interval = l.locker.config.TTL / 3
+	}
+	margin := l.locker.safetyMargin()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// The lock is only trusted until its validity, counted from the start of the last
+	// successful acquire or extend, minus the safety margin
+	trustedUntil := time.Now().Add(l.validity - margin)
+	expiry := time.NewTimer(l.validity - margin)
+	defer expiry.Stop()
+
+	for {
+		select {
+		case <-l.stopCh:
+			return
+		case <-expiry.C:
+			l.lostOnce.Do(func() { close(l.lostCh) })
+			return
+		case <-ticker.C:
+			// The expiry timer cannot fire while Extend runs, so Extend must give up by then
+			start := time.Now()
+			ctx, cancel := context.WithTimeout(context.Background(), min(interval, trustedUntil.Sub(start)))
+			err := l.Extend(ctx, l.locker.config.TTL)
+			cancel()
+
+			if err == nil {
+				validity := l.locker.validity(start) - margin
+				trustedUntil = time.Now().Add(validity)
+				expiry.Reset(validity)
+				continue
+			}
+
+			// Transient errors are retried on the next tick until the lock expires,
+			// ownership loss is final
+			if errors.Is(err, ErrLockNotHeld) || !time.Now().Before(trustedUntil) {
+				l.lostOnce.Do(func() { close(l.lostCh) })
+				return
+			}
+		}
+	}
+}
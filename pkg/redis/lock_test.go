@@ -0,0 +1,313 @@
+package _redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLockerTryLock(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	locker, err := NewLocker(client, DefaultLockConfig())
+	if err != nil {
+		t.Fatalf("NewLocker() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		held     bool
+		released bool
+		wantErr  error
+	}{
+		{
+			name: "Free key",
+		},
+		{
+			name:    "Held key",
+			held:    true,
+			wantErr: ErrLockNotObtained,
+		},
+		{
+			name:     "Released key",
+			held:     true,
+			released: true,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var first *Lock
+			if tt.held {
+				if first, err = locker.TryLock(ctx, tt.name); err != nil {
+					t.Fatalf("TryLock() error = %v", err)
+				}
+				if tt.released {
+					first.Unlock(ctx)
+				}
+			}
+
+			lock, err := locker.TryLock(ctx, tt.name)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TryLock() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer lock.Unlock(ctx)
+
+			// Fencing tokens keep growing across owners of the same key
+			if first != nil && lock.Token() <= first.Token() {
+				t.Errorf("Token() = %v, want greater than %v", lock.Token(), first.Token())
+			}
+		})
+	}
+}
+
+func TestLockUnlock(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	config := DefaultLockConfig()
+	config.TTL = time.Second
+	config.RenewInterval = 500 * time.Millisecond
+	locker, err := NewLocker(client, config)
+	if err != nil {
+		t.Fatalf("NewLocker() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		taken    bool
+		unlocked bool
+		wantErr  error
+	}{
+		{
+			name: "Owner",
+		},
+		{
+			name:     "Already unlocked",
+			unlocked: true,
+			wantErr:  ErrLockNotHeld,
+		},
+		{
+			name:    "Expired and taken by another owner",
+			taken:   true,
+			wantErr: ErrLockNotHeld,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock, err := locker.TryLock(ctx, tt.name)
+			if err != nil {
+				t.Fatalf("TryLock() error = %v", err)
+			}
+
+			var owner *Lock
+			if tt.taken {
+				server.FastForward(2 * time.Second)
+				if owner, err = locker.TryLock(ctx, tt.name); err != nil {
+					t.Fatalf("TryLock() error = %v", err)
+				}
+				defer owner.Unlock(ctx)
+			}
+			if tt.unlocked {
+				lock.Unlock(ctx)
+			}
+
+			if err := lock.Unlock(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Unlock() error = %v, want %v", err, tt.wantErr)
+			}
+			if owner != nil {
+				if got, _ := server.Get(owner.Key()); got != owner.owner {
+					t.Errorf("lock owner = %v, want %v", got, owner.owner)
+				}
+			}
+		})
+	}
+}
+
+func TestLockExtend(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	config := DefaultLockConfig()
+	config.TTL = time.Second
+	config.RenewInterval = 500 * time.Millisecond
+	locker, err := NewLocker(client, config)
+	if err != nil {
+		t.Fatalf("NewLocker() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		expired bool
+		wantErr error
+		wantTTL time.Duration
+	}{
+		{
+			name:    "Held lock",
+			wantTTL: 10 * time.Second,
+		},
+		{
+			name:    "Expired lock",
+			expired: true,
+			wantErr: ErrLockNotHeld,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock, err := locker.TryLock(ctx, tt.name)
+			if err != nil {
+				t.Fatalf("TryLock() error = %v", err)
+			}
+			if tt.expired {
+				server.FastForward(2 * time.Second)
+			}
+
+			if err := lock.Extend(ctx, 10*time.Second); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Extend() error = %v, want %v", err, tt.wantErr)
+			}
+			if ttl := server.TTL(lock.Key()); ttl != tt.wantTTL {
+				t.Errorf("TTL = %v, want %v", ttl, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestLockAutoRenew(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	config := DefaultLockConfig()
+	config.TTL = time.Second
+	config.AutoRenew = true
+	config.RenewInterval = 50 * time.Millisecond
+	locker, err := NewLocker(client, config)
+	if err != nil {
+		t.Fatalf("NewLocker() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		taken    bool
+		wantLost bool
+	}{
+		{
+			// Renewal resets the TTL before the lock expires
+			name: "Renewed",
+		},
+		{
+			// Another owner takes the key, the next renewal fails and the lock is lost
+			name:     "Taken by another owner",
+			taken:    true,
+			wantLost: true,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock, err := locker.TryLock(ctx, tt.name)
+			if err != nil {
+				t.Fatalf("TryLock() error = %v", err)
+			}
+			defer lock.Unlock(ctx)
+
+			if tt.taken {
+				server.Set(lock.Key(), "someone-else")
+			} else {
+				server.FastForward(800 * time.Millisecond)
+			}
+
+			// Wait past several renewals
+			var lost bool
+			select {
+			case <-lock.Lost():
+				lost = true
+			case <-time.After(300 * time.Millisecond):
+			}
+
+			if lost != tt.wantLost {
+				t.Errorf("Lost() closed = %v, want %v", lost, tt.wantLost)
+			}
+			if ttl := server.TTL(lock.Key()); !tt.taken && ttl <= 500*time.Millisecond {
+				t.Errorf("TTL = %v, want renewed", ttl)
+			}
+		})
+	}
+}
+
+// hangHook blocks every command until its context ends once hang is closed
+type hangHook struct {
+	hang chan struct{}
+}
+
+func (h hangHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h hangHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		select {
+		case <-h.hang:
+			<-ctx.Done()
+			return ctx.Err()
+		default:
+			return next(ctx, cmd)
+		}
+	}
+}
+
+func (h hangHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestLockLostWhileExtendHangs(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	hook := hangHook{hang: make(chan struct{})}
+	client.AddHook(hook)
+
+	// The first renewal starts after most of the lease and would hang past the TTL
+	config := DefaultLockConfig()
+	config.TTL = time.Second
+	config.RenewInterval = 600 * time.Millisecond
+
+	locker, err := NewLocker(client, config)
+	if err != nil {
+		t.Fatalf("NewLocker() error = %v", err)
+	}
+
+	start := time.Now()
+	lock, err := locker.TryLock(context.Background(), "order")
+	if err != nil {
+		t.Fatalf("TryLock() error = %v", err)
+	}
+	close(hook.hang)
+
+	select {
+	case <-lock.Lost():
+		if elapsed := time.Since(start); elapsed > 950*time.Millisecond {
+			t.Errorf("Lost() closed after %v, want before the lease expires", elapsed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for Lost()")
+	}
+}
@@ -0,0 +1,93 @@
+package _queue_redis
+
+import (
+	"errors"
+	"time"
+)
+
+// Config holds the configuration for a delayed job queue
+type Config struct {
+	// Name identifies the queue, all of its keys share the hash tag {Name}
+	Name string
+	// KeyPrefix is prepended to every queue key
+	KeyPrefix string
+	// VisibilityTimeout is how long a claimed job is hidden before it is handed out again
+	VisibilityTimeout time.Duration
+	// DefaultMaxRetries is used for jobs that do not set MaxRetries
+	DefaultMaxRetries int
+	// RetryBaseDelay is the first retry delay, it doubles on every attempt
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the retry delay
+	RetryMaxDelay time.Duration
+	// UniqueTTL is how long a unique key blocks duplicates when the job does not set UniqueFor
+	UniqueTTL time.Duration
+}
+
+// DefaultConfig returns a queue configuration with sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Name:              "default",
+		KeyPrefix:         "queue:",
+		VisibilityTimeout: 5 * time.Minute,
+		DefaultMaxRetries: 3,
+		RetryBaseDelay:    10 * time.Second,
+		RetryMaxDelay:     time.Hour,
+		UniqueTTL:         24 * time.Hour,
+	}
+}
+
+// Validate checks if the queue configuration is valid
+func (c *Config) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	if c.VisibilityTimeout <= 0 {
+		return errors.New("visibility timeout must be greater than 0")
+	}
+	if c.DefaultMaxRetries < 0 {
+		return errors.New("default max retries must be greater than or equal to 0")
+	}
+	if c.RetryBaseDelay <= 0 {
+		return errors.New("retry base delay must be greater than 0")
+	}
+	if c.RetryMaxDelay < c.RetryBaseDelay {
+		return errors.New("retry max delay must be greater than or equal to retry base delay")
+	}
+	if c.UniqueTTL <= 0 {
+		return errors.New("unique ttl must be greater than 0")
+	}
+	return nil
+}
+
+// WorkerConfig holds the configuration for a worker pool
+type WorkerConfig struct {
+	// Concurrency is the number of jobs processed at the same time
+	Concurrency int
+	// PollInterval is how often the queue is polled when it is idle
+	PollInterval time.Duration
+	// ShutdownTimeout bounds how long Stop waits for running jobs
+	ShutdownTimeout time.Duration
+}
+
+// DefaultWorkerConfig returns a worker configuration with sensible defaults
+func DefaultWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		Concurrency:     10,
+		PollInterval:    time.Second,
+		ShutdownTimeout: 30 * time.Second,
+	}
+}
+
+// Validate checks if the worker configuration is valid
+func (c *WorkerConfig) Validate() error {
+	if c.Concurrency <= 0 {
+		return errors.New("concurrency must be greater than 0")
+	}
+	if c.PollInterval <= 0 {
+		return errors.New("poll interval must be greater than 0")
+	}
+	if c.ShutdownTimeout < 0 {
+		return errors.New("shutdown timeout must be greater than or equal to 0")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package _queue_redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NoRetry disables retries for a job when used as MaxRetries
+const NoRetry = -1
+
+// Job is a unit of work stored in the queue
+type Job struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Payload []byte `json:"payload"`
+	// RunAt is when the job becomes due, zero means now
+	RunAt time.Time `json:"run_at"`
+	// MaxRetries is how many times a failed job is retried, 0 uses the queue default
+	// and NoRetry disables retries
+	MaxRetries int `json:"max_retries"`
+	// UniqueKey prevents enqueueing another job with the same key while this one exists
+	UniqueKey string `json:"unique_key,omitempty"`
+	// UniqueFor bounds how long UniqueKey blocks duplicates, 0 uses the queue default
+	UniqueFor  time.Duration `json:"unique_for,omitempty"`
+	EnqueuedAt time.Time     `json:"enqueued_at"`
+
+	// Attempts is how many times the job has been claimed, including the current one
+	Attempts int `json:"-"`
+}
+
+// NewJob creates a job with a JSON encoded payload
+func NewJob(jobType string, payload any) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return &Job{
+		ID:      uuid.New().String(),
+		Type:    jobType,
+		Payload: data,
+	}, nil
+}
+
+// Decode unmarshals the JSON payload into v
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
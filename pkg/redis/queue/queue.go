@@ -0,0 +1,264 @@
+package _queue_redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	_queue "go-libs/pkg/queue"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrDuplicateJob is returned by Enqueue when a job with the same unique key exists
+	ErrDuplicateJob = errors.New("queue: duplicate job")
+	// ErrJobNotClaimed is returned when retrying or killing a job that is no longer claimed
+	ErrJobNotClaimed = _queue.ErrJobNotClaimed
+)
+
+// Stats contains the number of jobs in each state
+type Stats struct {
+	Scheduled int64
+	InFlight  int64
+	Dead      int64
+}
+
+// Queue is a delayed job queue stored in Redis sorted sets keyed by due time
+type Queue struct {
+	client redis.UniversalClient
+	config Config
+}
+
+// NewQueue creates a new delayed job queue
+func NewQueue(client redis.UniversalClient, config Config) (*Queue, error) {
+	if client == nil {
+		return nil, errors.New("redis client is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid queue config: %w", err)
+	}
+
+	return &Queue{
+		client: client,
+		config: config,
+	}, nil
+}
+
+// Enqueue stores the job and schedules it at RunAt. If the job has a unique key that is
+// already taken, the ID of the existing job is returned with ErrDuplicateJob.
+func (q *Queue) Enqueue(ctx context.Context, job *Job) (string, error) {
+	if job.Type == "" {
+		return "", errors.New("job type is required")
+	}
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	job.EnqueuedAt = now
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	var uniqueTTL int64
+	if job.UniqueKey != "" {
+		uniqueTTL = q.config.UniqueTTL.Milliseconds()
+		if job.UniqueFor > 0 {
+			uniqueTTL = job.UniqueFor.Milliseconds()
+		}
+	}
+
+	id, err := enqueueScript.Run(ctx, q.client,
+		[]string{q.key("scheduled"), q.key("jobs"), q.uniqueKey(job.UniqueKey)},
+		job.ID, data, job.RunAt.UnixMilli(), uniqueTTL,
+	).Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	if id != job.ID {
+		return id, ErrDuplicateJob
+	}
+
+	return id, nil
+}
+
+// EnqueueIn schedules the job to run after delay
+func (q *Queue) EnqueueIn(ctx context.Context, job *Job, delay time.Duration) (string, error) {
+	job.RunAt = time.Now().Add(delay)
+	return q.Enqueue(ctx, job)
+}
+
+// Claim atomically takes up to limit due jobs. Claimed jobs are hidden for the visibility
+// timeout and handed out again if they are not completed, retried or extended in time.
+func (q *Queue) Claim(ctx context.Context, limit int) ([]*Job, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	values, err := claimScript.Run(ctx, q.client,
+		[]string{q.key("scheduled"), q.key("inflight"), q.key("jobs"), q.key("attempts")},
+		now.UnixMilli(), now.Add(q.config.VisibilityTimeout).UnixMilli(), limit,
+	).Slice()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+
+	jobs := make([]*Job, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		data, _ := values[i].(string)
+		attempts, _ := values[i+1].(int64)
+
+		job := &Job{}
+		if err := json.Unmarshal([]byte(data), job); err != nil {
+			return jobs, fmt.Errorf("failed to unmarshal job: %w", err)
+		}
+		job.Attempts = int(attempts)
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// Complete removes a finished job from the queue
+func (q *Queue) Complete(ctx context.Context, job *Job) error {
+	err := completeScript.Run(ctx, q.client,
+		[]string{q.key("inflight"), q.key("scheduled"), q.key("jobs"), q.key("attempts"), q.key("errors"), q.uniqueKey(job.UniqueKey)},
+		job.ID,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	return nil
+}
+
+// Retry reschedules a failed job with exponential backoff, or moves it to the dead set
+// when it has no retries left
+func (q *Queue) Retry(ctx context.Context, job *Job, cause error) error {
+	if !q.canRetry(job) {
+		return q.Kill(ctx, job, cause)
+	}
+
+	runAt := time.Now().Add(q.backoff(job.Attempts))
+	n, err := retryScript.Run(ctx, q.client,
+		[]string{q.key("inflight"), q.key("scheduled"), q.key("errors")},
+		job.ID, runAt.UnixMilli(), errorMessage(cause),
+	).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to retry job: %w", err)
+	}
+	if n == 0 {
+		return ErrJobNotClaimed
+	}
+	return nil
+}
+
+// Kill moves a claimed job to the dead set
+func (q *Queue) Kill(ctx context.Context, job *Job, cause error) error {
+	n, err := killScript.Run(ctx, q.client,
+		[]string{q.key("inflight"), q.key("dead"), q.key("errors"), q.uniqueKey(job.UniqueKey)},
+		job.ID, time.Now().UnixMilli(), errorMessage(cause),
+	).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to kill job: %w", err)
+	}
+	if n == 0 {
+		return ErrJobNotClaimed
+	}
+	return nil
+}
+
+// Extend pushes back the visibility deadline of a claimed job
+func (q *Queue) Extend(ctx context.Context, job *Job) error {
+	n, err := q.client.ZAddArgs(ctx, q.key("inflight"), redis.ZAddArgs{
+		XX:      true,
+		Ch:      true,
+		Members: []redis.Z{{Score: float64(time.Now().Add(q.config.VisibilityTimeout).UnixMilli()), Member: job.ID}},
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to extend job: %w", err)
+	}
+	if n == 0 {
+		return ErrJobNotClaimed
+	}
+	return nil
+}
+
+// Stats returns the number of scheduled, in-flight and dead jobs
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	var scheduled, inflight, dead *redis.IntCmd
+	_, err := q.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		scheduled = pipe.ZCard(ctx, q.key("scheduled"))
+		inflight = pipe.ZCard(ctx, q.key("inflight"))
+		dead = pipe.ZCard(ctx, q.key("dead"))
+		return nil
+	})
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to get queue stats: %w", err)
+	}
+
+	return Stats{
+		Scheduled: scheduled.Val(),
+		InFlight:  inflight.Val(),
+		Dead:      dead.Val(),
+	}, nil
+}
+
+// maxAttempts returns how many times a job may run in total
+func (q *Queue) maxAttempts(job *Job) int {
+	maxRetries := job.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = q.config.DefaultMaxRetries
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return maxRetries + 1
+}
+
+// canRetry reports whether the job has retries left
+func (q *Queue) canRetry(job *Job) bool {
+	return job.Attempts < q.maxAttempts(job)
+}
+
+// backoff returns the delay before the next attempt: the base delay doubled for every
+// previous attempt, capped at the max delay, with equal jitter
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.config.RetryMaxDelay
+	if attempts < 32 {
+		if d := q.config.RetryBaseDelay << (max(attempts, 1) - 1); d > 0 && d < delay {
+			delay = d
+		}
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// key returns the Redis key for one of the queue structures. The hash tag keeps every
+// key of a queue in the same cluster slot so the scripts can touch them atomically.
+func (q *Queue) key(name string) string {
+	return q.config.KeyPrefix + "{" + q.config.Name + "}:" + name
+}
+
+// uniqueKey returns the Redis key guarding a unique job
+func (q *Queue) uniqueKey(key string) string {
+	return q.key("unique:" + key)
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,56 @@
+package _queue_redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueBackoff(t *testing.T) {
+	q := &Queue{config: Config{RetryBaseDelay: time.Second, RetryMaxDelay: 10 * time.Second}}
+
+	tests := []struct {
+		name     string
+		attempts int
+		maxDelay time.Duration
+	}{
+		{name: "First attempt", attempts: 1, maxDelay: time.Second},
+		{name: "Second attempt", attempts: 2, maxDelay: 2 * time.Second},
+		{name: "Third attempt", attempts: 3, maxDelay: 4 * time.Second},
+		{name: "Capped", attempts: 10, maxDelay: 10 * time.Second},
+		{name: "Overflow", attempts: 100, maxDelay: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				delay := q.backoff(tt.attempts)
+				if delay < tt.maxDelay/2 || delay > tt.maxDelay {
+					t.Fatalf("backoff(%d) = %v, want within [%v, %v]", tt.attempts, delay, tt.maxDelay/2, tt.maxDelay)
+				}
+			}
+		})
+	}
+}
+
+func TestQueueMaxAttempts(t *testing.T) {
+	q := &Queue{config: Config{DefaultMaxRetries: 3}}
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		expected   int
+	}{
+		{name: "Queue default", maxRetries: 0, expected: 4},
+		{name: "Job override", maxRetries: 5, expected: 6},
+		{name: "No retry", maxRetries: NoRetry, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := q.maxAttempts(&Job{MaxRetries: tt.maxRetries})
+			if result != tt.expected {
+				t.Errorf("maxAttempts() = %d, want %d", result, tt.expected)
+			}
+		})
+	}
+}
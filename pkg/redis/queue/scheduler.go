@@ -0,0 +1,179 @@
+package _queue_redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// cronEntry is a recurring job registered on the scheduler
+type cronEntry struct {
+	name     string
+	schedule cron.Schedule
+	template Job
+	next     time.Time
+}
+
+// Scheduler enqueues recurring jobs from cron expressions. Every instance may run a
+// scheduler; each occurrence is enqueued exactly once across all of them.
+type Scheduler struct {
+	queue   *Queue
+	entries map[string]*cronEntry
+
+	// Control
+	cancel context.CancelFunc
+	doneCh chan struct{}
+
+	// State
+	running bool
+	mu      sync.Mutex
+}
+
+// NewScheduler creates a new cron scheduler for the queue
+func NewScheduler(queue *Queue) *Scheduler {
+	return &Scheduler{
+		queue:   queue,
+		entries: make(map[string]*cronEntry),
+	}
+}
+
+// AddCron registers a recurring job. The spec uses the standard five field cron format
+// and descriptors such as "@hourly". Every occurrence enqueues a copy of job.
+func (s *Scheduler) AddCron(name, spec string, job Job) error {
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if job.Type == "" {
+		return errors.New("job type is required")
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("invalid cron spec: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[name] = &cronEntry{
+		name:     name,
+		schedule: schedule,
+		template: job,
+		next:     schedule.Next(time.Now()),
+	}
+
+	return nil
+}
+
+// RemoveCron unregisters a recurring job
+func (s *Scheduler) RemoveCron(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, name)
+}
+
+// Start begins enqueueing recurring jobs
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return fmt.Errorf("scheduler already started")
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.doneCh = make(chan struct{})
+	s.running = true
+
+	go s.run(runCtx, s.doneCh)
+
+	return nil
+}
+
+// Stop stops enqueueing recurring jobs
+func (s *Scheduler) Stop() error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	cancel, doneCh := s.cancel, s.doneCh
+	s.mu.Unlock()
+
+	// running stays set until the goroutine has exited so Start cannot begin another one
+	cancel()
+	<-doneCh
+
+	s.mu.Lock()
+	s.running = false
+	s.mu.Unlock()
+
+	return nil
+}
+
+// run checks for due occurrences every second
+func (s *Scheduler) run(ctx context.Context, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.tick(ctx, now)
+		}
+	}
+}
+
+// tick enqueues every occurrence that is due
+func (s *Scheduler) tick(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	var due []cronEntry
+	for _, entry := range s.entries {
+		if !entry.next.After(now) {
+			due = append(due, *entry)
+			entry.next = entry.schedule.Next(now)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, entry := range due {
+		if err := s.enqueue(ctx, entry); err != nil {
+			fmt.Printf("Failed to enqueue cron job %s: %v\n", entry.name, err)
+		}
+	}
+}
+
+// enqueue claims the occurrence so only one instance enqueues it, then enqueues the job
+func (s *Scheduler) enqueue(ctx context.Context, entry cronEntry) error {
+	occurrence := strconv.FormatInt(entry.next.Unix(), 10)
+	key := s.queue.key("cron:" + entry.name + ":" + occurrence)
+
+	claimed, err := s.queue.client.SetNX(ctx, key, 1, s.queue.config.UniqueTTL).Result()
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	job := entry.template
+	job.ID = uuid.New().String()
+	job.RunAt = entry.next
+
+	_, err = s.queue.Enqueue(ctx, &job)
+	if errors.Is(err, ErrDuplicateJob) {
+		return nil
+	}
+	return err
+}
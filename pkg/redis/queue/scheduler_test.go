@@ -0,0 +1,92 @@
+package _queue_redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// blockHook holds every command until release is closed, after signalling entered
+type blockHook struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (h blockHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h blockHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		select {
+		case h.entered <- struct{}{}:
+		default:
+		}
+		<-h.release
+		return next(ctx, cmd)
+	}
+}
+
+func (h blockHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestSchedulerStartWhileStopping(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	queue, err := NewQueue(client, DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewQueue() error = %v", err)
+	}
+	scheduler := NewScheduler(queue)
+	if err := scheduler.AddCron("report", "@hourly", Job{Type: "report"}); err != nil {
+		t.Fatalf("AddCron() error = %v", err)
+	}
+	// Make the entry due on the first tick
+	scheduler.entries["report"].next = time.Now()
+
+	// The first tick blocks in Redis, so the run loop outlives the call to Stop
+	hook := blockHook{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	client.AddHook(hook)
+
+	ctx := context.Background()
+	if err := scheduler.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	select {
+	case <-hook.entered:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for the first tick")
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- scheduler.Stop() }()
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if err := scheduler.Start(ctx); err == nil {
+			t.Fatalf("Start() while the run loop is still running error = nil, want error")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(hook.release)
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Stop() did not return")
+	}
+
+	if err := scheduler.Start(ctx); err != nil {
+		t.Fatalf("Start() after Stop error = %v", err)
+	}
+	scheduler.Stop()
+}
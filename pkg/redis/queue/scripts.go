@@ -0,0 +1,85 @@
+package _queue_redis
+
+import "github.com/redis/go-redis/v9"
+
+// enqueueScript stores the job and schedules it, honouring the unique key if one is given.
+// KEYS[1] = scheduled, KEYS[2] = jobs, KEYS[3] = unique key
+// ARGV[1] = id, ARGV[2] = job data, ARGV[3] = due time in ms, ARGV[4] = unique ttl in ms (0 = not unique)
+var enqueueScript = redis.NewScript(`
+if tonumber(ARGV[4]) > 0 then
+	if not redis.call("SET", KEYS[3], ARGV[1], "NX", "PX", ARGV[4]) then
+		return redis.call("GET", KEYS[3])
+	end
+end
+redis.call("HSET", KEYS[2], ARGV[1], ARGV[2])
+redis.call("ZADD", KEYS[1], ARGV[3], ARGV[1])
+return ARGV[1]
+`)
+
+// claimScript requeues jobs whose visibility timeout expired, then moves due jobs to
+// the in-flight set and returns {data, attempts, data, attempts, ...}.
+// KEYS[1] = scheduled, KEYS[2] = inflight, KEYS[3] = jobs, KEYS[4] = attempts
+// ARGV[1] = now in ms, ARGV[2] = visibility deadline in ms, ARGV[3] = limit
+var claimScript = redis.NewScript(`
+local expired = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1])
+for _, id in ipairs(expired) do
+	redis.call("ZREM", KEYS[2], id)
+	redis.call("ZADD", KEYS[1], ARGV[1], id)
+end
+
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+local result = {}
+for _, id in ipairs(ids) do
+	redis.call("ZREM", KEYS[1], id)
+	local data = redis.call("HGET", KEYS[3], id)
+	if data then
+		redis.call("ZADD", KEYS[2], ARGV[2], id)
+		table.insert(result, data)
+		table.insert(result, redis.call("HINCRBY", KEYS[4], id, 1))
+	end
+end
+return result
+`)
+
+// completeScript removes a finished job and releases its unique key.
+// KEYS[1] = inflight, KEYS[2] = scheduled, KEYS[3] = jobs, KEYS[4] = attempts, KEYS[5] = errors, KEYS[6] = unique key
+// ARGV[1] = id
+var completeScript = redis.NewScript(`
+redis.call("ZREM", KEYS[1], ARGV[1])
+redis.call("ZREM", KEYS[2], ARGV[1])
+redis.call("HDEL", KEYS[3], ARGV[1])
+redis.call("HDEL", KEYS[4], ARGV[1])
+redis.call("HDEL", KEYS[5], ARGV[1])
+if redis.call("GET", KEYS[6]) == ARGV[1] then
+	redis.call("DEL", KEYS[6])
+end
+return 1
+`)
+
+// retryScript moves a claimed job back to the scheduled set. It returns 0 if the job
+// is no longer claimed, for example because its visibility timeout expired.
+// KEYS[1] = inflight, KEYS[2] = scheduled, KEYS[3] = errors
+// ARGV[1] = id, ARGV[2] = due time in ms, ARGV[3] = error message
+var retryScript = redis.NewScript(`
+if redis.call("ZREM", KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call("ZADD", KEYS[2], ARGV[2], ARGV[1])
+redis.call("HSET", KEYS[3], ARGV[1], ARGV[3])
+return 1
+`)
+
+// killScript moves a claimed job to the dead set, keeping its data for inspection.
+// KEYS[1] = inflight, KEYS[2] = dead, KEYS[3] = errors, KEYS[4] = unique key
+// ARGV[1] = id, ARGV[2] = now in ms, ARGV[3] = error message
+var killScript = redis.NewScript(`
+if redis.call("ZREM", KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call("ZADD", KEYS[2], ARGV[2], ARGV[1])
+redis.call("HSET", KEYS[3], ARGV[1], ARGV[3])
+if redis.call("GET", KEYS[4]) == ARGV[1] then
+	redis.call("DEL", KEYS[4])
+end
+return 1
+`)
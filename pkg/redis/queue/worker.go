@@ -0,0 +1,93 @@
+package _queue_redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	_queue "go-libs/pkg/queue"
+)
+
+// Handler processes jobs of one type. Returning an error retries the job with backoff.
+type Handler interface {
+	Handle(ctx context.Context, job *Job) error
+}
+
+// HandlerFunc adapts a function to the Handler interface
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// Handle calls f(ctx, job)
+func (f HandlerFunc) Handle(ctx context.Context, job *Job) error {
+	return f(ctx, job)
+}
+
+// Worker is a pool of goroutines that claims and processes jobs from a queue
+type Worker struct {
+	queue    *Queue
+	config   WorkerConfig
+	pool     *_queue.Pool[*Job]
+	handlers map[string]Handler
+	mu       sync.RWMutex
+}
+
+// NewWorker creates a new worker pool for the queue
+func NewWorker(queue *Queue, config WorkerConfig) *Worker {
+	w := &Worker{
+		queue:    queue,
+		config:   config,
+		handlers: make(map[string]Handler),
+	}
+	w.pool = _queue.NewPool(queue, w.handle, _queue.PoolConfig{
+		Concurrency:       config.Concurrency,
+		PollInterval:      config.PollInterval,
+		ShutdownTimeout:   config.ShutdownTimeout,
+		HeartbeatInterval: queue.config.VisibilityTimeout / 2,
+	})
+	return w
+}
+
+// Register sets the handler for a job type. It must be called before Start.
+func (w *Worker) Register(jobType string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[jobType] = handler
+}
+
+// Start begins polling the queue
+func (w *Worker) Start(ctx context.Context) error {
+	if err := w.config.Validate(); err != nil {
+		return fmt.Errorf("invalid worker config: %w", err)
+	}
+	return w.pool.Start(ctx)
+}
+
+// Stop stops claiming jobs and waits up to ShutdownTimeout for running jobs to finish.
+// Jobs still running after the timeout are cancelled and become visible again once
+// their visibility timeout expires.
+func (w *Worker) Stop() error {
+	return w.pool.Stop()
+}
+
+// IsRunning checks if the worker is active
+func (w *Worker) IsRunning() bool {
+	return w.pool.IsRunning()
+}
+
+// handle runs the handler registered for the type of a job
+func (w *Worker) handle(ctx context.Context, job *Job) error {
+	// The job may have been handed out again after its visibility timeout expired
+	// more times than it is allowed to run
+	if job.Attempts > w.queue.maxAttempts(job) {
+		return _queue.Permanent(errors.New("max attempts exceeded"))
+	}
+
+	w.mu.RLock()
+	handler, ok := w.handlers[job.Type]
+	w.mu.RUnlock()
+	if !ok {
+		return _queue.Permanent(fmt.Errorf("no handler registered for job type %s", job.Type))
+	}
+
+	return handler.Handle(ctx, job)
+}
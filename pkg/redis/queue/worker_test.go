@@ -0,0 +1,74 @@
+package _queue_redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestWorkerStopWhileJobsStart(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	queue, err := NewQueue(client, DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewQueue() error = %v", err)
+	}
+
+	ctx := context.Background()
+	const jobs = 50
+	for i := 0; i < jobs; i++ {
+		job, err := NewJob("email", i)
+		if err != nil {
+			t.Fatalf("NewJob() error = %v", err)
+		}
+		if _, err := queue.Enqueue(ctx, job); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	config := DefaultWorkerConfig()
+	config.Concurrency = jobs
+	config.ShutdownTimeout = time.Second
+	worker := NewWorker(queue, config)
+
+	started := make(chan struct{}, jobs)
+	worker.Register("email", HandlerFunc(func(ctx context.Context, job *Job) error {
+		started <- struct{}{}
+		// Handlers may inspect the worker while it is stopping
+		worker.IsRunning()
+		return nil
+	}))
+
+	if err := worker.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Stop as soon as the first job runs, while the others are still starting
+	<-started
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- worker.Stop()
+	}()
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Stop() did not return")
+	}
+
+	if worker.IsRunning() {
+		t.Errorf("IsRunning() = true after Stop")
+	}
+	if err := worker.Start(ctx); err != nil {
+		t.Errorf("Start() after Stop error = %v", err)
+	}
+	worker.Stop()
+}
@@ -0,0 +1,100 @@
+package _ratelimit_redis
+
+import (
+	"errors"
+	"time"
+)
+
+// Algorithm selects how requests are counted
+type Algorithm string
+
+const (
+	// FixedWindow counts requests in a window that starts with the first request
+	FixedWindow Algorithm = "fixed_window"
+	// SlidingLog records a timestamp per request and counts those inside the window
+	SlidingLog Algorithm = "sliding_log"
+	// SlidingWindow approximates a sliding window by weighting the previous fixed window
+	SlidingWindow Algorithm = "sliding_window"
+	// GCRA is the generic cell rate algorithm, a token bucket storing a single timestamp
+	GCRA Algorithm = "gcra"
+)
+
+// Config holds the configuration for a rate limiter
+type Config struct {
+	// KeyPrefix is prepended to every rate limit key
+	KeyPrefix string
+	// Algorithm is the counting algorithm to use
+	Algorithm Algorithm
+}
+
+// DefaultConfig returns a rate limiter configuration with sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		KeyPrefix: "ratelimit:",
+		Algorithm: GCRA,
+	}
+}
+
+// Validate checks if the configuration is valid
+func (c *Config) Validate() error {
+	switch c.Algorithm {
+	case FixedWindow, SlidingLog, SlidingWindow, GCRA:
+		return nil
+	default:
+		return errors.New("unknown rate limit algorithm")
+	}
+}
+
+// Limit describes how many requests are allowed per period
+type Limit struct {
+	// Rate is the number of requests allowed per period
+	Rate int
+	// Period is the length of the window
+	Period time.Duration
+	// Burst is the bucket size for GCRA, defaults to Rate. Ignored by the window algorithms.
+	Burst int
+}
+
+// PerSecond returns a limit of rate requests per second
+func PerSecond(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Second, Burst: rate}
+}
+
+// PerMinute returns a limit of rate requests per minute
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute, Burst: rate}
+}
+
+// PerHour returns a limit of rate requests per hour
+func PerHour(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Hour, Burst: rate}
+}
+
+// Validate checks if the limit is valid
+func (l Limit) Validate() error {
+	if l.Rate <= 0 {
+		return errors.New("rate must be greater than 0")
+	}
+	if l.Period < time.Millisecond {
+		return errors.New("period must be at least 1ms")
+	}
+	if l.Burst < 0 {
+		return errors.New("burst must be greater than or equal to 0")
+	}
+	return nil
+}
+
+// Result is the outcome of a rate limit check
+type Result struct {
+	// Allowed reports whether the request may proceed
+	Allowed bool
+	// Remaining is how many more requests are allowed right now
+	Remaining int
+	// RetryAfter is how long to wait before the request would be allowed.
+	// It is 0 when allowed and -1 when the request can never be allowed.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the limiter returns to its full capacity
+	ResetAfter time.Duration
+	// Limit is the limit that was applied
+	Limit Limit
+}
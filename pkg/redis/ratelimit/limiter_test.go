@@ -0,0 +1,208 @@
+package _ratelimit_redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type limiterStep struct {
+	advance    time.Duration
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+}
+
+func TestLimiterAllow(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	limit := PerSecond(4)
+
+	// The first four requests at the same instant use up the limit
+	afterBurst := func(steps ...limiterStep) []limiterStep {
+		return append([]limiterStep{
+			{allowed: true, remaining: 3},
+			{allowed: true, remaining: 2},
+			{allowed: true, remaining: 1},
+			{allowed: true, remaining: 0},
+		}, steps...)
+	}
+
+	tests := []struct {
+		name      string
+		algorithm Algorithm
+		steps     []limiterStep
+	}{
+		{
+			name:      "fixed window",
+			algorithm: FixedWindow,
+			steps: afterBurst(
+				limiterStep{allowed: false, remaining: 0, retryAfter: time.Second},
+				limiterStep{advance: 500 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond},
+				// The window expires and the counter starts over
+				limiterStep{advance: 501 * time.Millisecond, allowed: true, remaining: 3},
+			),
+		},
+		{
+			name:      "sliding log",
+			algorithm: SlidingLog,
+			steps: afterBurst(
+				limiterStep{allowed: false, remaining: 0, retryAfter: time.Second},
+				limiterStep{advance: 500 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond},
+				// Every logged request has left the window
+				limiterStep{advance: 501 * time.Millisecond, allowed: true, remaining: 3},
+			),
+		},
+		{
+			name:      "sliding window",
+			algorithm: SlidingWindow,
+			steps: afterBurst(
+				limiterStep{allowed: false, remaining: 0, retryAfter: time.Second},
+				limiterStep{advance: 500 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond},
+				// Half way into the next window, half of the previous window still counts
+				limiterStep{advance: time.Second, allowed: true, remaining: 1},
+				// Two windows later nothing counts
+				limiterStep{advance: 2 * time.Second, allowed: true, remaining: 3},
+			),
+		},
+		{
+			name:      "GCRA",
+			algorithm: GCRA,
+			steps: afterBurst(
+				limiterStep{allowed: false, remaining: 0, retryAfter: 250 * time.Millisecond},
+				// One emission interval later a single request is allowed
+				limiterStep{advance: 250 * time.Millisecond, allowed: true, remaining: 0},
+				// The bucket refills after a full period
+				limiterStep{advance: time.Second, allowed: true, remaining: 3},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Algorithm = tt.algorithm
+			limiter, err := NewLimiter(client, config)
+			if err != nil {
+				t.Fatalf("NewLimiter() error = %v", err)
+			}
+			ctx := context.Background()
+			server.FlushAll()
+
+			// Start on a window boundary so the sliding window weights are exact
+			now := time.Unix(1700000000, 0)
+			server.SetTime(now)
+
+			for i, step := range tt.steps {
+				now = now.Add(step.advance)
+				server.SetTime(now)
+				server.FastForward(step.advance)
+
+				result, err := limiter.Allow(ctx, "user:1", limit)
+				if err != nil {
+					t.Fatalf("step %d: Allow() error = %v", i, err)
+				}
+				if result.Allowed != step.allowed {
+					t.Errorf("step %d: Allowed = %v, want %v", i, result.Allowed, step.allowed)
+				}
+				if result.Remaining != step.remaining {
+					t.Errorf("step %d: Remaining = %v, want %v", i, result.Remaining, step.remaining)
+				}
+				if result.RetryAfter != step.retryAfter {
+					t.Errorf("step %d: RetryAfter = %v, want %v", i, result.RetryAfter, step.retryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterAllowNAboveLimit(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	tests := []struct {
+		name      string
+		algorithm Algorithm
+	}{
+		{name: "fixed window", algorithm: FixedWindow},
+		{name: "sliding log", algorithm: SlidingLog},
+		{name: "sliding window", algorithm: SlidingWindow},
+		{name: "GCRA", algorithm: GCRA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Algorithm = tt.algorithm
+			limiter, err := NewLimiter(client, config)
+			if err != nil {
+				t.Fatalf("NewLimiter() error = %v", err)
+			}
+
+			result, err := limiter.AllowN(context.Background(), tt.name, PerSecond(4), 5)
+			if err != nil {
+				t.Fatalf("AllowN() error = %v", err)
+			}
+			if result.Allowed {
+				t.Errorf("Allowed = true, want false")
+			}
+			if result.Remaining != 4 {
+				t.Errorf("Remaining = %v, want %v", result.Remaining, 4)
+			}
+			if result.RetryAfter != -1 {
+				t.Errorf("RetryAfter = %v, want %v", result.RetryAfter, time.Duration(-1))
+			}
+		})
+	}
+}
+
+func TestLimiterReset(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	tests := []struct {
+		name      string
+		used      int
+		reset     bool
+		wantAllow bool
+	}{
+		{name: "Limit used up", used: 4, wantAllow: false},
+		{name: "Reset after limit used up", used: 4, reset: true, wantAllow: true},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Algorithm = GCRA
+			limiter, err := NewLimiter(client, config)
+			if err != nil {
+				t.Fatalf("NewLimiter() error = %v", err)
+			}
+
+			if _, err := limiter.AllowN(ctx, tt.name, PerSecond(4), tt.used); err != nil {
+				t.Fatalf("AllowN() error = %v", err)
+			}
+			if tt.reset {
+				if err := limiter.Reset(ctx, tt.name); err != nil {
+					t.Fatalf("Reset() error = %v", err)
+				}
+			}
+
+			result, err := limiter.Allow(ctx, tt.name, PerSecond(4))
+			if err != nil {
+				t.Fatalf("Allow() error = %v", err)
+			}
+			if result.Allowed != tt.wantAllow {
+				t.Errorf("Allow() = %v, want %v", result.Allowed, tt.wantAllow)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package _ratelimit_redis
+
+import "github.com/redis/go-redis/v9"
+
+// All scripts read the clock from Redis so every pod agrees on the current time.
+// Times are in microseconds and every script returns
+// {allowed, remaining, retry_after_us, reset_after_us}.
+
+// fixedWindowScript
+// KEYS[1] = counter key, ARGV[1] = limit, ARGV[2] = window in ms, ARGV[3] = cost
+var fixedWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local count = tonumber(redis.call("GET", KEYS[1]) or "0")
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	ttl = window
+end
+
+if count + cost > limit then
+	local retry = ttl * 1000
+	if cost > limit then
+		retry = -1
+	end
+	return {0, math.max(limit - count, 0), retry, ttl * 1000}
+end
+
+count = redis.call("INCRBY", KEYS[1], cost)
+if count == cost then
+	redis.call("PEXPIRE", KEYS[1], window)
+end
+
+return {1, limit - count, 0, ttl * 1000}
+`)
+
+// slidingLogScript
+// KEYS[1] = log key, ARGV[1] = limit, ARGV[2] = window in us, ARGV[3] = cost, ARGV[4] = request id
+var slidingLogScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", "(" .. string.format("%.0f", now - window))
+local count = redis.call("ZCARD", KEYS[1])
+
+local reset = 0
+local newest = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+if #newest > 0 then
+	reset = tonumber(newest[2]) + window - now
+end
+
+if count + cost > limit then
+	if cost > limit then
+		return {0, math.max(limit - count, 0), -1, reset}
+	end
+	local oldest = redis.call("ZRANGE", KEYS[1], count + cost - limit - 1, count + cost - limit - 1, "WITHSCORES")
+	return {0, math.max(limit - count, 0), tonumber(oldest[2]) + window - now, reset}
+end
+
+local score = string.format("%.0f", now)
+for i = 1, cost do
+	redis.call("ZADD", KEYS[1], score, ARGV[4] .. ":" .. i)
+end
+redis.call("PEXPIRE", KEYS[1], math.ceil(window / 1000))
+
+return {1, limit - count - cost, 0, window}
+`)
+
+// slidingWindowScript
+// KEYS[1] = hash of window counters, ARGV[1] = limit, ARGV[2] = window in us, ARGV[3] = cost
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local current = math.floor(now / window)
+local elapsed = (now - current * window) / window
+local currentField = string.format("%.0f", current)
+local previousField = string.format("%.0f", current - 1)
+
+local counts = redis.call("HMGET", KEYS[1], currentField, previousField)
+local currentCount = tonumber(counts[1] or "0")
+local previousCount = tonumber(counts[2] or "0")
+local weighted = math.floor(previousCount * (1 - elapsed)) + currentCount
+
+local reset = (current + 1) * window - now
+if previousCount > 0 then
+	reset = reset + window
+end
+
+if weighted + cost > limit then
+	if cost > limit then
+		return {0, math.max(limit - weighted, 0), -1, reset}
+	end
+	-- Wait until enough of the previous window has slid out, or the next window starts
+	local retry = (current + 1) * window - now
+	if previousCount > 0 then
+		local needed = weighted + cost - limit
+		local slide = math.ceil(needed * window / previousCount)
+		if currentCount + cost <= limit and slide < retry then
+			retry = slide
+		end
+	end
+	return {0, math.max(limit - weighted, 0), retry, reset}
+end
+
+redis.call("HINCRBY", KEYS[1], currentField, cost)
+redis.call("HDEL", KEYS[1], string.format("%.0f", current - 2))
+redis.call("PEXPIRE", KEYS[1], math.ceil(window * 2 / 1000))
+
+return {1, limit - weighted - cost, 0, reset}
+`)
+
+// gcraScript
+// KEYS[1] = theoretical arrival time key, ARGV[1] = burst, ARGV[2] = emission interval in us, ARGV[3] = cost
+var gcraScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local emission = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local burstOffset = emission * burst
+local tat = tonumber(redis.call("GET", KEYS[1]) or "0")
+if tat < now then
+	tat = now
+end
+
+local newTat = tat + emission * cost
+local allowAt = newTat - burstOffset
+local diff = now - allowAt
+
+if diff < 0 then
+	local retry = -diff
+	if cost > burst then
+		retry = -1
+	end
+	local remaining = math.floor((now - (tat - burstOffset)) / emission)
+	return {0, math.max(remaining, 0), retry, tat - now}
+end
+
+local reset = newTat - now
+redis.call("SET", KEYS[1], string.format("%.0f", newTat), "PX", math.ceil(reset / 1000))
+
+return {1, math.floor(diff / emission), 0, reset}
+`)
@@ -13,6 +13,8 @@ type SentinelConnection struct {
 	config       SentinelConfig
 	masterClient *redis.Client
 	slaveClient  *redis.Client
+	replicas     *replicaRouter
+	hooks        []redis.Hook
 }
 
 // NewSentinelConnection creates a new Redis connection using Sentinel
@@ -31,14 +33,16 @@ func (c *SentinelConnection) Connect(ctx context.Context) error {
 	connectCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	options, err := c.config.FailoverOptions()
+	if err != nil {
+		return fmt.Errorf("invalid sentinel config: %w", err)
+	}
+
 	// Create a failover client that uses Sentinel for automatic master discovery and failover
-	c.masterClient = redis.NewFailoverClient(&redis.FailoverOptions{
-		MasterName:       c.config.MasterName,
-		SentinelAddrs:    c.config.SentinelAddresses,
-		Password:         c.config.Password,
-		DB:               c.config.DB,
-		SentinelPassword: c.config.SentinelPassword,
-	})
+	c.masterClient = redis.NewFailoverClient(options)
+	for _, hook := range c.hooks {
+		c.masterClient.AddHook(hook)
+	}
 
 	// Test the master connection
 	if err := c.masterClient.Ping(connectCtx).Err(); err != nil {
@@ -48,14 +52,12 @@ func (c *SentinelConnection) Connect(ctx context.Context) error {
 	// If slave connection is requested, establish it
 	if c.config.UseSlaveConnection {
 		// Create a failover client that connects to slaves for read-only operations
-		c.slaveClient = redis.NewFailoverClient(&redis.FailoverOptions{
-			MasterName:       c.config.MasterName,
-			SentinelAddrs:    c.config.SentinelAddresses,
-			Password:         c.config.Password,
-			DB:               c.config.DB,
-			SentinelPassword: c.config.SentinelPassword,
-			ReplicaOnly:      true, // Connect only to replicas
-		})
+		slaveOptions := *options
+		slaveOptions.ReplicaOnly = true // Connect only to replicas
+		c.slaveClient = redis.NewFailoverClient(&slaveOptions)
+		for _, hook := range c.hooks {
+			c.slaveClient.AddHook(hook)
+		}
 
 		// Test the slave connection
 		if err := c.slaveClient.Ping(connectCtx).Err(); err != nil {
@@ -63,6 +65,10 @@ func (c *SentinelConnection) Connect(ctx context.Context) error {
 			c.masterClient.Close()
 			return fmt.Errorf("failed to connect to Redis slave using Sentinel: %w", err)
 		}
+
+		// Discover every replica so reads can be spread across them
+		c.replicas = newReplicaRouter(c.config, options, c.masterClient, c.hooks)
+		c.replicas.start(connectCtx)
 	}
 
 	return nil
@@ -70,7 +76,11 @@ func (c *SentinelConnection) Connect(ctx context.Context) error {
 
 // Close closes the Redis connection
 func (c *SentinelConnection) Close() error {
-	var masterErr, slaveErr error
+	var masterErr, slaveErr, replicaErr error
+
+	if c.replicas != nil {
+		replicaErr = c.replicas.close()
+	}
 
 	if c.masterClient != nil {
 		masterErr = c.masterClient.Close()
@@ -87,6 +97,9 @@ func (c *SentinelConnection) Close() error {
 	if slaveErr != nil {
 		return fmt.Errorf("error closing slave connection: %w", slaveErr)
 	}
+	if replicaErr != nil {
+		return fmt.Errorf("error closing replica connections: %w", replicaErr)
+	}
 
 	return nil
 }
@@ -109,15 +122,52 @@ func (c *SentinelConnection) GetMasterClient() *redis.Client {
 	return c.masterClient
 }
 
-// GetSlaveClient returns a client connected to a replica/slave node (read-only)
+// GetSlaveClient returns a client connected to a healthy replica chosen by the
+// ReplicaRouting policy. It falls back to the Sentinel managed replica client when no
+// discovered replica is healthy.
 func (c *SentinelConnection) GetSlaveClient() *redis.Client {
+	if c.replicas != nil {
+		if client := c.replicas.pick(); client != nil {
+			return client
+		}
+	}
 	return c.slaveClient
 }
 
+// GetReplicaStatus returns the replicas discovered through Sentinel and their health
+func (c *SentinelConnection) GetReplicaStatus() []ReplicaStatus {
+	if c.replicas == nil {
+		return nil
+	}
+	return c.replicas.status()
+}
+
+// RefreshReplicas rediscovers replicas from Sentinel immediately
+func (c *SentinelConnection) RefreshReplicas(ctx context.Context) {
+	if c.replicas != nil {
+		c.replicas.refresh(ctx)
+	}
+}
+
 // HasSlaveConnected returns true if a slave connection is available
 func (c *SentinelConnection) HasSlaveConnected() bool {
 	return c.slaveClient != nil
 }
 
+// AddHook attaches a go-redis hook, such as NewInstrumentationHook, to the master, slave
+// and replica clients. Hooks added before Connect are installed when the clients are created.
+func (c *SentinelConnection) AddHook(hook redis.Hook) {
+	c.hooks = append(c.hooks, hook)
+	if c.masterClient != nil {
+		c.masterClient.AddHook(hook)
+	}
+	if c.slaveClient != nil {
+		c.slaveClient.AddHook(hook)
+	}
+	if c.replicas != nil {
+		c.replicas.addHook(hook)
+	}
+}
+
 // Ensure SentinelConnection implements SentinelClient interface
 var _ SentinelClient = (*SentinelConnection)(nil)
@@ -0,0 +1,463 @@
+package _redis
+
+import (
+	"bufio"
+	"context"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Replica routing policies for SentinelConfig.ReplicaRouting
+const (
+	ReplicaRoutingRandom     = "random"
+	ReplicaRoutingRoundRobin = "round_robin"
+	ReplicaRoutingLatency    = "latency"
+)
+
+// defaultReplicaRefreshInterval is used when SentinelConfig.ReplicaRefreshInterval is 0
+const defaultReplicaRefreshInterval = 10 * time.Second
+
+// sentinelEvents are the Sentinel pub/sub channels that signal a topology change
+var sentinelEvents = []string{
+	"+switch-master",
+	"+sdown",
+	"-sdown",
+	"+odown",
+	"-odown",
+	"+slave",
+	"+convert-to-slave",
+	"+reboot",
+}
+
+// ReplicaStatus describes a replica discovered through Sentinel
+type ReplicaStatus struct {
+	Addr    string
+	Healthy bool
+	Latency time.Duration
+	Lag     time.Duration
+}
+
+// replicaNode is a replica with its own client
+type replicaNode struct {
+	addr    string
+	client  *redis.Client
+	healthy bool
+	latency time.Duration
+	lag     time.Duration
+}
+
+// replicaRouter discovers replicas through Sentinel, health checks them and picks
+// one for each read according to the routing policy
+type replicaRouter struct {
+	config    SentinelConfig
+	options   *redis.FailoverOptions
+	master    *redis.Client
+	sentinels []*redis.SentinelClient
+	hooks     []redis.Hook
+
+	mu      sync.RWMutex
+	nodes   map[string]*replicaNode
+	healthy []*replicaNode
+	counter atomic.Uint64
+	closed  bool
+
+	refreshCh chan struct{}
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+// newReplicaRouter creates a router for the replicas of the configured master
+func newReplicaRouter(config SentinelConfig, options *redis.FailoverOptions, master *redis.Client, hooks []redis.Hook) *replicaRouter {
+	if config.ReplicaRefreshInterval == 0 {
+		config.ReplicaRefreshInterval = defaultReplicaRefreshInterval
+	}
+
+	sentinels := make([]*redis.SentinelClient, 0, len(config.SentinelAddresses))
+	for _, addr := range config.SentinelAddresses {
+		sentinels = append(sentinels, redis.NewSentinelClient(&redis.Options{
+			Addr:        addr,
+			Username:    config.SentinelUsername,
+			Password:    config.SentinelPassword,
+			TLSConfig:   options.TLSConfig,
+			DialTimeout: options.DialTimeout,
+		}))
+	}
+
+	return &replicaRouter{
+		config:    config,
+		options:   options,
+		master:    master,
+		sentinels: sentinels,
+		hooks:     append([]redis.Hook(nil), hooks...),
+		nodes:     make(map[string]*replicaNode),
+		refreshCh: make(chan struct{}, 1),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+}
+
+// start performs the first discovery and keeps the replica list up to date
+func (r *replicaRouter) start(ctx context.Context) {
+	r.refresh(ctx)
+	go r.run()
+}
+
+// close stops the background refresh and closes every client. It is safe to call twice.
+func (r *replicaRouter) close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.stopCh)
+	<-r.doneCh
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for _, node := range r.nodes {
+		if err := node.client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, sentinel := range r.sentinels {
+		sentinel.Close()
+	}
+
+	r.nodes = nil
+	r.healthy = nil
+
+	return firstErr
+}
+
+// addHook installs a hook on every current and future replica client
+func (r *replicaRouter) addHook(hook redis.Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hooks = append(r.hooks, hook)
+	for _, node := range r.nodes {
+		node.client.AddHook(hook)
+	}
+}
+
+// pick returns a healthy replica client, or nil if there is none
+func (r *replicaRouter) pick() *redis.Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.healthy) == 0 {
+		return nil
+	}
+
+	switch r.config.ReplicaRouting {
+	case ReplicaRoutingRoundRobin:
+		return r.healthy[r.counter.Add(1)%uint64(len(r.healthy))].client
+	case ReplicaRoutingLatency:
+		best := r.healthy[0]
+		for _, node := range r.healthy[1:] {
+			if node.latency < best.latency {
+				best = node
+			}
+		}
+		return best.client
+	default:
+		return r.healthy[rand.IntN(len(r.healthy))].client
+	}
+}
+
+// status returns the state of every known replica
+func (r *replicaRouter) status() []ReplicaStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]ReplicaStatus, 0, len(r.nodes))
+	for _, node := range r.nodes {
+		result = append(result, ReplicaStatus{
+			Addr:    node.addr,
+			Healthy: node.healthy,
+			Latency: node.latency,
+			Lag:     node.lag,
+		})
+	}
+	return result
+}
+
+// run refreshes periodically and whenever Sentinel reports a topology change
+func (r *replicaRouter) run() {
+	defer close(r.doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go r.watch(ctx)
+
+	ticker := time.NewTicker(r.config.ReplicaRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+		case <-r.refreshCh:
+		}
+
+		refreshCtx, refreshCancel := context.WithTimeout(ctx, r.config.ReplicaRefreshInterval)
+		r.refresh(refreshCtx)
+		refreshCancel()
+	}
+}
+
+// watch listens for Sentinel events about the master and triggers a refresh
+func (r *replicaRouter) watch(ctx context.Context) {
+	for i := 0; ctx.Err() == nil; i++ {
+		sentinel := r.sentinels[i%len(r.sentinels)]
+		pubsub := sentinel.Subscribe(ctx, sentinelEvents...)
+
+		for {
+			msg, err := pubsub.ReceiveMessage(ctx)
+			if err != nil {
+				break
+			}
+			if strings.Contains(msg.Payload, r.config.MasterName) {
+				r.triggerRefresh()
+			}
+		}
+
+		pubsub.Close()
+
+		// Try the next sentinel after a short pause
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// triggerRefresh requests a refresh without blocking
+func (r *replicaRouter) triggerRefresh() {
+	select {
+	case r.refreshCh <- struct{}{}:
+	default:
+	}
+}
+
+// refresh rediscovers replicas from Sentinel and health checks them
+func (r *replicaRouter) refresh(ctx context.Context) {
+	replicas, ok := r.discover(ctx)
+	if !ok {
+		// Keep the current view if no sentinel answered, but still health check it
+		r.mu.RLock()
+		replicas = make(map[string]bool, len(r.nodes))
+		for addr := range r.nodes {
+			replicas[addr] = true
+		}
+		r.mu.RUnlock()
+	}
+
+	lags := r.replicationLags(ctx)
+
+	r.mu.Lock()
+	// A refresh started by RefreshReplicas may finish after close
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	// Add new replicas and drop those Sentinel no longer reports
+	for addr := range replicas {
+		if _, exists := r.nodes[addr]; !exists {
+			client := redis.NewClient(r.replicaOptions(addr))
+			for _, hook := range r.hooks {
+				client.AddHook(hook)
+			}
+			r.nodes[addr] = &replicaNode{
+				addr:   addr,
+				client: client,
+			}
+		}
+	}
+	for addr, node := range r.nodes {
+		if _, exists := replicas[addr]; !exists {
+			node.client.Close()
+			delete(r.nodes, addr)
+		}
+	}
+	nodes := make([]*replicaNode, 0, len(r.nodes))
+	for _, node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	r.mu.Unlock()
+
+	// Ping outside the lock so reads are not blocked by slow replicas
+	type check struct {
+		node    *replicaNode
+		healthy bool
+		latency time.Duration
+		lag     time.Duration
+	}
+	checks := make([]check, len(nodes))
+
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node *replicaNode) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := node.client.Ping(ctx).Err()
+			latency := time.Since(start)
+
+			lag, known := lags[node.addr]
+			healthy := err == nil && replicas[node.addr]
+			if r.config.MaxReplicaLag > 0 && known && lag > r.config.MaxReplicaLag {
+				healthy = false
+			}
+
+			checks[i] = check{node: node, healthy: healthy, latency: latency, lag: lag}
+		}(i, node)
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	healthy := make([]*replicaNode, 0, len(checks))
+	for _, c := range checks {
+		// The node may have been removed by a concurrent refresh
+		if r.nodes[c.node.addr] != c.node {
+			continue
+		}
+		c.node.healthy = c.healthy
+		c.node.latency = c.latency
+		c.node.lag = c.lag
+		if c.healthy {
+			healthy = append(healthy, c.node)
+		}
+	}
+	r.healthy = healthy
+}
+
+// replicaOptions returns the client options for one replica, sharing the credentials,
+// TLS and pool settings of the master
+func (r *replicaRouter) replicaOptions(addr string) *redis.Options {
+	return &redis.Options{
+		Addr:            addr,
+		Username:        r.options.Username,
+		Password:        r.options.Password,
+		DB:              r.options.DB,
+		TLSConfig:       r.options.TLSConfig,
+		PoolSize:        r.options.PoolSize,
+		MinIdleConns:    r.options.MinIdleConns,
+		MaxIdleConns:    r.options.MaxIdleConns,
+		ConnMaxIdleTime: r.options.ConnMaxIdleTime,
+		ConnMaxLifetime: r.options.ConnMaxLifetime,
+		PoolTimeout:     r.options.PoolTimeout,
+		DialTimeout:     r.options.DialTimeout,
+		ReadTimeout:     r.options.ReadTimeout,
+		WriteTimeout:    r.options.WriteTimeout,
+		MaxRetries:      r.options.MaxRetries,
+		MinRetryBackoff: r.options.MinRetryBackoff,
+		MaxRetryBackoff: r.options.MaxRetryBackoff,
+		Protocol:        r.options.Protocol,
+	}
+}
+
+// discover asks the sentinels for the replicas of the master. The value reports whether
+// the replica is usable according to Sentinel.
+func (r *replicaRouter) discover(ctx context.Context) (map[string]bool, bool) {
+	for _, sentinel := range r.sentinels {
+		replicas, err := sentinel.Replicas(ctx, r.config.MasterName).Result()
+		if err != nil {
+			continue
+		}
+
+		result := make(map[string]bool, len(replicas))
+		for _, replica := range replicas {
+			addr := net.JoinHostPort(replica["ip"], replica["port"])
+			result[addr] = isReplicaUsable(replica)
+		}
+		return result, true
+	}
+
+	return nil, false
+}
+
+// replicationLags reads the lag of each replica from the master's INFO replication
+func (r *replicaRouter) replicationLags(ctx context.Context) map[string]time.Duration {
+	lags := make(map[string]time.Duration)
+	if r.config.MaxReplicaLag <= 0 {
+		return lags
+	}
+
+	info, err := r.master.Info(ctx, "replication").Result()
+	if err != nil {
+		return lags
+	}
+
+	return parseReplicationLags(info)
+}
+
+// isReplicaUsable checks the flags Sentinel reports for a replica
+func isReplicaUsable(replica map[string]string) bool {
+	for _, flag := range strings.Split(replica["flags"], ",") {
+		switch flag {
+		case "s_down", "o_down", "disconnected":
+			return false
+		}
+	}
+	if status, ok := replica["master-link-status"]; ok && status != "ok" {
+		return false
+	}
+	return true
+}
+
+// parseReplicationLags parses lines such as
+// "slave0:ip=10.0.0.2,port=6379,state=online,offset=123,lag=1" from INFO replication
+func parseReplicationLags(info string) map[string]time.Duration {
+	lags := make(map[string]time.Duration)
+
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "slave") {
+			continue
+		}
+
+		_, fields, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		values := make(map[string]string)
+		for _, field := range strings.Split(fields, ",") {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				values[key] = value
+			}
+		}
+
+		lag, err := strconv.Atoi(values["lag"])
+		if err != nil || values["ip"] == "" || values["port"] == "" {
+			continue
+		}
+		lags[net.JoinHostPort(values["ip"], values["port"])] = time.Duration(lag) * time.Second
+	}
+
+	return lags
+}
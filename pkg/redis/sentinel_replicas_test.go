@@ -0,0 +1,136 @@
+package _redis
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestParseReplicationLags(t *testing.T) {
+	info := "# Replication\r\n" +
+		"role:master\r\n" +
+		"connected_slaves:2\r\n" +
+		"slave0:ip=10.0.0.2,port=6379,state=online,offset=1024,lag=0\r\n" +
+		"slave1:ip=10.0.0.3,port=6380,state=online,offset=900,lag=12\r\n" +
+		"master_repl_offset:1024\r\n"
+
+	lags := parseReplicationLags(info)
+
+	want := map[string]time.Duration{
+		"10.0.0.2:6379": 0,
+		"10.0.0.3:6380": 12 * time.Second,
+	}
+	if len(lags) != len(want) {
+		t.Fatalf("parseReplicationLags() = %v, want %v", lags, want)
+	}
+	for addr, lag := range want {
+		if got, ok := lags[addr]; !ok || got != lag {
+			t.Errorf("parseReplicationLags()[%s] = %v, want %v", addr, got, lag)
+		}
+	}
+}
+
+func TestIsReplicaUsable(t *testing.T) {
+	tests := []struct {
+		name    string
+		replica map[string]string
+		want    bool
+	}{
+		{name: "healthy", replica: map[string]string{"flags": "slave", "master-link-status": "ok"}, want: true},
+		{name: "subjectively down", replica: map[string]string{"flags": "s_down,slave", "master-link-status": "ok"}, want: false},
+		{name: "disconnected", replica: map[string]string{"flags": "slave,disconnected"}, want: false},
+		{name: "link down", replica: map[string]string{"flags": "slave", "master-link-status": "err"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isReplicaUsable(tt.replica); got != tt.want {
+				t.Errorf("isReplicaUsable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSentinelConfigReplicaRefreshInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		wantErr  bool
+		want     time.Duration
+	}{
+		{name: "unset uses default", interval: 0, want: defaultReplicaRefreshInterval},
+		{name: "custom", interval: 5 * time.Second, want: 5 * time.Second},
+		{name: "negative", interval: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A config written before the replica settings existed
+			config := SentinelConfig{
+				MasterName:             "mymaster",
+				SentinelAddresses:      []string{"localhost:26379"},
+				UseSlaveConnection:     true,
+				ReplicaRefreshInterval: tt.interval,
+			}
+
+			err := config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			router := newReplicaRouter(config, &redis.FailoverOptions{}, nil, nil)
+			if router.config.ReplicaRefreshInterval != tt.want {
+				t.Errorf("ReplicaRefreshInterval = %v, want %v", router.config.ReplicaRefreshInterval, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplicaRouterClose(t *testing.T) {
+	replica := miniredis.RunT(t)
+	host, port, _ := net.SplitHostPort(replica.Addr())
+
+	// The sentinel reports the replica to every discovery
+	sentinel := miniredis.RunT(t)
+	sentinel.Server().Register("SENTINEL", func(c *server.Peer, cmd string, args []string) {
+		c.WriteLen(1)
+		c.WriteStrings([]string{"ip", host, "port", port, "flags", "slave"})
+	})
+
+	master := redis.NewClient(&redis.Options{Addr: replica.Addr()})
+	defer master.Close()
+
+	config := DefaultSentinelConfig()
+	config.SentinelAddresses = []string{sentinel.Addr()}
+	router := newReplicaRouter(config, &redis.FailoverOptions{}, master, nil)
+
+	ctx := context.Background()
+	router.start(ctx)
+	if status := router.status(); len(status) != 1 || !status[0].Healthy {
+		t.Fatalf("status() = %v, want one healthy replica", status)
+	}
+
+	if err := router.close(); err != nil {
+		t.Errorf("close() error = %v", err)
+	}
+	if err := router.close(); err != nil {
+		t.Errorf("second close() error = %v", err)
+	}
+
+	// A refresh that finishes after close must not add replicas back
+	router.refresh(ctx)
+	if status := router.status(); len(status) != 0 {
+		t.Errorf("status() after close = %v, want none", status)
+	}
+	if client := router.pick(); client != nil {
+		t.Errorf("pick() after close = %v, want nil", client)
+	}
+}
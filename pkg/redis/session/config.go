@@ -0,0 +1,48 @@
+package _session_redis
+
+import (
+	"errors"
+	"time"
+)
+
+// Config holds the configuration for the session store
+type Config struct {
+	// KeyPrefix is prepended to every session and user index key
+	KeyPrefix string `json:"key_prefix" yaml:"key_prefix"`
+	// IdleTimeout expires a session that has not been touched for this long (sliding expiration)
+	IdleTimeout time.Duration `json:"idle_timeout" yaml:"idle_timeout"`
+	// AbsoluteTimeout expires a session this long after it was created, however active it is
+	AbsoluteTimeout time.Duration `json:"absolute_timeout" yaml:"absolute_timeout"`
+	// IDLength is the number of random bytes in a generated session ID
+	IDLength int `json:"id_length" yaml:"id_length"`
+	// MaxSessionsPerUser caps the active sessions of a user, the oldest are destroyed first (0 = unlimited)
+	MaxSessionsPerUser int `json:"max_sessions_per_user" yaml:"max_sessions_per_user"`
+}
+
+// DefaultConfig returns a session store configuration with sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		KeyPrefix:          "session:",
+		IdleTimeout:        30 * time.Minute,
+		AbsoluteTimeout:    7 * 24 * time.Hour,
+		IDLength:           32,
+		MaxSessionsPerUser: 0,
+	}
+}
+
+// Validate checks if the session store configuration is valid
+func (c *Config) Validate() error {
+	if c.IdleTimeout <= 0 {
+		return errors.New("idle timeout must be greater than 0")
+	}
+	if c.AbsoluteTimeout < c.IdleTimeout {
+		return errors.New("absolute timeout must be greater than or equal to idle timeout")
+	}
+	if c.IDLength < 16 {
+		return errors.New("id length must be at least 16 bytes")
+	}
+	if c.MaxSessionsPerUser < 0 {
+		return errors.New("max sessions per user must be greater than or equal to 0")
+	}
+	return nil
+}
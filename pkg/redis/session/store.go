@@ -0,0 +1,438 @@
+package _session_redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrSessionNotFound is returned when a session does not exist or has expired
+var ErrSessionNotFound = errors.New("session: not found")
+
+// maxTouchAttempts bounds how often Touch rereads a session that is saved concurrently
+const maxTouchAttempts = 3
+
+// touchScript slides the session TTL and replaces the session only if it has not changed
+// since it was read, so a concurrent Save is not overwritten with stale values.
+// KEYS[1] = session key, ARGV[1] = data read, ARGV[2] = new data, ARGV[3] = ttl in ms
+// Returns 1 when replaced, 0 when only the TTL was slid and -1 when the session is gone.
+var touchScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if not current then
+	return -1
+end
+if current ~= ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// Session is a user session stored in Redis
+type Session struct {
+	ID     string         `json:"id"`
+	UserID string         `json:"user_id"`
+	Values map[string]any `json:"values,omitempty"`
+	// CreatedAt is when the session was created, it is kept across Rotate
+	CreatedAt time.Time `json:"created_at"`
+	// LastSeenAt is the last time the session was created or touched
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// ExpiresAt is the absolute expiry, the session ends then even if it is active
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Store keeps sessions in Redis with sliding and absolute expiration. Every session of a
+// user is indexed so they can all be listed or destroyed at once.
+type Store struct {
+	client redis.UniversalClient
+	config Config
+}
+
+// NewStore creates a new session store
+func NewStore(client redis.UniversalClient, config Config) (*Store, error) {
+	if client == nil {
+		return nil, errors.New("redis client is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid session config: %w", err)
+	}
+
+	return &Store{
+		client: client,
+		config: config,
+	}, nil
+}
+
+// Create starts a new session for the user with a random ID
+func (s *Store) Create(ctx context.Context, userID string, values map[string]any) (*Session, error) {
+	id, err := GenerateID(s.config.IDLength)
+	if err != nil {
+		return nil, err
+	}
+	return s.CreateWithID(ctx, id, userID, values)
+}
+
+// CreateWithID starts a new session with the given ID, for example the jti of a refresh
+// token so the token can be revoked by destroying its session
+func (s *Store) CreateWithID(ctx context.Context, id, userID string, values map[string]any) (*Session, error) {
+	if id == "" {
+		return nil, errors.New("session id is required")
+	}
+
+	now := time.Now()
+	session := &Session{
+		ID:         id,
+		UserID:     userID,
+		Values:     values,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.config.AbsoluteTimeout),
+	}
+
+	if err := s.create(ctx, session, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.enforceLimit(ctx, userID); err != nil {
+		return session, err
+	}
+
+	return session, nil
+}
+
+// Get returns a session without extending it
+func (s *Store) Get(ctx context.Context, id string) (*Session, error) {
+	session, _, err := s.get(ctx, id)
+	return session, err
+}
+
+// Touch marks the session as active and slides its idle expiry, never past the absolute expiry.
+// The expiry is always slid, LastSeenAt is only recorded if the session is not saved
+// concurrently for maxTouchAttempts reads in a row.
+func (s *Store) Touch(ctx context.Context, id string) (*Session, error) {
+	for attempt := 1; ; attempt++ {
+		session, data, err := s.get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		touched, err := s.touch(ctx, session, data, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if touched || attempt == maxTouchAttempts {
+			return session, nil
+		}
+		// The session was saved since it was read, touch the new version
+	}
+}
+
+// Save stores changes to the session values without extending it
+func (s *Store) Save(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	ok, err := s.client.SetArgs(ctx, s.sessionKey(session.ID), data, redis.SetArgs{
+		Mode:    "XX",
+		KeepTTL: true,
+	}).Result()
+	if errors.Is(err, redis.Nil) || (err == nil && ok != "OK") {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return nil
+}
+
+// Rotate replaces the session ID, keeping its values and absolute expiry. Use it after a
+// privilege change or when a refresh token is exchanged. A random ID is generated when
+// newID is empty.
+func (s *Store) Rotate(ctx context.Context, id, newID string) (*Session, error) {
+	session, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if newID == "" {
+		if newID, err = GenerateID(s.config.IDLength); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	rotated := *session
+	rotated.ID = newID
+	rotated.LastSeenAt = now
+
+	if err := s.create(ctx, &rotated, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.Destroy(ctx, id); err != nil {
+		return &rotated, err
+	}
+
+	return &rotated, nil
+}
+
+// Destroy ends a session. Destroying a session that does not exist is not an error.
+func (s *Store) Destroy(ctx context.Context, id string) error {
+	session, err := s.Get(ctx, id)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.remove(ctx, session); err != nil {
+		return fmt.Errorf("failed to destroy session: %w", err)
+	}
+
+	return nil
+}
+
+// DestroyUser ends every session of the user except the given ones, for example to log
+// out everywhere but the current device. It returns the number of sessions destroyed.
+func (s *Store) DestroyUser(ctx context.Context, userID string, except ...string) (int, error) {
+	ids, err := s.client.ZRange(ctx, s.userKey(userID), 0, -1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+
+	keep := make(map[string]bool, len(except))
+	for _, id := range except {
+		keep[id] = true
+	}
+
+	var destroyed []string
+	for _, id := range ids {
+		if !keep[id] {
+			destroyed = append(destroyed, id)
+		}
+	}
+	if len(destroyed) == 0 {
+		return 0, nil
+	}
+
+	// Delete keys one by one since sessions live in different cluster slots
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range destroyed {
+			pipe.Del(ctx, s.sessionKey(id))
+		}
+		members := make([]any, len(destroyed))
+		for i, id := range destroyed {
+			members[i] = id
+		}
+		pipe.ZRem(ctx, s.userKey(userID), members...)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to destroy user sessions: %w", err)
+	}
+
+	return len(destroyed), nil
+}
+
+// ListUser returns the active sessions of the user, oldest first
+func (s *Store) ListUser(ctx context.Context, userID string) ([]*Session, error) {
+	userKey := s.userKey(userID)
+
+	// Drop sessions past their absolute expiry from the index
+	now := time.Now()
+	if err := s.client.ZRemRangeByScore(ctx, userKey, "-inf", strconv.FormatInt(now.UnixMilli(), 10)).Err(); err != nil {
+		return nil, fmt.Errorf("failed to prune user sessions: %w", err)
+	}
+
+	ids, err := s.client.ZRange(ctx, userKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	cmds := make([]*redis.StringCmd, len(ids))
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.Get(ctx, s.sessionKey(id))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to get user sessions: %w", err)
+	}
+
+	sessions := make([]*Session, 0, len(ids))
+	var stale []any
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if errors.Is(err, redis.Nil) {
+			// Expired through the idle timeout
+			stale = append(stale, ids[i])
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session: %w", err)
+		}
+
+		session := &Session{}
+		if err := json.Unmarshal(data, session); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if len(stale) > 0 {
+		s.client.ZRem(ctx, userKey, stale...)
+	}
+
+	return sessions, nil
+}
+
+// get fetches a session along with the data it was decoded from
+func (s *Store) get(ctx context.Context, id string) (*Session, []byte, error) {
+	if id == "" {
+		return nil, nil, ErrSessionNotFound
+	}
+
+	data, err := s.client.Get(ctx, s.sessionKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	session := &Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+
+	if !time.Now().Before(session.ExpiresAt) {
+		s.remove(ctx, session)
+		return nil, nil, ErrSessionNotFound
+	}
+
+	return session, data, nil
+}
+
+// touch slides the TTL and updates LastSeenAt if the stored session still matches data.
+// It returns false when the session has changed since data was read.
+func (s *Store) touch(ctx context.Context, session *Session, data []byte, now time.Time) (bool, error) {
+	session.LastSeenAt = now
+
+	touched, err := json.Marshal(session)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	// A session destroyed concurrently is not brought back
+	result, err := touchScript.Run(ctx, s.client, []string{s.sessionKey(session.ID)},
+		data, touched, s.ttl(session, now).Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to touch session: %w", err)
+	}
+	if result < 0 {
+		return false, ErrSessionNotFound
+	}
+
+	return result == 1, nil
+}
+
+// create stores a new session and adds it to the user index
+func (s *Store) create(ctx context.Context, session *Session, now time.Time) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.sessionKey(session.ID), data, s.ttl(session, now))
+		if session.UserID != "" {
+			userKey := s.userKey(session.UserID)
+			pipe.ZAdd(ctx, userKey, redis.Z{Score: float64(session.ExpiresAt.UnixMilli()), Member: session.ID})
+			// No session indexed now can outlive a full absolute timeout
+			pipe.PExpire(ctx, userKey, s.config.AbsoluteTimeout)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// remove deletes the session and its entry in the user index
+func (s *Store) remove(ctx context.Context, session *Session) error {
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.sessionKey(session.ID))
+		if session.UserID != "" {
+			pipe.ZRem(ctx, s.userKey(session.UserID), session.ID)
+		}
+		return nil
+	})
+	return err
+}
+
+// enforceLimit destroys the oldest sessions of the user beyond MaxSessionsPerUser
+func (s *Store) enforceLimit(ctx context.Context, userID string) error {
+	if s.config.MaxSessionsPerUser == 0 || userID == "" {
+		return nil
+	}
+
+	sessions, err := s.ListUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(sessions)-s.config.MaxSessionsPerUser; i++ {
+		if err := s.Destroy(ctx, sessions[i].ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ttl returns the idle timeout, capped by the time left until the absolute expiry
+func (s *Store) ttl(session *Session, now time.Time) time.Duration {
+	ttl := s.config.IdleTimeout
+	if remaining := session.ExpiresAt.Sub(now); remaining < ttl {
+		ttl = remaining
+	}
+	if ttl < time.Millisecond {
+		ttl = time.Millisecond
+	}
+	return ttl
+}
+
+func (s *Store) sessionKey(id string) string {
+	return s.config.KeyPrefix + id
+}
+
+func (s *Store) userKey(userID string) string {
+	return s.config.KeyPrefix + "user:" + userID
+}
+
+// GenerateID returns a URL safe session ID made of n bytes from crypto/rand
+func GenerateID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,232 @@
+package _session_redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestGenerateID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id, err := GenerateID(32)
+		if err != nil {
+			t.Fatalf("GenerateID() error = %v", err)
+		}
+		if len(id) != 43 {
+			t.Errorf("GenerateID() length = %v, want %v", len(id), 43)
+		}
+		if seen[id] {
+			t.Errorf("GenerateID() returned duplicate %v", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestStoreTTL(t *testing.T) {
+	s := &Store{config: Config{IdleTimeout: 30 * time.Minute}}
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		want      time.Duration
+	}{
+		{name: "idle timeout", expiresAt: now.Add(time.Hour), want: 30 * time.Minute},
+		{name: "capped by absolute expiry", expiresAt: now.Add(10 * time.Minute), want: 10 * time.Minute},
+		{name: "already expired", expiresAt: now.Add(-time.Minute), want: time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.ttl(&Session{ExpiresAt: tt.expiresAt}, now); got != tt.want {
+				t.Errorf("ttl() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreTouchKeepsSave(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	store, err := NewStore(client, DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		racing bool
+		want   any
+	}{
+		{
+			// A Save lands between the read and the write of a Touch
+			name: "Save between read and write",
+			want: "full",
+		},
+		{
+			name:   "Saves racing touches",
+			racing: true,
+			want:   float64(49),
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := store.Create(ctx, "user-1", map[string]any{"value": "empty"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			if tt.racing {
+				var wg sync.WaitGroup
+				stop := make(chan struct{})
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						select {
+						case <-stop:
+							return
+						default:
+						}
+						if _, err := store.Touch(ctx, session.ID); err != nil {
+							t.Errorf("Touch() error = %v", err)
+							return
+						}
+					}
+				}()
+
+				for i := 0; i < 50; i++ {
+					session.Values["value"] = float64(i)
+					if err := store.Save(ctx, session); err != nil {
+						t.Fatalf("Save() error = %v", err)
+					}
+				}
+				close(stop)
+				wg.Wait()
+			} else {
+				read, data, err := store.get(ctx, session.ID)
+				if err != nil {
+					t.Fatalf("get() error = %v", err)
+				}
+				session.Values["value"] = "full"
+				if err := store.Save(ctx, session); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+				if touched, err := store.touch(ctx, read, data, time.Now()); err != nil || touched {
+					t.Fatalf("touch() = %v, %v, want false, nil", touched, err)
+				}
+			}
+
+			touched, err := store.Touch(ctx, session.ID)
+			if err != nil {
+				t.Fatalf("Touch() error = %v", err)
+			}
+			if touched.Values["value"] != tt.want {
+				t.Errorf("Touch() value = %v, want %v", touched.Values["value"], tt.want)
+			}
+
+			stored, err := store.Get(ctx, session.ID)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if stored.Values["value"] != tt.want {
+				t.Errorf("Get() value = %v, want %v", stored.Values["value"], tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	store, err := NewStore(client, DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		expiresIn  time.Duration
+		touch      bool
+		touchAfter time.Duration
+		wait       time.Duration
+		wantErr    error
+	}{
+		{
+			name: "Active session",
+			wait: 20 * time.Minute,
+		},
+		{
+			name:    "Idle session",
+			wait:    31 * time.Minute,
+			wantErr: ErrSessionNotFound,
+		},
+		{
+			// Touching slides the expiry
+			name:       "Touched session",
+			touch:      true,
+			touchAfter: 20 * time.Minute,
+			wait:       20 * time.Minute,
+		},
+		{
+			name:       "Idle after touch",
+			touch:      true,
+			touchAfter: 20 * time.Minute,
+			wait:       31 * time.Minute,
+			wantErr:    ErrSessionNotFound,
+		},
+		{
+			name:      "Touch capped by absolute expiry",
+			expiresIn: time.Minute,
+			touch:     true,
+			wait:      time.Minute,
+			wantErr:   ErrSessionNotFound,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := store.Create(ctx, "user-1", nil)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if tt.expiresIn > 0 {
+				session.ExpiresAt = time.Now().Add(tt.expiresIn)
+				if err := store.Save(ctx, session); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+
+			if tt.touch {
+				server.FastForward(tt.touchAfter)
+				if _, err := store.Touch(ctx, session.ID); err != nil {
+					t.Fatalf("Touch() error = %v", err)
+				}
+			}
+			if ttl := server.TTL(store.sessionKey(session.ID)); tt.expiresIn > 0 && ttl > tt.expiresIn {
+				t.Errorf("TTL = %v, want at most %v", ttl, tt.expiresIn)
+			}
+
+			server.FastForward(tt.wait)
+			if _, err := store.Get(ctx, session.ID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := store.Touch(ctx, session.ID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Touch() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
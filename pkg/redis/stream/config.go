@@ -0,0 +1,108 @@
+package _stream_redis
+
+import (
+	"errors"
+	"time"
+)
+
+// Message field names used by the producer
+const (
+	FieldBody      = "body"
+	FieldMessageID = "message_id"
+	FieldTimestamp = "timestamp"
+
+	// Fields added to messages moved to the dead-letter stream
+	FieldSourceStream = "source_stream"
+	FieldSourceID     = "source_id"
+	FieldDeliveries   = "deliveries"
+	FieldError        = "error"
+)
+
+// PublishConfig holds configuration for publishing messages
+type PublishConfig struct {
+	// MaxLen caps the stream length, 0 means unbounded
+	MaxLen int64
+	// Approximate allows Redis to trim lazily (MAXLEN ~), which is much cheaper
+	Approximate bool
+}
+
+// DefaultPublishConfig returns default publish configuration
+func DefaultPublishConfig() PublishConfig {
+	return PublishConfig{
+		MaxLen:      0,
+		Approximate: true,
+	}
+}
+
+// ConsumeConfig holds configuration for consuming messages with a consumer group
+type ConsumeConfig struct {
+	// Stream is the stream to read from
+	Stream string
+	// Group is the consumer group name
+	Group string
+	// Consumer is the name of this consumer within the group, it should be stable across restarts
+	Consumer string
+	// StartID is where a newly created group starts reading, "$" for new messages or "0" for all
+	StartID string
+	// BatchSize is the maximum number of messages read per XREADGROUP call
+	BatchSize int64
+	// Block is how long XREADGROUP waits for new messages
+	Block time.Duration
+	// Workers is the number of goroutines processing messages
+	Workers int
+
+	// ClaimInterval is how often stale pending messages are claimed, 0 disables claiming
+	ClaimInterval time.Duration
+	// MinIdleTime is how long a message must be pending before another consumer may claim it
+	MinIdleTime time.Duration
+	// MaxDeliveries is how many times a message is delivered before it is dead-lettered, 0 means unlimited
+	MaxDeliveries int64
+	// DeadLetterStream receives messages that exceeded MaxDeliveries, empty drops them
+	DeadLetterStream string
+}
+
+// DefaultConsumeConfig returns default consume configuration
+func DefaultConsumeConfig() ConsumeConfig {
+	return ConsumeConfig{
+		Stream:           "",
+		Group:            "",
+		Consumer:         "",
+		StartID:          "$",
+		BatchSize:        10,
+		Block:            5 * time.Second,
+		Workers:          1,
+		ClaimInterval:    30 * time.Second,
+		MinIdleTime:      time.Minute,
+		MaxDeliveries:    5,
+		DeadLetterStream: "",
+	}
+}
+
+// Validate checks if the consume configuration is valid
+func (c *ConsumeConfig) Validate() error {
+	if c.Stream == "" {
+		return errors.New("stream is required")
+	}
+	if c.Group == "" {
+		return errors.New("group is required")
+	}
+	if c.Consumer == "" {
+		return errors.New("consumer is required")
+	}
+	if c.BatchSize <= 0 {
+		return errors.New("batch size must be greater than 0")
+	}
+	if c.Block <= 0 {
+		return errors.New("block must be greater than 0")
+	}
+	if c.Workers <= 0 {
+		return errors.New("workers must be greater than 0")
+	}
+	if c.ClaimInterval > 0 && c.MinIdleTime <= 0 {
+		return errors.New("min idle time must be greater than 0 when claiming is enabled")
+	}
+	if c.MaxDeliveries < 0 {
+		return errors.New("max deliveries must be greater than or equal to 0")
+	}
+	return nil
+}
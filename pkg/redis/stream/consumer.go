@@ -0,0 +1,378 @@
+package _stream_redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Message represents a consumed stream entry
+type Message struct {
+	// ID is the stream entry ID assigned by Redis
+	ID        string
+	MessageID string
+	Stream    string
+	Body      []byte
+	Values    map[string]any
+	// Deliveries is how many times the message has been delivered to the group
+	Deliveries int64
+	Timestamp  time.Time
+}
+
+// MessageProcessor is an interface for processing messages.
+// Returning nil acknowledges the message, an error leaves it pending so it is redelivered.
+type MessageProcessor interface {
+	Process(ctx context.Context, msg *Message) error
+}
+
+// Consumer reads a stream as part of a consumer group
+type Consumer struct {
+	client    redis.UniversalClient
+	config    ConsumeConfig
+	processor MessageProcessor
+
+	// Control
+	cancel context.CancelFunc
+	doneCh chan struct{}
+
+	// State
+	consuming bool
+	mu        sync.RWMutex
+}
+
+// NewConsumer creates a new Redis Streams consumer
+func NewConsumer(client redis.UniversalClient, config ConsumeConfig, processor MessageProcessor) *Consumer {
+	return &Consumer{
+		client:    client,
+		config:    config,
+		processor: processor,
+		consuming: false,
+	}
+}
+
+// Start creates the consumer group if needed and begins consuming messages
+func (c *Consumer) Start(ctx context.Context) error {
+	if err := c.config.Validate(); err != nil {
+		return fmt.Errorf("invalid consume config: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.consuming {
+		return fmt.Errorf("consumer already started")
+	}
+
+	if err := c.createGroup(ctx); err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.doneCh = make(chan struct{})
+	c.consuming = true
+
+	go c.run(ctx, runCtx)
+
+	return nil
+}
+
+// Stop stops reading new messages and waits for in-flight messages to finish processing.
+// It may wait up to Block for a pending XREADGROUP call to return.
+func (c *Consumer) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.consuming {
+		return nil
+	}
+
+	c.cancel()
+	<-c.doneCh
+
+	c.consuming = false
+
+	return nil
+}
+
+// IsConsuming checks if the consumer is active
+func (c *Consumer) IsConsuming() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.consuming
+}
+
+// createGroup creates the consumer group and the stream if they do not exist
+func (c *Consumer) createGroup(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(ctx, c.config.Stream, c.config.Group, c.config.StartID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	return nil
+}
+
+// run starts the reader, claimer and workers and waits for them to finish.
+// Processing uses ctx so in-flight messages are not cancelled by Stop.
+func (c *Consumer) run(ctx context.Context, runCtx context.Context) {
+	defer close(c.doneCh)
+
+	messages := make(chan *Message)
+
+	var workers sync.WaitGroup
+	for i := 0; i < c.config.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for msg := range messages {
+				c.handle(ctx, msg)
+			}
+		}()
+	}
+
+	var producers sync.WaitGroup
+	producers.Add(1)
+	go func() {
+		defer producers.Done()
+		c.read(runCtx, messages)
+	}()
+
+	if c.config.ClaimInterval > 0 {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			c.claim(runCtx, messages)
+		}()
+	}
+
+	producers.Wait()
+	close(messages)
+	workers.Wait()
+}
+
+// read is the main XREADGROUP loop. It first drains messages that were delivered to this
+// consumer before a restart, then reads new ones.
+func (c *Consumer) read(ctx context.Context, messages chan<- *Message) {
+	pending := true
+	lastID := "0"
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		id := ">"
+		block := c.config.Block
+		if pending {
+			id = lastID
+			block = -1 // history reads never block
+		}
+
+		streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.config.Group,
+			Consumer: c.config.Consumer,
+			Streams:  []string{c.config.Stream, id},
+			Count:    c.config.BatchSize,
+			Block:    block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				c.createGroup(ctx)
+			}
+			time.Sleep(1 * time.Second)
+			continue
+		}
+
+		var batch []redis.XMessage
+		for _, stream := range streams {
+			batch = append(batch, stream.Messages...)
+		}
+
+		if pending {
+			// Pending history is exhausted, switch to new messages
+			if len(batch) == 0 {
+				pending = false
+				continue
+			}
+			// Continue after the last pending entry
+			lastID = batch[len(batch)-1].ID
+			c.dispatch(ctx, messages, batch, c.deliveryCounts(ctx, batch))
+			continue
+		}
+
+		c.dispatch(ctx, messages, batch, nil)
+	}
+}
+
+// claim periodically takes over messages that have been pending on other consumers
+// for longer than MinIdleTime
+func (c *Consumer) claim(ctx context.Context, messages chan<- *Message) {
+	ticker := time.NewTicker(c.config.ClaimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := "0-0"
+		for {
+			claimed, next, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   c.config.Stream,
+				Group:    c.config.Group,
+				Consumer: c.config.Consumer,
+				MinIdle:  c.config.MinIdleTime,
+				Start:    start,
+				Count:    c.config.BatchSize,
+			}).Result()
+			if err != nil {
+				break
+			}
+
+			if len(claimed) > 0 {
+				c.dispatch(ctx, messages, claimed, c.deliveryCounts(ctx, claimed))
+			}
+
+			if next == "0-0" || next == "" || ctx.Err() != nil {
+				break
+			}
+			start = next
+		}
+	}
+}
+
+// dispatch converts stream entries and hands them to the workers
+func (c *Consumer) dispatch(ctx context.Context, messages chan<- *Message, batch []redis.XMessage, deliveries map[string]int64) {
+	for _, entry := range batch {
+		msg := c.newMessage(entry)
+		if count, ok := deliveries[entry.ID]; ok {
+			msg.Deliveries = count
+		}
+
+		select {
+		case <-ctx.Done():
+			// Undispatched messages stay pending and are read again on restart
+			return
+		case messages <- msg:
+		}
+	}
+}
+
+// handle processes a single message and acknowledges or dead-letters it
+func (c *Consumer) handle(ctx context.Context, msg *Message) {
+	maxDeliveries := c.config.MaxDeliveries
+
+	if maxDeliveries > 0 && msg.Deliveries > maxDeliveries {
+		c.deadLetter(ctx, msg, errors.New("max deliveries exceeded"))
+		return
+	}
+
+	err := c.processor.Process(ctx, msg)
+	if err == nil {
+		if ackErr := c.client.XAck(ctx, c.config.Stream, c.config.Group, msg.ID).Err(); ackErr != nil {
+			// Log error
+			fmt.Printf("Failed to ack message: %v\n", ackErr)
+		}
+		return
+	}
+
+	// Leave the message pending for a later claim unless it is out of attempts
+	if maxDeliveries > 0 && msg.Deliveries >= maxDeliveries {
+		c.deadLetter(ctx, msg, err)
+	}
+}
+
+// deadLetter copies the message to the dead-letter stream and acknowledges it
+func (c *Consumer) deadLetter(ctx context.Context, msg *Message, cause error) {
+	if c.config.DeadLetterStream != "" {
+		values := make(map[string]any, len(msg.Values)+4)
+		for k, v := range msg.Values {
+			values[k] = v
+		}
+		values[FieldSourceStream] = msg.Stream
+		values[FieldSourceID] = msg.ID
+		values[FieldDeliveries] = msg.Deliveries
+		values[FieldError] = cause.Error()
+
+		if err := c.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: c.config.DeadLetterStream,
+			Values: values,
+		}).Err(); err != nil {
+			// Keep the message pending so it is not lost
+			fmt.Printf("Failed to dead-letter message: %v\n", err)
+			return
+		}
+	}
+
+	if err := c.client.XAck(ctx, c.config.Stream, c.config.Group, msg.ID).Err(); err != nil {
+		// Log error
+		fmt.Printf("Failed to ack dead-lettered message: %v\n", err)
+	}
+}
+
+// deliveryCounts looks up how many times each entry has been delivered
+func (c *Consumer) deliveryCounts(ctx context.Context, batch []redis.XMessage) map[string]int64 {
+	counts := make(map[string]int64, len(batch))
+	cmds := make([]*redis.XPendingExtCmd, len(batch))
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, entry := range batch {
+			cmds[i] = pipe.XPendingExt(ctx, &redis.XPendingExtArgs{
+				Stream: c.config.Stream,
+				Group:  c.config.Group,
+				Start:  entry.ID,
+				End:    entry.ID,
+				Count:  1,
+			})
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return counts
+	}
+
+	for _, cmd := range cmds {
+		for _, pending := range cmd.Val() {
+			counts[pending.ID] = pending.RetryCount
+		}
+	}
+
+	return counts
+}
+
+// newMessage converts a stream entry into a Message
+func (c *Consumer) newMessage(entry redis.XMessage) *Message {
+	msg := &Message{
+		ID:         entry.ID,
+		Stream:     c.config.Stream,
+		Values:     entry.Values,
+		Deliveries: 1,
+	}
+
+	if body, ok := entry.Values[FieldBody].(string); ok {
+		msg.Body = []byte(body)
+	}
+	if messageID, ok := entry.Values[FieldMessageID].(string); ok {
+		msg.MessageID = messageID
+	}
+	if ts, ok := entry.Values[FieldTimestamp].(string); ok {
+		if nanos, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			msg.Timestamp = time.Unix(0, nanos)
+		}
+	}
+
+	return msg
+}
@@ -0,0 +1,168 @@
+package _stream_redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeProcessor records processed messages and fails them with err
+type fakeProcessor struct {
+	processed chan *Message
+	err       error
+}
+
+func (p *fakeProcessor) Process(ctx context.Context, msg *Message) error {
+	p.processed <- msg
+	return p.err
+}
+
+func waitForMessage(t *testing.T, processor *fakeProcessor) *Message {
+	t.Helper()
+	select {
+	case msg := <-processor.processed:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for a message")
+		return nil
+	}
+}
+
+// waitForPending polls until the group has want pending messages
+func waitForPending(t *testing.T, client redis.UniversalClient, want int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		pending, err := client.XPending(context.Background(), "orders", "billing").Result()
+		if err == nil && pending.Count == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("XPending() = %v, %v, want %d pending", pending, err, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConsumerRecoversUnacknowledged(t *testing.T) {
+	tests := []struct {
+		name           string
+		consumer       string
+		claimInterval  time.Duration
+		maxDeliveries  int64
+		err            error
+		wantDeliveries []int64
+	}{
+		{
+			// The consumer received the message before a restart and never acknowledged it
+			name:           "Pending replayed on start",
+			consumer:       "worker-1",
+			wantDeliveries: []int64{2},
+		},
+		{
+			// Another consumer died while processing the message
+			name:           "Idle message claimed",
+			consumer:       "worker-2",
+			claimInterval:  20 * time.Millisecond,
+			wantDeliveries: []int64{2},
+		},
+		{
+			// Delivered to worker-1, then claimed twice by worker-2 which fails both times
+			name:           "Dead-lettered after max deliveries",
+			consumer:       "worker-2",
+			claimInterval:  20 * time.Millisecond,
+			maxDeliveries:  3,
+			err:            errors.New("payment declined"),
+			wantDeliveries: []int64{2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			defer client.Close()
+			ctx := context.Background()
+
+			// Deliver a message to worker-1 without acknowledging it, as if it crashed
+			// while processing
+			if err := client.XGroupCreateMkStream(ctx, "orders", "billing", "0").Err(); err != nil {
+				t.Fatalf("XGroupCreateMkStream() error = %v", err)
+			}
+			result, err := NewProducer(client, DefaultPublishConfig()).Publish(ctx, "orders", []byte("order-1"))
+			if err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+			if err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    "billing",
+				Consumer: "worker-1",
+				Streams:  []string{"orders", ">"},
+				Count:    1,
+				Block:    -1,
+			}).Err(); err != nil {
+				t.Fatalf("XReadGroup() error = %v", err)
+			}
+
+			config := DefaultConsumeConfig()
+			config.Stream = "orders"
+			config.Group = "billing"
+			config.Consumer = tt.consumer
+			config.StartID = "0"
+			config.Block = 50 * time.Millisecond
+			config.ClaimInterval = tt.claimInterval
+			config.MinIdleTime = 50 * time.Millisecond
+			if tt.maxDeliveries > 0 {
+				config.MaxDeliveries = tt.maxDeliveries
+				config.DeadLetterStream = "orders:dead"
+			}
+
+			processor := &fakeProcessor{processed: make(chan *Message, 10), err: tt.err}
+			consumer := NewConsumer(client, config, processor)
+			if err := consumer.Start(ctx); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer consumer.Stop()
+
+			for _, want := range tt.wantDeliveries {
+				msg := waitForMessage(t, processor)
+				if msg.ID != result.ID {
+					t.Errorf("Message.ID = %v, want %v", msg.ID, result.ID)
+				}
+				if string(msg.Body) != "order-1" {
+					t.Errorf("Message.Body = %v, want %v", string(msg.Body), "order-1")
+				}
+				if msg.Deliveries != want {
+					t.Errorf("Message.Deliveries = %v, want %v", msg.Deliveries, want)
+				}
+			}
+			waitForPending(t, client, 0)
+
+			if tt.maxDeliveries == 0 {
+				return
+			}
+			dead, err := client.XRange(ctx, "orders:dead", "-", "+").Result()
+			if err != nil {
+				t.Fatalf("XRange() error = %v", err)
+			}
+			if len(dead) != 1 {
+				t.Fatalf("dead-letter stream length = %d, want 1", len(dead))
+			}
+
+			values := dead[0].Values
+			if values[FieldSourceID] != result.ID {
+				t.Errorf("%s = %v, want %v", FieldSourceID, values[FieldSourceID], result.ID)
+			}
+			if values[FieldError] != tt.err.Error() {
+				t.Errorf("%s = %v, want %v", FieldError, values[FieldError], tt.err)
+			}
+			if values[FieldBody] != "order-1" {
+				t.Errorf("%s = %v, want %v", FieldBody, values[FieldBody], "order-1")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package _stream_redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Producer handles publishing messages to Redis Streams
+type Producer struct {
+	client redis.UniversalClient
+	config PublishConfig
+}
+
+// NewProducer creates a new Redis Streams producer
+func NewProducer(client redis.UniversalClient, config PublishConfig) *Producer {
+	return &Producer{
+		client: client,
+		config: config,
+	}
+}
+
+// PublishResult contains information about the published message
+type PublishResult struct {
+	ID        string
+	MessageID string
+	Stream    string
+	Timestamp time.Time
+}
+
+// Publish publishes a message to the stream with auto-generated message ID
+func (p *Producer) Publish(ctx context.Context, stream string, body []byte) (*PublishResult, error) {
+	return p.PublishWithID(ctx, stream, body, uuid.New().String())
+}
+
+// PublishWithID publishes a message to the stream with a custom message ID
+func (p *Producer) PublishWithID(ctx context.Context, stream string, body []byte, messageID string) (*PublishResult, error) {
+	timestamp := time.Now()
+
+	id, err := p.client.XAdd(ctx, p.addArgs(stream, map[string]any{
+		FieldBody:      body,
+		FieldMessageID: messageID,
+		FieldTimestamp: timestamp.UnixNano(),
+	})).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return &PublishResult{
+		ID:        id,
+		MessageID: messageID,
+		Stream:    stream,
+		Timestamp: timestamp,
+	}, nil
+}
+
+// PublishBatch publishes multiple messages to the stream in a single pipeline
+func (p *Producer) PublishBatch(ctx context.Context, stream string, bodies [][]byte) ([]*PublishResult, error) {
+	if len(bodies) == 0 {
+		return nil, nil
+	}
+
+	timestamp := time.Now()
+	results := make([]*PublishResult, len(bodies))
+	cmds := make([]*redis.StringCmd, len(bodies))
+
+	_, err := p.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, body := range bodies {
+			messageID := uuid.New().String()
+			results[i] = &PublishResult{
+				MessageID: messageID,
+				Stream:    stream,
+				Timestamp: timestamp,
+			}
+			cmds[i] = pipe.XAdd(ctx, p.addArgs(stream, map[string]any{
+				FieldBody:      body,
+				FieldMessageID: messageID,
+				FieldTimestamp: timestamp.UnixNano(),
+			}))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish batch: %w", err)
+	}
+
+	for i, cmd := range cmds {
+		results[i].ID = cmd.Val()
+	}
+
+	return results, nil
+}
+
+// addArgs builds the XADD arguments for a message
+func (p *Producer) addArgs(stream string, values map[string]any) *redis.XAddArgs {
+	return &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: p.config.MaxLen,
+		Approx: p.config.Approximate && p.config.MaxLen > 0,
+		Values: values,
+	}
+}
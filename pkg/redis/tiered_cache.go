@@ -0,0 +1,252 @@
+package _redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// TieredCacheConfig holds the configuration for a two-tier cache
+type TieredCacheConfig struct {
+	CacheConfig
+
+	// LocalSize is the maximum number of entries kept in memory
+	LocalSize int
+	// LocalTTL is how long an entry stays in memory, it should be shorter than the Redis TTL
+	// to bound staleness if an invalidation message is lost
+	LocalTTL time.Duration
+	// InvalidationChannel is the pub/sub channel used to broadcast invalidations
+	InvalidationChannel string
+}
+
+// DefaultTieredCacheConfig returns a two-tier cache configuration with sensible defaults
+func DefaultTieredCacheConfig() TieredCacheConfig {
+	return TieredCacheConfig{
+		CacheConfig:         DefaultCacheConfig(),
+		LocalSize:           10000,
+		LocalTTL:            time.Minute,
+		InvalidationChannel: "cache:invalidate",
+	}
+}
+
+// Validate checks if the two-tier cache configuration is valid
+func (c *TieredCacheConfig) Validate() error {
+	if err := c.CacheConfig.Validate(); err != nil {
+		return err
+	}
+	if c.LocalSize <= 0 {
+		return errors.New("local size must be greater than 0")
+	}
+	if c.LocalTTL <= 0 {
+		return errors.New("local ttl must be greater than 0")
+	}
+	if c.InvalidationChannel == "" {
+		return errors.New("invalidation channel is required")
+	}
+	return nil
+}
+
+// invalidation is the message broadcast when keys change
+type invalidation struct {
+	Source string   `json:"source"`
+	Keys   []string `json:"keys"`
+}
+
+// TieredCache keeps hot keys in an in-process LRU in front of a Redis Cache.
+// Writes through any instance broadcast an invalidation over Redis pub/sub so
+// every instance drops its local copy.
+type TieredCache[T any] struct {
+	remote *Cache[T]
+	local  *localCache[T]
+	client redis.UniversalClient
+	config TieredCacheConfig
+	id     string
+
+	pubsub *redis.PubSub
+	doneCh chan struct{}
+	mu     sync.Mutex
+}
+
+// NewTieredCache creates a two-tier cache backed by the given client
+func NewTieredCache[T any](client redis.UniversalClient, config TieredCacheConfig) (*TieredCache[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tiered cache config: %w", err)
+	}
+
+	remote, err := NewCache[T](client, config.CacheConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TieredCache[T]{
+		remote: remote,
+		local:  newLocalCache[T](config.LocalSize, config.LocalTTL),
+		client: client,
+		config: config,
+		id:     uuid.New().String(),
+	}, nil
+}
+
+// Start subscribes to the invalidation channel. It must be called before the cache is used.
+func (c *TieredCache[T]) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pubsub != nil {
+		return fmt.Errorf("tiered cache already started")
+	}
+
+	pubsub := c.client.Subscribe(ctx, c.config.InvalidationChannel)
+
+	// Wait for the subscription to be confirmed so no invalidation is missed after Start returns
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to invalidation channel: %w", err)
+	}
+
+	c.pubsub = pubsub
+	c.doneCh = make(chan struct{})
+	go c.listen(pubsub, c.doneCh)
+
+	return nil
+}
+
+// Close unsubscribes from the invalidation channel
+func (c *TieredCache[T]) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pubsub == nil {
+		return nil
+	}
+
+	err := c.pubsub.Close()
+	<-c.doneCh
+
+	c.pubsub = nil
+	c.local.purge()
+
+	return err
+}
+
+// Get returns the value from memory or Redis
+func (c *TieredCache[T]) Get(ctx context.Context, key string) (T, error) {
+	if value, ok := c.local.get(key); ok {
+		return value, nil
+	}
+
+	generation := c.local.currentGeneration()
+	value, err := c.remote.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	// Skip the local copy if an invalidation arrived during the Redis round-trip
+	c.local.setIfCurrent(key, value, generation)
+	return value, nil
+}
+
+// GetOrLoad returns the value from memory or Redis, calling loader on a miss in both tiers
+func (c *TieredCache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	if value, ok := c.local.get(key); ok {
+		return value, nil
+	}
+
+	generation := c.local.currentGeneration()
+	value, err := c.remote.GetOrLoad(ctx, key, ttl, loader)
+	if err != nil {
+		return value, err
+	}
+
+	c.local.setIfCurrent(key, value, generation)
+	return value, nil
+}
+
+// Set writes the value to Redis and invalidates the key on every instance
+func (c *TieredCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+
+	if err := c.publish(ctx, key); err != nil {
+		return err
+	}
+
+	c.local.set(key, value)
+	return nil
+}
+
+// Delete removes the keys from Redis and invalidates them on every instance
+func (c *TieredCache[T]) Delete(ctx context.Context, keys ...string) error {
+	c.local.delete(keys...)
+
+	if err := c.remote.Delete(ctx, keys...); err != nil {
+		return err
+	}
+
+	return c.publish(ctx, keys...)
+}
+
+// Invalidate drops the keys from the local tier of every instance without touching Redis
+func (c *TieredCache[T]) Invalidate(ctx context.Context, keys ...string) error {
+	c.local.delete(keys...)
+	return c.publish(ctx, keys...)
+}
+
+// publish broadcasts an invalidation for the given keys
+func (c *TieredCache[T]) publish(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(invalidation{Source: c.id, Keys: keys})
+	if err != nil {
+		return fmt.Errorf("failed to marshal invalidation: %w", err)
+	}
+
+	if err := c.client.Publish(ctx, c.config.InvalidationChannel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish invalidation: %w", err)
+	}
+
+	return nil
+}
+
+// listen applies invalidations until the subscription is closed
+func (c *TieredCache[T]) listen(pubsub *redis.PubSub, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ctx := context.Background()
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if errors.Is(err, redis.ErrClosed) {
+				return
+			}
+			// Invalidations may have been missed while disconnected
+			c.local.purge()
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			// Resubscribed after a reconnect, drop everything that may be stale
+			c.local.purge()
+		case *redis.Message:
+			var inv invalidation
+			if err := json.Unmarshal([]byte(m.Payload), &inv); err != nil {
+				continue
+			}
+			if inv.Source == c.id {
+				continue
+			}
+			c.local.delete(inv.Keys...)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package _redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// eventually polls cond until it holds or the timeout expires
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTieredCacheInvalidation(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	caches := make([]*TieredCache[string], 2)
+	for i := range caches {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer client.Close()
+
+		cache, err := NewTieredCache[string](client, DefaultTieredCacheConfig())
+		if err != nil {
+			t.Fatalf("NewTieredCache() error = %v", err)
+		}
+		if err := cache.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		defer cache.Close()
+		caches[i] = cache
+	}
+	writer, reader := caches[0], caches[1]
+
+	tests := []struct {
+		name    string
+		update  string
+		want    string
+		wantErr error
+	}{
+		{
+			// A write on another instance drops the local copy
+			name:   "Set on another instance",
+			update: "bob",
+			want:   "bob",
+		},
+		{
+			// A delete on another instance drops the local copy
+			name:    "Delete on another instance",
+			wantErr: ErrCacheMiss,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := "user:" + tt.name
+
+			if err := writer.Set(ctx, key, "alice", 0); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			// The value is kept locally once the invalidation of the write has been received
+			eventually(t, "reader to cache the value", func() bool {
+				value, err := reader.Get(ctx, key)
+				if err != nil || value != "alice" {
+					t.Fatalf("Get() = %v, %v, want alice, nil", value, err)
+				}
+				_, ok := reader.local.get(key)
+				return ok
+			})
+
+			if tt.update != "" {
+				if err := writer.Set(ctx, key, tt.update, 0); err != nil {
+					t.Fatalf("Set() error = %v", err)
+				}
+			} else {
+				if err := writer.Delete(ctx, key); err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+			}
+			eventually(t, "reader to see the change", func() bool {
+				value, err := reader.Get(ctx, key)
+				return errors.Is(err, tt.wantErr) && value == tt.want
+			})
+		})
+	}
+
+	// The invalidation arrives while the reader is loading the value
+	t.Run("Invalidated during read", func(t *testing.T) {
+		value, err := reader.GetOrLoad(ctx, "user:1", 0, func(ctx context.Context) (string, error) {
+			generation := reader.local.currentGeneration()
+			if err := writer.Invalidate(ctx, "user:1"); err != nil {
+				return "", err
+			}
+			eventually(t, "reader to receive the invalidation", func() bool {
+				return reader.local.currentGeneration() != generation
+			})
+			return "stale", nil
+		})
+		if err != nil || value != "stale" {
+			t.Fatalf("GetOrLoad() = %v, %v, want stale, nil", value, err)
+		}
+
+		if _, ok := reader.local.get("user:1"); ok {
+			t.Errorf("local get(user:1) found, want the value read before the invalidation to be skipped")
+		}
+	})
+}
+
+func TestLocalCacheSetIfCurrent(t *testing.T) {
+	tests := []struct {
+		name    string
+		deleted bool
+		want    bool
+	}{
+		{name: "Current generation", want: true},
+		{name: "Deleted since the generation was read", deleted: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newLocalCache[int](2, time.Minute)
+
+			generation := c.currentGeneration()
+			if tt.deleted {
+				c.delete("a")
+			}
+
+			if got := c.setIfCurrent("a", 1, generation); got != tt.want {
+				t.Errorf("setIfCurrent() = %v, want %v", got, tt.want)
+			}
+			if _, ok := c.get("a"); ok != tt.want {
+				t.Errorf("get(a) found = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
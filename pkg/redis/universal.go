@@ -0,0 +1,92 @@
+package _redis
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewUniversalClient creates the connection for the configured mode. Call Connect on the
+// result before use.
+func NewUniversalClient(config UniversalConfig) (UniversalClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid universal config: %w", err)
+	}
+
+	switch config.Mode {
+	case ModeCluster:
+		return NewClusterConnection(config.Cluster), nil
+	case ModeSentinel:
+		return NewSentinelConnection(config.Sentinel), nil
+	default:
+		return NewConnection(config.Single), nil
+	}
+}
+
+// Mode returns ModeSingle
+func (c *Connection) Mode() Mode {
+	return ModeSingle
+}
+
+// GetUniversalClient returns the underlying Redis client
+func (c *Connection) GetUniversalClient() redis.UniversalClient {
+	if c.client == nil {
+		return nil
+	}
+	return c.client
+}
+
+// GetReadClient returns the underlying Redis client, as a single node has no replicas
+func (c *Connection) GetReadClient() redis.UniversalClient {
+	return c.GetUniversalClient()
+}
+
+// Mode returns ModeCluster
+func (c *ClusterConnection) Mode() Mode {
+	return ModeCluster
+}
+
+// GetUniversalClient returns the cluster client for write operations
+func (c *ClusterConnection) GetUniversalClient() redis.UniversalClient {
+	if c.masterClient == nil {
+		return nil
+	}
+	return c.masterClient
+}
+
+// GetReadClient returns the read-only cluster client, or the master client if no slave client is connected
+func (c *ClusterConnection) GetReadClient() redis.UniversalClient {
+	if c.slaveClient == nil {
+		return c.GetUniversalClient()
+	}
+	return c.slaveClient
+}
+
+// Mode returns ModeSentinel
+func (c *SentinelConnection) Mode() Mode {
+	return ModeSentinel
+}
+
+// GetUniversalClient returns the failover client for the current master
+func (c *SentinelConnection) GetUniversalClient() redis.UniversalClient {
+	if c.masterClient == nil {
+		return nil
+	}
+	return c.masterClient
+}
+
+// GetReadClient returns a replica client chosen by the routing policy, or the master
+// client if no replica is available
+func (c *SentinelConnection) GetReadClient() redis.UniversalClient {
+	if client := c.GetSlaveClient(); client != nil {
+		return client
+	}
+	return c.GetUniversalClient()
+}
+
+// Ensure all connection types implement the UniversalClient interface
+var (
+	_ UniversalClient = (*Connection)(nil)
+	_ UniversalClient = (*ClusterConnection)(nil)
+	_ UniversalClient = (*SentinelConnection)(nil)
+)
@@ -0,0 +1,38 @@
+package _redis
+
+import "testing"
+
+func TestNewUniversalClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    Mode
+		want    Mode
+		wantErr bool
+	}{
+		{name: "single", mode: ModeSingle, want: ModeSingle},
+		{name: "cluster", mode: ModeCluster, want: ModeCluster},
+		{name: "sentinel", mode: ModeSentinel, want: ModeSentinel},
+		{name: "unknown", mode: "standalone", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultUniversalConfig()
+			config.Mode = tt.mode
+
+			client, err := NewUniversalClient(config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewUniversalClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := client.Mode(); got != tt.want {
+				t.Errorf("NewUniversalClient() mode = %v, want %v", got, tt.want)
+			}
+			if client.GetUniversalClient() != nil || client.GetReadClient() != nil {
+				t.Errorf("NewUniversalClient() returned clients before Connect")
+			}
+		})
+	}
+}